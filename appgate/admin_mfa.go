@@ -0,0 +1,105 @@
+package appgate
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
+)
+
+const (
+	// totpPeriod and totpDigits are the TOTP parameters used by the controller for admin MFA.
+	totpPeriod = 30 * time.Second
+	totpDigits = 6
+
+	// otpCommandTimeout is the maximum time we wait for the otp_command to exit.
+	otpCommandTimeout = 1 * time.Minute
+)
+
+// totp computes the time-based one-time password (RFC 6238) from a base32 encoded seed.
+func totp(seed string, t time.Time) (string, error) {
+	seed = strings.ToUpper(strings.Join(strings.Fields(seed), ""))
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimRight(seed, "="))
+	if err != nil {
+		return "", fmt.Errorf("invalid otp_seed, expected base32 %w", err)
+	}
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(t.Unix()/int64(totpPeriod.Seconds())))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+	// dynamic truncation, RFC 4226 section 5.3
+	offset := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", totpDigits, code%mod), nil
+}
+
+// runOTPCommand runs the otp_command and returns the one-time password from stdout.
+func runOTPCommand(command string) (string, error) {
+	program, stdout, err := runCommand("otp_command", command, otpCommandTimeout)
+	if err != nil {
+		return "", err
+	}
+	otp := strings.TrimSpace(string(stdout))
+	if len(otp) == 0 {
+		return "", fmt.Errorf("otp_command %s did not output a one-time password", program)
+	}
+	return otp, nil
+}
+
+// oneTimePassword returns the one-time password for admin MFA from otp_seed or otp_command.
+func (c *Config) oneTimePassword() (string, error) {
+	if len(c.OTPSeed) > 0 {
+		return totp(c.OTPSeed, time.Now())
+	}
+	if len(c.OTPCommand) > 0 {
+		return runOTPCommand(c.OTPCommand)
+	}
+	return "", fmt.Errorf("admin MFA is required for %s, set otp_seed or otp_command, or add the user to the admin MFA exempted_users", c.Username)
+}
+
+// completeAdminMFA finishes the admin MFA challenge with the partial token from POST /login,
+// and returns the authorization with the final token.
+func (c *Client) completeAdminMFA(ctx context.Context, partialToken string) (*openapi.LoginAuthorizationResponse, error) {
+	log.Printf("[DEBUG] Admin MFA required, completing one-time password challenge")
+	otp, err := c.Config.oneTimePassword()
+	if err != nil {
+		return nil, err
+	}
+	// the partial token is only valid for the MFA challenge, and can not be renewed.
+	ctx = context.WithValue(ctx, noReauthCtx, true)
+	api := c.API.LoginApi
+	partialCtx := context.WithValue(ctx, openapi.ContextAccessToken, partialToken)
+	initialize, _, err := api.AuthenticationOtpInitializePost(partialCtx).
+		AuthenticationOtpInitializePostRequest(openapi.AuthenticationOtpInitializePostRequest{}).
+		Execute()
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize admin MFA %w", prettyPrintAPIError(err))
+	}
+	otpRequest := openapi.AuthenticationOtpPostRequest{Otp: otp}
+	if state, ok := initialize.GetStateOk(); ok {
+		otpRequest.SetState(*state)
+	}
+	authentication, _, err := api.AuthenticationOtpPost(partialCtx).AuthenticationOtpPostRequest(otpRequest).Execute()
+	if err != nil {
+		return nil, fmt.Errorf("admin MFA one-time password rejected %w", prettyPrintAPIError(err))
+	}
+	authCtx := context.WithValue(ctx, openapi.ContextAccessToken, authentication.GetToken())
+	authorization, _, err := api.AuthorizationGet(authCtx).Execute()
+	if err != nil {
+		return nil, fmt.Errorf("could not authorize after admin MFA %w", prettyPrintAPIError(err))
+	}
+	log.Printf("[DEBUG] Admin MFA OK")
+	return authorization, nil
+}
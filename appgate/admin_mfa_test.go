@@ -0,0 +1,132 @@
+package appgate
+
+import (
+	"encoding/base32"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestTOTP(t *testing.T) {
+	// test vectors from RFC 6238 appendix B, SHA1 truncated to 6 digits.
+	seed := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			got, err := totp(seed, time.Unix(tt.unix, 0))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("totp() got %s, want %s", got, tt.want)
+			}
+		})
+	}
+	// seeds are often displayed in lower case groups without padding.
+	if got, err := totp("gezd gnbv gy3t qojq gezd gnbv gy3t qojq", time.Unix(59, 0)); err != nil || got != "287082" {
+		t.Errorf("totp() with formatted seed got %s %v, want 287082", got, err)
+	}
+	if _, err := totp("not base32!", time.Now()); err == nil {
+		t.Error("totp() expected error for invalid seed")
+	}
+}
+
+func TestClientAdminMFA(t *testing.T) {
+	seed := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	tests := []struct {
+		name       string
+		seed       string
+		output     string
+		wantOTP    string
+		wantErr    bool
+		rejectOTPs bool
+	}{
+		{name: "otp seed", seed: seed},
+		{name: "otp command", output: "123456\n", wantOTP: "123456"},
+		{name: "no otp configured", wantErr: true},
+		{name: "rejected otp", output: "000000", wantErr: true, rejectOTPs: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, mux, _, port, teardown := setup()
+			defer teardown()
+			mux.HandleFunc("/admin/login", func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, http.MethodPost)
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"user": {"name": "admin", "needTwoFactorAuth": true}, "token": "partial"}`)
+			})
+			mux.HandleFunc("/admin/authentication/otp/initialize", func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, http.MethodPost)
+				if got := r.Header.Get("Authorization"); got != "Bearer partial" {
+					t.Errorf("initialize got Authorization %q", got)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"type": "AlreadySeeded", "state": "challenge-state"}`)
+			})
+			mux.HandleFunc("/admin/authentication/otp", func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, http.MethodPost)
+				var body struct {
+					OTP   string `json:"otp"`
+					State string `json:"state"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Fatal(err)
+				}
+				if body.State != "challenge-state" {
+					t.Errorf("otp got state %q", body.State)
+				}
+				if len(tt.wantOTP) > 0 && body.OTP != tt.wantOTP {
+					t.Errorf("otp got %q, want %q", body.OTP, tt.wantOTP)
+				}
+				if tt.rejectOTPs {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusUnauthorized)
+					fmt.Fprint(w, `{"id": "unauthorized", "message": "Invalid one-time password"}`)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"token": "authenticated"}`)
+			})
+			mux.HandleFunc("/admin/authorization", func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, http.MethodGet)
+				if got := r.Header.Get("Authorization"); got != "Bearer authenticated" {
+					t.Errorf("authorization got Authorization %q", got)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"user": {"name": "admin"}, "token": "authorized"}`)
+			})
+			c := &Config{
+				URL:          fmt.Sprintf("http://localhost:%d", port),
+				Username:     "admin",
+				Password:     "admin",
+				Version:      22,
+				LoginTimeout: 1,
+				OTPSeed:      tt.seed,
+			}
+			if len(tt.output) > 0 {
+				c.OTPCommand = testCredentialProcess(t, tt.output)
+			}
+			appgateClient, err := c.Client()
+			if err != nil {
+				t.Fatalf("got err %s expected nil", err)
+			}
+			token, err := appgateClient.GetToken()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && token != "authorized" {
+				t.Errorf("GetToken() got %q, want authorized", token)
+			}
+		})
+	}
+}
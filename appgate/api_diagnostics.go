@@ -0,0 +1,172 @@
+package appgate
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
+	"github.com/hashicorp/go-cty/cty"
+	fwdiag "github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	resourceschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// apiErrorDiagnostics returns one diagnostic for each field in a validation error from the controller,
+// with the AttributePath of the matching argument in s, so terraform shows the error on the argument in the configuration.
+// Other errors are returned as a single diagnostic, same as prettyPrintAPIError.
+func apiErrorDiagnostics(summary string, err error, s map[string]*schema.Schema) diag.Diagnostics {
+	apiErr, ok := err.(*openapi.GenericOpenAPIError)
+	if !ok {
+		return diag.FromErr(fmt.Errorf("%s %w", summary, prettyPrintAPIError(err)))
+	}
+	model, ok := apiErr.Model().(openapi.ValidationError)
+	if !ok || len(model.GetErrors()) == 0 {
+		return diag.FromErr(fmt.Errorf("%s %w", summary, prettyPrintAPIError(err)))
+	}
+	var diags diag.Diagnostics
+	for _, ve := range model.GetErrors() {
+		detail := fmt.Sprintf("%s %s", ve.GetField(), ve.GetMessage())
+		if msg, ok := model.GetMessageOk(); ok {
+			detail = fmt.Sprintf("%s: %s", *msg, detail)
+		}
+		diags = append(diags, diag.Diagnostic{
+			Severity:      diag.Error,
+			Summary:       summary,
+			Detail:        detail,
+			AttributePath: fieldPath(ve.GetField(), s),
+		})
+	}
+	return diags
+}
+
+// frameworkAPIErrorDiagnostics is apiErrorDiagnostics for a terraform-plugin-framework resource with the schema s.
+func frameworkAPIErrorDiagnostics(summary string, err error, s resourceschema.Schema) fwdiag.Diagnostics {
+	var diags fwdiag.Diagnostics
+	apiErr, ok := err.(*openapi.GenericOpenAPIError)
+	if !ok {
+		diags.AddError(summary, prettyPrintAPIError(err).Error())
+		return diags
+	}
+	model, ok := apiErr.Model().(openapi.ValidationError)
+	if !ok || len(model.GetErrors()) == 0 {
+		diags.AddError(summary, prettyPrintAPIError(err).Error())
+		return diags
+	}
+	for _, ve := range model.GetErrors() {
+		detail := fmt.Sprintf("%s %s", ve.GetField(), ve.GetMessage())
+		if msg, ok := model.GetMessageOk(); ok {
+			detail = fmt.Sprintf("%s: %s", *msg, detail)
+		}
+		if p := frameworkFieldPath(ve.GetField(), s); len(p.Steps()) > 0 {
+			diags.AddAttributeError(p, summary, detail)
+			continue
+		}
+		diags.AddError(summary, detail)
+	}
+	return diags
+}
+
+// fieldSegment matches a single segment of a field path from the controller, for example actions[2].
+var fieldSegment = regexp.MustCompile(`^([^\[\]]+)((?:\[\d+\])*)$`)
+
+// fieldPath converts a json field path from the controller, such as nameResolution.awsResolvers[0].vpcs,
+// to the path of the argument in the schema, name_resolution[0].aws_resolvers[0].vpcs.
+// The path stops at the last field found in the schema, and at sets since their elements are not addressed by index.
+// nil is returned if the field is not found at all, and the diagnostic is shown on the resource.
+func fieldPath(field string, s map[string]*schema.Schema) cty.Path {
+	var path cty.Path
+	segments := strings.Split(field, ".")
+	for i, segment := range segments {
+		m := fieldSegment.FindStringSubmatch(segment)
+		if m == nil || s == nil {
+			break
+		}
+		key := camelToSnake(m[1])
+		attr, ok := s[key]
+		if !ok {
+			break
+		}
+		path = path.GetAttr(key)
+		s = nil
+		if attr.Type != schema.TypeList {
+			continue
+		}
+		elem, isBlock := attr.Elem.(*schema.Resource)
+		if index := strings.TrimSuffix(strings.TrimPrefix(m[2], "["), "]"); len(index) > 0 {
+			// only the first index is used, we don't have lists of lists in the schema.
+			n, _ := strconv.Atoi(strings.SplitN(index, "]", 2)[0])
+			path = path.IndexInt(n)
+		} else if isBlock && i < len(segments)-1 {
+			// nested objects in the API are blocks with MaxItems 1 in the schema.
+			path = path.IndexInt(0)
+		} else {
+			continue
+		}
+		if isBlock {
+			s = elem.Schema
+		}
+	}
+	if len(path) == 0 {
+		return nil
+	}
+	return path
+}
+
+// camelToSnake converts a json field name to the schema argument name, for example awsResolvers to aws_resolvers.
+func camelToSnake(in string) string {
+	runes := []rune(in)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteRune('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// frameworkFieldPath is fieldPath for a terraform-plugin-framework resource with the schema s,
+// nested objects are list blocks with at most one element.
+func frameworkFieldPath(field string, s resourceschema.Schema) path.Path {
+	p := path.Empty()
+	attributes, blocks := s.Attributes, s.Blocks
+	segments := strings.Split(field, ".")
+	for i, segment := range segments {
+		m := fieldSegment.FindStringSubmatch(segment)
+		if m == nil {
+			break
+		}
+		key := camelToSnake(m[1])
+		if _, ok := attributes[key]; ok {
+			return p.AtName(key)
+		}
+		block, ok := blocks[key]
+		if !ok {
+			break
+		}
+		p = p.AtName(key)
+		list, ok := block.(resourceschema.ListNestedBlock)
+		if !ok {
+			break
+		}
+		if index := strings.TrimSuffix(strings.TrimPrefix(m[2], "["), "]"); len(index) > 0 {
+			n, _ := strconv.Atoi(strings.SplitN(index, "]", 2)[0])
+			p = p.AtListIndex(n)
+		} else if i < len(segments)-1 {
+			p = p.AtListIndex(0)
+		} else {
+			break
+		}
+		attributes, blocks = list.NestedObject.Attributes, list.NestedObject.Blocks
+	}
+	return p
+}
@@ -0,0 +1,158 @@
+package appgate
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestFieldPath(t *testing.T) {
+	site := resourceAppgateSite().Schema
+	entitlement := resourceAppgateEntitlement().Schema
+	tests := []struct {
+		field string
+		s     map[string]*schema.Schema
+		want  cty.Path
+	}{
+		{
+			field: "name",
+			s:     site,
+			want:  cty.GetAttrPath("name"),
+		},
+		{
+			field: "networkSubnets[1]",
+			s:     site,
+			want:  cty.GetAttrPath("network_subnets"),
+		},
+		{
+			field: "nameResolution.useHostsFile",
+			s:     site,
+			want:  cty.GetAttrPath("name_resolution").IndexInt(0).GetAttr("use_hosts_file"),
+		},
+		{
+			// aws_resolvers is a set, its elements can't be addressed by index.
+			field: "nameResolution.awsResolvers[0].vpcs",
+			s:     site,
+			want:  cty.GetAttrPath("name_resolution").IndexInt(0).GetAttr("aws_resolvers"),
+		},
+		{
+			field: "actions[2].ports",
+			s:     entitlement,
+			want:  cty.GetAttrPath("actions"),
+		},
+		{
+			field: "appShortcuts[1].url",
+			s:     entitlement,
+			want:  cty.GetAttrPath("app_shortcuts").IndexInt(1).GetAttr("url"),
+		},
+		{
+			field: "unknownField.name",
+			s:     site,
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			if got := fieldPath(tt.field, tt.s); !got.Equals(tt.want) {
+				t.Fatalf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFrameworkFieldPath(t *testing.T) {
+	policy := newPolicyResource().(*policyResource).schema
+	tests := []struct {
+		field string
+		want  path.Path
+	}{
+		{field: "name", want: path.Root("name")},
+		{field: "overrideSiteClaim", want: path.Root("override_site_claim")},
+		{field: "proxyAutoConfig.url", want: path.Root("proxy_auto_config").AtListIndex(0).AtName("url")},
+		// dns_settings is a set, its elements can't be addressed by index.
+		{field: "dnsSettings[0].servers", want: path.Root("dns_settings")},
+		{field: "unknownField.name", want: path.Empty()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			if got := frameworkFieldPath(tt.field, policy); !got.Equal(tt.want) {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCamelToSnake(t *testing.T) {
+	tests := map[string]string{
+		"name":           "name",
+		"nameResolution": "name_resolution",
+		"awsResolvers":   "aws_resolvers",
+		"useIAMRole":     "use_iam_role",
+		"ipv4":           "ipv4",
+		"clientSettings": "client_settings",
+	}
+	for in, want := range tests {
+		if got := camelToSnake(in); got != want {
+			t.Errorf("camelToSnake(%q) got %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestAPIErrorDiagnostics(t *testing.T) {
+	client, _, mux, _, _, teardown := setup()
+	defer teardown()
+	mux.HandleFunc("/conditions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprint(w, `{
+			"id": "unprocessable entity",
+			"message": "Condition is invalid.",
+			"errors": [
+				{"field": "expression", "message": "may not be empty"},
+				{"field": "repeatSchedules[0]", "message": "is not a valid schedule"},
+				{"field": "somethingElse", "message": "is wrong"}
+			]
+		}`)
+	})
+	condition := openapi.NewCondition("condition", "")
+	_, _, err := client.ConditionsApi.ConditionsPost(context.Background()).Condition(*condition).Execute()
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+
+	diags := apiErrorDiagnostics("Could not create condition", err, resourceAppgateCondition().Schema)
+	if len(diags) != 3 {
+		t.Fatalf("got %d diagnostics, expected 3", len(diags))
+	}
+	want := []struct {
+		detail string
+		path   cty.Path
+	}{
+		{"Condition is invalid.: expression may not be empty", cty.GetAttrPath("expression")},
+		{"Condition is invalid.: repeatSchedules[0] is not a valid schedule", cty.GetAttrPath("repeat_schedules")},
+		{"Condition is invalid.: somethingElse is wrong", nil},
+	}
+	for i, d := range diags {
+		if d.Summary != "Could not create condition" {
+			t.Errorf("got summary %q", d.Summary)
+		}
+		if d.Detail != want[i].detail {
+			t.Errorf("got detail %q, want %q", d.Detail, want[i].detail)
+		}
+		if !d.AttributePath.Equals(want[i].path) {
+			t.Errorf("got path %#v, want %#v", d.AttributePath, want[i].path)
+		}
+	}
+
+	// errors without fields are returned as a single diagnostic.
+	diags = apiErrorDiagnostics("Could not create condition", fmt.Errorf("connection refused"), nil)
+	if len(diags) != 1 || diags[0].Summary != "Could not create condition connection refused" {
+		t.Fatalf("got %v", diags)
+	}
+}
@@ -0,0 +1,221 @@
+package appgate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// attributeVersion is the range of appliance versions that support an attribute.
+type attributeVersion struct {
+	// Path is the attribute in the schema, nested blocks are separated by dots, for example name_resolution.illumio_resolvers.
+	Path string
+	// Added is the first appliance version that supports the attribute, nil if all versions support it.
+	Added *version.Version
+	// Removed is the first appliance version that no longer supports the attribute, nil if it is still supported.
+	Removed *version.Version
+}
+
+var policyClientAttributeVersions = []attributeVersion{
+	{Path: "client_profile_settings", Added: Appliance61Version},
+	{Path: "client_profile_settings.force", Added: Appliance62Version},
+	{Path: "custom_client_help_url", Added: Appliance61Version},
+}
+
+var policySiteAttributeVersions = []attributeVersion{
+	{Path: "override_nearest_site", Added: Appliance62Version},
+	{Path: "apply_fallback_site", Added: Appliance62Version},
+}
+
+var identityProviderAttributeVersions = []attributeVersion{
+	{Path: "network_inactivity_timeout_enabled", Added: Appliance61Version},
+}
+
+func prometheusExporterAttributeVersions(prefix string) []attributeVersion {
+	return []attributeVersion{
+		{Path: prefix + "basic_auth", Added: Appliance62Version},
+		{Path: prefix + "use_https", Added: Appliance62Version},
+		{Path: prefix + "https_p12", Added: Appliance62Version},
+		{Path: prefix + "allowed_users", Added: Appliance62Version},
+		{Path: prefix + "labels_disabled", Added: Appliance63Version},
+	}
+}
+
+// attributeVersions lists, for each resource type, the attributes that are not supported by all appliance versions.
+// Add new attributes here instead of checking the appliance version in the CRUD functions.
+var attributeVersions = map[string][]attributeVersion{
+	"appgatesdp_policy":        append(append([]attributeVersion{}, policyClientAttributeVersions...), policySiteAttributeVersions...),
+	"appgatesdp_access_policy": policySiteAttributeVersions,
+	"appgatesdp_device_policy": policyClientAttributeVersions,
+	"appgatesdp_dns_policy":    policySiteAttributeVersions,
+	"appgatesdp_stop_policy":   policyClientAttributeVersions,
+	"appgatesdp_site": {
+		{Path: "name_resolution.illumio_resolvers", Added: Appliance61Version},
+		{Path: "name_resolution.illumio_resolvers.org_id", Added: Appliance62Version},
+		{Path: "name_resolution.aws_resolvers.ec2", Added: Appliance65Version},
+		{Path: "name_resolution.aws_resolvers.eks", Added: Appliance65Version},
+		{Path: "name_resolution.aws_resolvers.rds", Added: Appliance65Version},
+		{Path: "name_resolution.dns_forwarding.default_ttl_seconds", Removed: Appliance65Version},
+	},
+	"appgatesdp_entitlement": {
+		{Path: "actions.methods", Added: Appliance61Version},
+	},
+	"appgatesdp_ip_pool": {
+		{Path: "excluded_ranges", Added: Appliance61Version},
+	},
+	"appgatesdp_global_settings": {
+		{Path: "registered_device_expiration_days", Added: Appliance62Version},
+	},
+	"appgatesdp_appliance": append(append([]attributeVersion{
+		{Path: "gateway.suspended", Added: Appliance61Version},
+		{Path: "log_forwarder.sumo_logic", Added: Appliance61Version},
+		{Path: "log_forwarder.azure_monitor", Added: Appliance62Version},
+		{Path: "log_forwarder.datadogs", Added: Appliance63Version},
+	}, prometheusExporterAttributeVersions("prometheus_exporter.")...), prometheusExporterAttributeVersions("metrics_aggregator.prometheus_exporter.")...),
+	"appgatesdp_local_database_identity_provider":   identityProviderAttributeVersions,
+	"appgatesdp_ldap_identity_provider":             identityProviderAttributeVersions,
+	"appgatesdp_ldap_certificate_identity_provider": identityProviderAttributeVersions,
+	"appgatesdp_oidc_identity_provider":             identityProviderAttributeVersions,
+	"appgatesdp_radius_identity_provider":           identityProviderAttributeVersions,
+	"appgatesdp_saml_identity_provider":             identityProviderAttributeVersions,
+}
+
+// supports returns false and the reason if the attribute is not supported by the appliance version.
+func (a attributeVersion) supports(v *version.Version) (bool, string) {
+	if a.Added != nil && v.LessThan(a.Added) {
+		return false, fmt.Sprintf("it requires appliance version %s or later", a.Added)
+	}
+	if a.Removed != nil && v.GreaterThanOrEqual(a.Removed) {
+		return false, fmt.Sprintf("it was removed in appliance version %s", a.Removed)
+	}
+	return true, ""
+}
+
+// attributeSupported returns false if the attribute of resourceType in attributeVersions is not supported by
+// the appliance version v, it is used for attributes with a default value that are sent even if they are not configured.
+func attributeSupported(resourceType, path string, v *version.Version) bool {
+	for _, a := range attributeVersions[resourceType] {
+		if a.Path == path {
+			ok, _ := a.supports(v)
+			return ok
+		}
+	}
+	return true
+}
+
+// attributeVersionCustomizeDiff returns a CustomizeDiffFunc that fails the plan if the configuration sets an attribute
+// from attributeVersions that the collective doesn't support, instead of failing during apply.
+func attributeVersionCustomizeDiff(resourceType string) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+		config := d.GetRawConfig()
+		if config.IsNull() || !config.IsKnown() {
+			return nil
+		}
+		return unsupportedAttributes(resourceType, meta, func(path []string) bool {
+			return configSets(config, path)
+		})
+	}
+}
+
+// unsupportedAttributes returns an error that lists the attributes of resourceType in attributeVersions that
+// the collective doesn't support, and configured returns true for.
+func unsupportedAttributes(resourceType string, meta interface{}, configured func(path []string) bool) error {
+	attributes := attributeVersions[resourceType]
+	c, ok := meta.(*Client)
+	if !ok || c == nil || len(attributes) == 0 {
+		return nil
+	}
+	// the appliance version is detected during login.
+	if c.ApplianceVersion == nil {
+		if _, err := c.GetToken(); err != nil {
+			return err
+		}
+	}
+	current := c.ApplianceVersion
+	if current == nil {
+		return nil
+	}
+	var unsupported []string
+	for _, a := range attributes {
+		ok, reason := a.supports(current)
+		if ok || !configured(strings.Split(a.Path, ".")) {
+			continue
+		}
+		unsupported = append(unsupported, fmt.Sprintf("%s is not supported by appliance version %s, %s", a.Path, current, reason))
+	}
+	if len(unsupported) > 0 {
+		return fmt.Errorf("%s", strings.Join(unsupported, "\n"))
+	}
+	return nil
+}
+
+// configSets returns true if the attribute in path is set in the configuration value v,
+// in any element of the nested blocks on the way.
+func configSets(v cty.Value, path []string) bool {
+	if v.IsNull() {
+		return false
+	}
+	if !v.IsKnown() {
+		return len(path) == 0
+	}
+	ty := v.Type()
+	collection := ty.IsListType() || ty.IsSetType() || ty.IsTupleType()
+	if len(path) == 0 {
+		return !collection || v.LengthInt() > 0
+	}
+	if collection {
+		for it := v.ElementIterator(); it.Next(); {
+			if _, e := it.Element(); configSets(e, path) {
+				return true
+			}
+		}
+		return false
+	}
+	if !ty.IsObjectType() || !ty.HasAttribute(path[0]) {
+		return false
+	}
+	return configSets(v.GetAttr(path[0]), path[1:])
+}
+
+// tfConfigSets is configSets for the configuration of a terraform-plugin-framework resource.
+func tfConfigSets(v tftypes.Value, path []string) bool {
+	if v.IsNull() {
+		return false
+	}
+	if !v.IsKnown() {
+		return len(path) == 0
+	}
+	ty := v.Type()
+	if ty.Is(tftypes.List{}) || ty.Is(tftypes.Set{}) || ty.Is(tftypes.Tuple{}) {
+		var elements []tftypes.Value
+		if err := v.As(&elements); err != nil {
+			return false
+		}
+		if len(path) == 0 {
+			return len(elements) > 0
+		}
+		for _, e := range elements {
+			if tfConfigSets(e, path) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(path) == 0 {
+		return true
+	}
+	attributes := map[string]tftypes.Value{}
+	if !ty.Is(tftypes.Object{}) || v.As(&attributes) != nil {
+		return false
+	}
+	a, ok := attributes[path[0]]
+	if !ok {
+		return false
+	}
+	return tfConfigSets(a, path[1:])
+}
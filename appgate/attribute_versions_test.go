@@ -0,0 +1,189 @@
+package appgate
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/go-version"
+	resourceschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAttributeVersionsSchema(t *testing.T) {
+	resources := Provider().ResourcesMap
+	frameworkResources := map[string]resourceschema.Schema{}
+	for _, f := range newFrameworkProvider(Provider())().Resources(context.Background()) {
+		if r, ok := f().(*policyResource); ok {
+			frameworkResources[r.typeName] = r.schema
+		}
+	}
+	for resourceType, attributes := range attributeVersions {
+		if s, ok := frameworkResources[resourceType]; ok {
+			for _, a := range attributes {
+				if !frameworkSchemaHas(s.Attributes, s.Blocks, strings.Split(a.Path, ".")) {
+					t.Errorf("%s: %s is not in the schema", resourceType, a.Path)
+				}
+			}
+			continue
+		}
+		r, ok := resources[resourceType]
+		if !ok {
+			t.Errorf("%s is not a resource", resourceType)
+			continue
+		}
+		if r.CustomizeDiff == nil {
+			t.Errorf("%s has no CustomizeDiff", resourceType)
+		}
+		for _, a := range attributes {
+			s := r.Schema
+			for _, key := range strings.Split(a.Path, ".") {
+				attr, ok := s[key]
+				if !ok {
+					t.Errorf("%s: %s is not in the schema", resourceType, a.Path)
+					break
+				}
+				s = nil
+				if elem, ok := attr.Elem.(*schema.Resource); ok {
+					s = elem.Schema
+				}
+			}
+			if a.Added == nil && a.Removed == nil {
+				t.Errorf("%s: %s has no version", resourceType, a.Path)
+			}
+		}
+	}
+}
+
+func frameworkSchemaHas(attributes map[string]resourceschema.Attribute, blocks map[string]resourceschema.Block, path []string) bool {
+	if _, ok := attributes[path[0]]; ok {
+		return len(path) == 1
+	}
+	b, ok := blocks[path[0]]
+	if !ok {
+		return false
+	}
+	if len(path) == 1 {
+		return true
+	}
+	var nested resourceschema.NestedBlockObject
+	switch b := b.(type) {
+	case resourceschema.ListNestedBlock:
+		nested = b.NestedObject
+	case resourceschema.SetNestedBlock:
+		nested = b.NestedObject
+	default:
+		return false
+	}
+	return frameworkSchemaHas(nested.Attributes, nested.Blocks, path[1:])
+}
+
+func TestAttributeVersionSupports(t *testing.T) {
+	v62, _ := version.NewVersion("6.2.1-27835-release")
+	estimated, _ := version.NewVersion("6.5.0+" + estimatedVersionMetadata)
+	tests := []struct {
+		name    string
+		a       attributeVersion
+		version *version.Version
+		want    bool
+	}{
+		{"added before", attributeVersion{Added: Appliance61Version}, v62, true},
+		{"added same minor", attributeVersion{Added: Appliance62Version}, v62, true},
+		{"added after", attributeVersion{Added: Appliance63Version}, v62, false},
+		{"removed after", attributeVersion{Removed: Appliance65Version}, v62, true},
+		{"removed", attributeVersion{Removed: Appliance65Version}, estimated, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := tt.a.supports(tt.version)
+			if got != tt.want {
+				t.Fatalf("got %t, want %t", got, tt.want)
+			}
+			if !got && len(reason) == 0 {
+				t.Fatal("expected a reason")
+			}
+		})
+	}
+}
+
+func TestConfigSets(t *testing.T) {
+	resolver := func(ec2 cty.Value) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{
+			"name": cty.StringVal("aws"),
+			"ec2":  ec2,
+		})
+	}
+	config := cty.ObjectVal(map[string]cty.Value{
+		"name":            cty.StringVal("site"),
+		"network_subnets": cty.ListValEmpty(cty.String),
+		"notes":           cty.NullVal(cty.String),
+		"name_resolution": cty.ListVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{
+				"aws_resolvers": cty.SetVal([]cty.Value{
+					resolver(cty.NullVal(cty.Bool)),
+					resolver(cty.False),
+				}),
+				"illumio_resolvers": cty.NullVal(cty.Set(cty.Object(map[string]cty.Type{"org_id": cty.String}))),
+				"dns_forwarding":    cty.UnknownVal(cty.List(cty.Object(map[string]cty.Type{"default_ttl_seconds": cty.Number}))),
+			}),
+		}),
+	})
+	tests := map[string]bool{
+		"name":                                     true,
+		"notes":                                    false,
+		"network_subnets":                          false,
+		"name_resolution.aws_resolvers.ec2":        true,
+		"name_resolution.aws_resolvers.rds":        false,
+		"name_resolution.illumio_resolvers":        false,
+		"name_resolution.illumio_resolvers.org_id": false,
+		"name_resolution.dns_forwarding.default_ttl_seconds": false,
+		"ip_pool_mappings": false,
+	}
+	for path, want := range tests {
+		if got := configSets(config, strings.Split(path, ".")); got != want {
+			t.Errorf("configSets(%s) got %t, want %t", path, got, want)
+		}
+	}
+}
+
+func TestAttributeSupported(t *testing.T) {
+	v60, _ := version.NewVersion("6.0.3")
+	v65, _ := version.NewVersion("6.5.0")
+	if attributeSupported("appgatesdp_site", "name_resolution.aws_resolvers.ec2", v60) {
+		t.Error("ec2 is not supported on 6.0")
+	}
+	if !attributeSupported("appgatesdp_site", "name_resolution.aws_resolvers.ec2", v65) {
+		t.Error("ec2 is supported on 6.5")
+	}
+	if !attributeSupported("appgatesdp_site", "name", v60) {
+		t.Error("attributes that are not in attributeVersions are supported")
+	}
+}
+
+func TestAttributeVersionCustomizeDiff(t *testing.T) {
+	v60, _ := version.NewVersion("6.0.3")
+	v61, _ := version.NewVersion("6.1.0")
+	r := resourceAppgateIPPool()
+	config := map[string]interface{}{
+		"name":            "pool",
+		"excluded_ranges": []interface{}{map[string]interface{}{"first": "10.0.0.1", "last": "10.0.0.2"}},
+	}
+	state := &terraform.InstanceState{
+		RawConfig: cty.ObjectVal(map[string]cty.Value{
+			"name": cty.StringVal("pool"),
+			"excluded_ranges": cty.ListVal([]cty.Value{cty.ObjectVal(map[string]cty.Value{
+				"first": cty.StringVal("10.0.0.1"),
+				"last":  cty.StringVal("10.0.0.2"),
+			})}),
+		}),
+	}
+	_, err := r.SimpleDiff(context.Background(), state, terraform.NewResourceConfigRaw(config), &Client{ApplianceVersion: v60})
+	if err == nil || !strings.Contains(err.Error(), "excluded_ranges is not supported by appliance version 6.0.3") {
+		t.Fatalf("got %v, want an excluded_ranges error", err)
+	}
+	if _, err := r.SimpleDiff(context.Background(), state, terraform.NewResourceConfigRaw(config), &Client{ApplianceVersion: v61}); err != nil {
+		t.Fatal(err)
+	}
+}
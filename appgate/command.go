@@ -0,0 +1,74 @@
+package appgate
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// runCommand runs the command line from the provider setting, such as credential_process or otp_command,
+// and returns the program that was run and its stdout. The error includes stderr if the command fails.
+func runCommand(setting, command string, timeout time.Duration) (string, []byte, error) {
+	args, err := splitCommand(command)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid %s %w", setting, err)
+	}
+	if len(args) == 0 {
+		return "", nil, fmt.Errorf("%s is empty", setting)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	log.Printf("[DEBUG] Running %s %s", setting, args[0])
+	if err := cmd.Run(); err != nil {
+		return args[0], nil, fmt.Errorf("%s %s failed %w: %s", setting, args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return args[0], stdout.Bytes(), nil
+}
+
+// splitCommand splits the command line into arguments separated by whitespace,
+// single or double quotes can be used for arguments that contain whitespace.
+func splitCommand(command string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		quote   rune
+		inArg   bool
+	)
+	for _, r := range command {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+				continue
+			}
+			current.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in %q", command)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,32 @@
+package appgate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+		wantErr bool
+	}{
+		{command: "vault-wrapper appgate", want: []string{"vault-wrapper", "appgate"}},
+		{command: `  /usr/bin/creds   --profile "prod collective" `, want: []string{"/usr/bin/creds", "--profile", "prod collective"}},
+		{command: `"C:\Program Files\creds.exe" --json`, want: []string{`C:\Program Files\creds.exe`, "--json"}},
+		{command: `creds --name ''`, want: []string{"creds", "--name", ""}},
+		{command: `creds "unterminated`, wantErr: true},
+		{command: "", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			got, err := splitCommand(tt.command)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitCommand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitCommand() got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package appgate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// etagSchema is used by resources that read, modify and write back the whole object on update,
+// so we can detect if the object has been modified outside terraform between plan and apply.
+// The SDK has no private state for resources, so unlike the policy resources on the framework,
+// which keep the etag in the private state, the SDK resources need a computed attribute.
+func etagSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeString,
+		Description: "Hash of the object when it was last read by terraform, used to detect changes made outside terraform.",
+		Computed:    true,
+	}
+}
+
+// etagCustomizeDiff marks etag as unknown when the object will be updated,
+// since the controller will return a new version of the object.
+func etagCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+	for _, k := range d.GetChangedKeysPrefix("") {
+		if k != "etag" {
+			return d.SetNewComputed("etag")
+		}
+	}
+	return nil
+}
+
+// objectETag returns a hash of the object as returned by the controller.
+func objectETag(object interface{}) string {
+	b, err := json.Marshal(object)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// checkNotModified returns an error diagnostic if current, the object we just got from the controller,
+// does not match the etag from the last time terraform read it. Resources without an etag in the state,
+// for example state written by an older provider version, are not checked.
+func checkNotModified(d *schema.ResourceData, meta interface{}, kind string, current interface{}) diag.Diagnostics {
+	// use the value from the state, etag is unknown in the plan when the resource is updated.
+	known, _ := d.GetChange("etag")
+	etag, _ := known.(string)
+	if !modifiedOutsideTerraform(meta, etag, current) {
+		return nil
+	}
+	return diag.Diagnostics{
+		{
+			Severity: diag.Error,
+			Summary:  modifiedOutsideTerraformSummary(kind, d.Id()),
+			Detail:   modifiedOutsideTerraformDetail(kind),
+		},
+	}
+}
+
+// modifiedOutsideTerraform returns true if current does not match etag, unless force_overwrite is set.
+func modifiedOutsideTerraform(meta interface{}, etag string, current interface{}) bool {
+	if c, ok := meta.(*Client); ok && c.Config != nil && c.Config.ForceOverwrite {
+		return false
+	}
+	return len(etag) > 0 && etag != objectETag(current)
+}
+
+func modifiedOutsideTerraformSummary(kind, id string) string {
+	return fmt.Sprintf("%s %q modified outside Terraform", kind, id)
+}
+
+func modifiedOutsideTerraformDetail(kind string) string {
+	return fmt.Sprintf(
+		"The %s has been modified on the controller since terraform last read it, "+
+			"applying this plan would overwrite those changes. Run terraform plan again to review the changes, "+
+			"or set force_overwrite = true in the provider configuration to overwrite them.",
+		kind,
+	)
+}
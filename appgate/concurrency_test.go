@@ -0,0 +1,161 @@
+package appgate
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestObjectETag(t *testing.T) {
+	a := openapi.NewCondition("condition", "return true;")
+	b := openapi.NewCondition("condition", "return true;")
+	if objectETag(a) != objectETag(b) {
+		t.Fatal("expected the same etag for equal objects")
+	}
+	b.SetNotes("changed in the admin UI")
+	if objectETag(a) == objectETag(b) {
+		t.Fatal("expected a new etag for a modified object")
+	}
+}
+
+func TestConditionUpdateModifiedOutsideTerraform(t *testing.T) {
+	const id = "ee7f8e9c-6e4a-4c3b-8b5d-3b1c2a1e0f55"
+	lastRead := openapi.NewCondition("condition", "return true;")
+	lastRead.SetId(id)
+	modified := openapi.NewCondition("condition", "return false;")
+	modified.SetId(id)
+
+	tests := []struct {
+		name           string
+		etag           string
+		forceOverwrite bool
+		wantErr        string
+		wantPut        bool
+	}{
+		{
+			name:    "unchanged",
+			etag:    objectETag(modified),
+			wantPut: true,
+		},
+		{
+			name:    "modified",
+			etag:    objectETag(lastRead),
+			wantErr: fmt.Sprintf("Condition %q modified outside Terraform", id),
+		},
+		{
+			name:           "modified with force_overwrite",
+			etag:           objectETag(lastRead),
+			forceOverwrite: true,
+			wantPut:        true,
+		},
+		{
+			name:    "state without etag",
+			wantPut: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, mux, _, port, teardown := setup()
+			defer teardown()
+			mux.HandleFunc("/admin/login", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, loginResponse)
+			})
+			var puts int32
+			mux.HandleFunc("/admin/conditions/"+id, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPut {
+					atomic.AddInt32(&puts, 1)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				body, _ := modified.MarshalJSON()
+				w.Write(body)
+			})
+			c := &Config{
+				URL:            fmt.Sprintf("http://localhost:%d", port),
+				Username:       "admin",
+				Password:       "admin",
+				Version:        22,
+				LoginTimeout:   1,
+				ForceOverwrite: tt.forceOverwrite,
+			}
+			client, err := c.Client()
+			if err != nil {
+				t.Fatal(err)
+			}
+			d := resourceAppgateCondition().Data(&terraform.InstanceState{
+				ID: id,
+				Attributes: map[string]string{
+					"name":       "condition",
+					"expression": "return true;",
+					"etag":       tt.etag,
+				},
+			})
+			d.Set("notes", "updated by terraform")
+
+			diags := resourceAppgateConditionUpdate(context.Background(), d, client)
+			if len(tt.wantErr) > 0 {
+				if !diags.HasError() || !strings.Contains(diags[0].Summary, tt.wantErr) {
+					t.Fatalf("got %v, expected error %q", diags, tt.wantErr)
+				}
+			} else if diags.HasError() {
+				t.Fatalf("got %v, expected no error", diags)
+			}
+			if got := atomic.LoadInt32(&puts) > 0; got != tt.wantPut {
+				t.Fatalf("got PUT %t, expected %t", got, tt.wantPut)
+			}
+			if tt.wantPut && d.Get("etag").(string) != objectETag(modified) {
+				t.Fatal("expected the etag to be updated from the controller")
+			}
+		})
+	}
+}
+
+func TestETagResources(t *testing.T) {
+	resources := Provider().ResourcesMap
+	for _, name := range []string{
+		"appgatesdp_administrative_role",
+		"appgatesdp_appliance",
+		"appgatesdp_appliance_customization",
+		"appgatesdp_client_profile",
+		"appgatesdp_condition",
+		"appgatesdp_connector_identity_provider",
+		"appgatesdp_criteria_script",
+		"appgatesdp_device_script",
+		"appgatesdp_entitlement",
+		"appgatesdp_entitlement_script",
+		"appgatesdp_global_settings",
+		"appgatesdp_ip_pool",
+		"appgatesdp_ldap_certificate_identity_provider",
+		"appgatesdp_ldap_identity_provider",
+		"appgatesdp_local_database_identity_provider",
+		"appgatesdp_local_user",
+		"appgatesdp_mfa_provider",
+		"appgatesdp_admin_mfa_settings",
+		"appgatesdp_oidc_identity_provider",
+		"appgatesdp_radius_identity_provider",
+		"appgatesdp_replication_target",
+		"appgatesdp_ringfence_rule",
+		"appgatesdp_saml_identity_provider",
+		"appgatesdp_site",
+		"appgatesdp_trusted_certificate",
+		"appgatesdp_user_claim_script",
+	} {
+		r, ok := resources[name]
+		if !ok {
+			t.Errorf("%s is not in the provider", name)
+			continue
+		}
+		if s, ok := r.Schema["etag"]; !ok || !s.Computed {
+			t.Errorf("%s has no computed etag", name)
+		}
+		if r.CustomizeDiff == nil {
+			t.Errorf("%s does not mark the etag unknown on update", name)
+		}
+	}
+}
@@ -2,16 +2,16 @@ package appgate
 
 import (
 	"context"
-	"crypto/tls"
 	"crypto/x509"
 	b64 "encoding/base64"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"net/http"
 	"net/url"
-	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -19,29 +19,83 @@ import (
 	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
 	"github.com/cenkalti/backoff/v4"
 	"github.com/hashicorp/go-version"
-	"golang.org/x/net/http/httpproxy"
 )
 
 const (
 	// DefaultDescription is the default string for terraform resources.
 	DefaultDescription = "Managed by terraform"
+
+	// tokenExpiryLeeway is how long before the token expires we will authenticate again.
+	tokenExpiryLeeway = 1 * time.Minute
+
+	// estimatedVersionMetadata is the version metadata used when the appliance version is estimated from the client version.
+	estimatedVersionMetadata = "estimated"
 )
 
+type contextKey string
+
+// noReauthCtx disables reauthTransport for a request.
+const noReauthCtx contextKey = "noReauth"
+
 // Config for appgate provider.
 type Config struct {
-	URL          string        `json:"appgate_url,omitempty"`
-	Username     string        `json:"appgate_username,omitempty"`
-	Password     string        `json:"appgate_password,omitempty"`
-	Provider     string        `json:"appgate_provider,omitempty"`
-	Insecure     bool          `json:"appgate_insecure,omitempty"`
-	Timeout      int           `json:"appgate_timeout,omitempty"`
-	LoginTimeout time.Duration `json:"appgate_login_timeout,omitempty"`
-	Debug        bool          `json:"appgate_http_debug,omitempty"`
-	Version      int           `json:"appgate_client_version,omitempty"`
-	BearerToken  string        `json:"appgate_bearer_token,omitempty"`
-	PemFilePath  string        `json:"appgate_pem_filepath,omitempty"`
-	DeviceID     string        `json:"appgate_device_id,omitempty"`
-	UserAgent    string
+	URL string `json:"appgate_url,omitempty"`
+	// URLs is an optional list of controllers in a HA collective, used instead of URL.
+	URLs      []string `json:"appgate_urls,omitempty"`
+	URLsOrder string   `json:"appgate_urls_order,omitempty"`
+	Username  string   `json:"appgate_username,omitempty"`
+	Password  string   `json:"appgate_password,omitempty"`
+	Provider  string   `json:"appgate_provider,omitempty"`
+	Insecure  bool     `json:"appgate_insecure,omitempty"`
+	Timeout   int      `json:"appgate_timeout,omitempty"`
+	// RequestTimeout, TLSHandshakeTimeout, IdleConnTimeout and MaxIdleConnsPerHost configure the http.Transport.
+	RequestTimeout      time.Duration `json:"appgate_request_timeout,omitempty"`
+	TLSHandshakeTimeout time.Duration `json:"appgate_tls_handshake_timeout,omitempty"`
+	IdleConnTimeout     time.Duration `json:"appgate_idle_conn_timeout,omitempty"`
+	MaxIdleConnsPerHost int           `json:"appgate_max_idle_conns_per_host,omitempty"`
+	LoginTimeout        time.Duration `json:"appgate_login_timeout,omitempty"`
+	Debug               bool          `json:"appgate_http_debug,omitempty"`
+	Version             int           `json:"appgate_client_version,omitempty"`
+	BearerToken         string        `json:"appgate_bearer_token,omitempty"`
+	PemFilePath         string        `json:"appgate_pem_filepath,omitempty"`
+	DeviceID            string        `json:"appgate_device_id,omitempty"`
+	// ClientCert and ClientKey are used for mutual TLS, as PEM content or file paths.
+	ClientCert string `json:"appgate_client_cert,omitempty"`
+	ClientKey  string `json:"appgate_client_key,omitempty"`
+	// PinnedFingerprints are SHA-256 SPKI fingerprints of the controller certificate or CA.
+	PinnedFingerprints []string `json:"appgate_pinned_fingerprints,omitempty"`
+	// ProxyURL and NoProxy are used instead of HTTP_PROXY, HTTPS_PROXY and NO_PROXY.
+	ProxyURL      string `json:"appgate_proxy_url,omitempty"`
+	NoProxy       string `json:"appgate_no_proxy,omitempty"`
+	ProxyUsername string `json:"appgate_proxy_username,omitempty"`
+	ProxyPassword string `json:"appgate_proxy_password,omitempty"`
+	// MaxRetries and RetryMaxWait configure how requests are retried when the controller is busy.
+	MaxRetries   int           `json:"appgate_max_retries,omitempty"`
+	RetryMaxWait time.Duration `json:"appgate_retry_max_wait,omitempty"`
+	// MaxConcurrentRequests and MaxWritesPerSecond limit the load on the controller, 0 is unlimited.
+	MaxConcurrentRequests int     `json:"appgate_max_concurrent_requests,omitempty"`
+	MaxWritesPerSecond    float64 `json:"appgate_max_writes_per_second,omitempty"`
+	// ReadOnly rejects all requests that can modify the collective, except login.
+	ReadOnly bool `json:"appgate_read_only,omitempty"`
+	// ForceOverwrite disables the check for objects modified outside terraform before they are updated.
+	ForceOverwrite bool `json:"appgate_force_overwrite,omitempty"`
+	// DefaultTags are added to the tags of all resources.
+	DefaultTags []string `json:"appgate_default_tags,omitempty"`
+	// TokenCachePath is an optional file used to reuse tokens between terraform invocations.
+	TokenCachePath string `json:"appgate_token_cache_path,omitempty"`
+	// OTPSeed or OTPCommand is used to complete the admin MFA challenge during login.
+	OTPSeed    string `json:"appgate_otp_seed,omitempty"`
+	OTPCommand string `json:"appgate_otp_command,omitempty"`
+	// CredentialProcess is an optional command that outputs the credentials as json.
+	CredentialProcess  string    `json:"appgate_credential_process,omitempty"`
+	CredentialsExpires time.Time `json:"-"`
+	UserAgent          string
+	// logCtx is the context from the provider configuration, used to log requests
+	// made without the terraform context.
+	logCtx context.Context
+	// RefreshBearerToken is used to get a new BearerToken if the current one
+	// is rejected by the controller, for example if it has been rotated outside terraform.
+	RefreshBearerToken func() (string, error) `json:"-"`
 }
 
 // Validate makes sure we have minimum required configuration values to authenticate against the controller.
@@ -51,8 +105,10 @@ func (c *Config) Validate(usingFile bool) error {
 	if usingFile {
 		return nil
 	}
-	if !isUrl(c.URL) {
-		return fmt.Errorf("Controller URL is mandatory, got %q", c.URL)
+	for _, u := range c.controllerURLs() {
+		if !isUrl(u) {
+			return fmt.Errorf("Controller URL is mandatory, got %q", u)
+		}
 	}
 	if len(c.BearerToken) > 0 {
 		_, err := b64.StdEncoding.DecodeString(c.BearerToken)
@@ -70,81 +126,97 @@ func (c *Config) Validate(usingFile bool) error {
 type Client struct {
 	mu               sync.Mutex
 	Token            string
+	TokenExpires     time.Time
 	UUID             string
 	ApplianceVersion *version.Version
 	ClientVersion    int
 	API              *openapi.APIClient
 	OldAPI           *v22.APIClient
 	Config           *Config
-}
-
-var proxyFunc func(*url.URL) (*url.URL, error)
-
-func proxyFromEnvironment(req *http.Request) (*url.URL, error) {
-	if key, ok := os.LookupEnv("HTTP_PROXY"); ok {
-		proxyURL, err := url.Parse(key)
-		if err != nil {
-			return nil, err
-		}
-		log.Printf("[DEBUG] Using HTTP PROXY %s", proxyURL)
-		proxyConfig := &httpproxy.Config{
-			// use the same Addr for both HTTPS_PROXY and HTTP_PROXY for backwards compatibility
-			HTTPProxy:  proxyURL.String(),
-			HTTPSProxy: proxyURL.String(),
-		}
-		if noProxy, ok := os.LookupEnv("NO_PROXY"); ok {
-			log.Printf("[DEBUG] Using NO_PROXY %s", noProxy)
-			proxyConfig.NoProxy = noProxy
-		}
-		proxyFunc = proxyConfig.ProxyFunc()
-	}
-	if proxyFunc == nil {
-		proxyFunc = httpproxy.FromEnvironment().ProxyFunc()
-		log.Printf("[DEBUG] Using HTTP PROXY FromEnvironment")
-	}
-	return proxyFunc(req.URL)
+	failover         *failoverTransport
 }
 
 // Client creates the http client, APIClient, and setup configuration for
 // custom pem file
 // toggle tls verification based on config
-// setup http proxy based on environment variables
+// setup http proxy based on the provider configuration and environment variables
 func (c *Config) Client() (*Client, error) {
-	timeoutDuration := time.Duration(c.Timeout)
+	dialTimeout := time.Duration(c.Timeout) * time.Second
+	if c.Timeout <= 0 {
+		dialTimeout = DefaultTimeout * time.Second
+	}
+	requestTimeout := c.RequestTimeout
+	if requestTimeout <= 0 {
+		requestTimeout = DefaultRequestTimeout
+	}
+	tlsHandshakeTimeout := c.TLSHandshakeTimeout
+	if tlsHandshakeTimeout <= 0 {
+		tlsHandshakeTimeout = DefaultTLSHandshakeTimeout
+	}
+	idleConnTimeout := c.IdleConnTimeout
+	if idleConnTimeout <= 0 {
+		idleConnTimeout = DefaultIdleConnTimeout
+	}
+	maxIdleConnsPerHost := c.MaxIdleConnsPerHost
+	if maxIdleConnsPerHost <= 0 {
+		maxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	}
 
-	rootCAs, _ := x509.SystemCertPool()
-	if rootCAs == nil {
-		rootCAs = x509.NewCertPool()
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
 	}
-	if ok, err := FileExists(c.PemFilePath); err == nil && ok {
-		certs, err := os.ReadFile(c.PemFilePath)
+	proxy, err := c.proxyFunc()
+	if err != nil {
+		return nil, err
+	}
+	tr := &http.Transport{
+		TLSClientConfig: tlsConfig,
+		DialContext: (&net.Dialer{
+			Timeout:   dialTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout: tlsHandshakeTimeout,
+		IdleConnTimeout:     idleConnTimeout,
+		MaxIdleConnsPerHost: maxIdleConnsPerHost,
+		// we use a custom dialer and tls.Config, so HTTP/2 must be enabled explicitly.
+		// It is only used if the controller supports it.
+		ForceAttemptHTTP2: true,
+		Proxy:             proxy,
+	}
+
+	client := &Client{
+		ClientVersion: c.Version,
+		Config:        c,
+	}
+	var next http.RoundTripper = &loggingTransport{logCtx: c.logCtx, bodies: c.Debug, next: tr}
+	next = &timeoutTransport{timeout: requestTimeout, next: next}
+	var serverURL string
+	if len(c.URLs) > 0 {
+		failover, err := newFailoverTransport(c.URLs, c.URLsOrder, next)
 		if err != nil {
-			return nil, fmt.Errorf("could not read pem file %w", err)
+			return nil, err
 		}
-		if ok := rootCAs.AppendCertsFromPEM(certs); !ok {
-			return nil, fmt.Errorf("unable to append cert %s", c.PemFilePath)
+		next = failover
+		serverURL = failover.primary()
+		client.failover = failover
+	} else {
+		u, err := NormalizeConfigurationURL(c.URL)
+		if err != nil {
+			return nil, err
 		}
+		serverURL = u
 	}
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: c.Insecure,
-			RootCAs:            rootCAs,
-		},
-		Dial: (&net.Dialer{
-			Timeout: timeoutDuration * time.Second,
-		}).Dial,
-		TLSHandshakeTimeout: timeoutDuration * time.Second,
-		Proxy:               proxyFromEnvironment,
+	if c.MaxConcurrentRequests > 0 || c.MaxWritesPerSecond > 0 {
+		next = newThrottleTransport(c.MaxConcurrentRequests, c.MaxWritesPerSecond, next)
 	}
-
-	httpclient := &http.Client{
-		Transport: tr,
-		Timeout:   ((timeoutDuration * 2) * time.Second),
+	next = &retryTransport{maxRetries: c.MaxRetries, maxWait: c.RetryMaxWait, next: next}
+	if c.ReadOnly {
+		log.Printf("[INFO] read_only is enabled, requests that modify the collective are rejected")
+		next = &readOnlyTransport{next: next}
 	}
-
-	serverURL, err := NormalizeConfigurationURL(c.URL)
-	if err != nil {
-		return nil, err
+	httpclient := &http.Client{
+		Transport: &reauthTransport{client: client, next: next},
 	}
 
 	clientCfg := &openapi.Configuration{
@@ -152,7 +224,6 @@ func (c *Config) Client() (*Client, error) {
 			"Accept": fmt.Sprintf("application/vnd.appgate.peer-v%d+json", c.Version),
 		},
 		UserAgent: c.UserAgent,
-		Debug:     c.Debug,
 		Servers: []openapi.ServerConfiguration{
 			{
 				URL: serverURL,
@@ -169,7 +240,6 @@ func (c *Config) Client() (*Client, error) {
 			"Accept": fmt.Sprintf("application/vnd.appgate.peer-v%d+json", c.Version),
 		},
 		UserAgent: c.UserAgent,
-		Debug:     c.Debug,
 		Servers: []v22.ServerConfiguration{
 			{
 				URL: serverURL,
@@ -182,12 +252,8 @@ func (c *Config) Client() (*Client, error) {
 		return nil, errors.New("failed to initialize old api client")
 	}
 
-	client := &Client{
-		API:           apiClient,
-		OldAPI:        oldApiClient,
-		ClientVersion: c.Version,
-		Config:        c,
-	}
+	client.API = apiClient
+	client.OldAPI = oldApiClient
 
 	return client, nil
 }
@@ -211,24 +277,24 @@ func NormalizeConfigurationURL(u string) (string, error) {
 	return url.String(), nil
 }
 
+// guessVersion estimates the appliance version from the client version, it is only used
+// if we can't detect the controller version with detectApplianceVersion.
 func guessVersion(clientVersion int) (*version.Version, error) {
-	// TODO query GET /appliance controller and check exact version.
-	// POST /login does not include version anymore.
 	switch clientVersion {
 	case Version18:
-		return version.NewVersion("6.1.0+estimated")
+		return version.NewVersion("6.1.0+" + estimatedVersionMetadata)
 	case Version19:
-		return version.NewVersion("6.2.0+estimated")
+		return version.NewVersion("6.2.0+" + estimatedVersionMetadata)
 	case Version20:
-		return version.NewVersion("6.3.0+estimated")
+		return version.NewVersion("6.3.0+" + estimatedVersionMetadata)
 	case Version21:
-		return version.NewVersion("6.4.0+estimated")
+		return version.NewVersion("6.4.0+" + estimatedVersionMetadata)
 	case Version22:
-		return version.NewVersion("6.5.0+estimated")
+		return version.NewVersion("6.5.0+" + estimatedVersionMetadata)
 	case Version23:
-		return version.NewVersion("6.6.0+estimated")
+		return version.NewVersion("6.6.0+" + estimatedVersionMetadata)
 	case Version24:
-		return version.NewVersion("6.7.0+estimated")
+		return version.NewVersion("6.7.0+" + estimatedVersionMetadata)
 	}
 	return nil, fmt.Errorf("could not determine appliance version with client version %d", clientVersion)
 }
@@ -240,17 +306,106 @@ func (c *Client) GetToken() (string, error) {
 	// client default header values and only authenticate if we haven't already cached a bearer token.
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	return c.authenticate()
+}
+
+// refreshToken discards the cached token if it is the one that was rejected by the
+// controller, and authenticate again. If another request has already refreshed the
+// token while we waited for the lock, the new token is returned as is.
+func (c *Client) refreshToken(rejected string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.Token) > 0 && c.Token != rejected {
+		log.Printf("[DEBUG] Token already refreshed")
+		return c.Token, nil
+	}
+	log.Printf("[DEBUG] Token rejected by the controller, authenticate again")
+	c.Token = ""
+	c.TokenExpires = time.Time{}
+	cfg := c.Config
+	if len(cfg.TokenCachePath) > 0 {
+		if err := deleteCachedToken(cfg); err != nil {
+			log.Printf("[WARN] Could not remove token from token cache %s: %s", cfg.TokenCachePath, err)
+		}
+	}
+	if len(cfg.CredentialProcess) > 0 {
+		// the credentials might have been revoked, so we will ask for new ones.
+		if err := cfg.loadCredentialProcess(); err != nil {
+			return "", err
+		}
+	} else if len(cfg.BearerToken) > 0 {
+		if cfg.RefreshBearerToken == nil {
+			return "", errors.New("appgate bearer_token was rejected by the controller; only a bearer_token from config_path can be refreshed, run terraform again with a new token")
+		}
+		token, err := cfg.RefreshBearerToken()
+		if err != nil {
+			return "", fmt.Errorf("could not refresh appgate bearer_token %w", err)
+		}
+		cfg.BearerToken = token
+	}
+	return c.authenticate()
+}
+
+// tokenExpired returns true if the cached token is about to expire.
+// tokens without a known expiry never expire on the client side, we will
+// rely on the controller rejecting them instead.
+func (c *Client) tokenExpired() bool {
+	if c.TokenExpires.IsZero() {
+		return false
+	}
+	return time.Now().Add(tokenExpiryLeeway).After(c.TokenExpires)
+}
+
+// authenticate must be called while holding c.mu
+func (c *Client) authenticate() (string, error) {
 	cfg := c.Config
 
+	if len(cfg.CredentialProcess) > 0 && cfg.credentialsExpired() {
+		log.Printf("[DEBUG] Credentials from credential_process expired")
+		if err := cfg.loadCredentialProcess(); err != nil {
+			return "", err
+		}
+		c.Token = ""
+		c.TokenExpires = time.Time{}
+	}
+
 	if len(cfg.BearerToken) > 0 {
 		log.Printf("[DEBUG] Authenticate with Bearer token provided as APPGATE_BEARER_TOKEN")
 		c.Token = fmt.Sprintf("Bearer %s", cfg.BearerToken)
+		if c.ApplianceVersion == nil {
+			estimated, err := guessVersion(cfg.Version)
+			if err != nil {
+				return "", err
+			}
+			c.setApplianceVersion(c.Token, estimated)
+		}
 		return c.Token, nil
 	}
-	if len(c.Token) > 0 {
+	if len(c.Token) > 0 && !c.tokenExpired() {
 		log.Printf("[DEBUG] Using existing token")
 		return c.Token, nil
 	}
+	if len(cfg.TokenCachePath) > 0 {
+		cached, err := loadCachedToken(cfg)
+		if err != nil {
+			log.Printf("[WARN] Could not read token cache %s: %s", cfg.TokenCachePath, err)
+		}
+		if cached != nil {
+			log.Printf("[DEBUG] Using token from token cache %s", cfg.TokenCachePath)
+			if cfg.Version == MinimumSupportedVersion && cached.ClientVersion > 0 {
+				cfg.Version = cached.ClientVersion
+				c.API.GetConfig().DefaultHeader["Accept"] = fmt.Sprintf("application/vnd.appgate.peer-v%d+json", cfg.Version)
+			}
+			estimated, err := guessVersion(cfg.Version)
+			if err != nil {
+				return "", err
+			}
+			c.Token = cached.Token
+			c.TokenExpires = cached.Expires
+			c.setApplianceVersion(c.Token, estimated)
+			return c.Token, nil
+		}
+	}
 
 	// if the client_version is set to the default minimum value, we will do
 	// a error request to login to determine the maximum allowed version for the current
@@ -272,20 +427,140 @@ func (c *Client) GetToken() (string, error) {
 		}
 		c.API.GetConfig().DefaultHeader["Accept"] = fmt.Sprintf("application/vnd.appgate.peer-v%d+json", cfg.Version)
 	}
-	currentVersion, err := guessVersion(cfg.Version)
+	estimated, err := guessVersion(cfg.Version)
 	if err != nil {
 		return "", err
 	}
-	c.ApplianceVersion = currentVersion
 
-	if len(c.Token) > 0 {
-		return c.Token, nil
-	}
 	response, err := c.login(context.Background())
 	if err != nil {
 		return "", err
 	}
-	return *response.Token, nil
+	if user := response.GetUser(); user.GetNeedTwoFactorAuth() {
+		response, err = c.completeAdminMFA(context.Background(), response.GetToken())
+		if err != nil {
+			return "", err
+		}
+	}
+	c.Token = response.GetToken()
+	c.TokenExpires = time.Time{}
+	// if the controller clock is behind ours, the expiry date is not
+	// reliable, so we will wait for the controller to reject the token instead.
+	if expires, ok := response.GetExpiresOk(); ok && expires.After(time.Now()) {
+		c.TokenExpires = *expires
+		if len(cfg.TokenCachePath) > 0 {
+			t := cachedToken{
+				Token:         c.Token,
+				Expires:       c.TokenExpires,
+				ClientVersion: cfg.Version,
+			}
+			if err := saveCachedToken(cfg, t); err != nil {
+				log.Printf("[WARN] Could not save token in token cache %s: %s", cfg.TokenCachePath, err)
+			}
+		}
+	}
+	c.setApplianceVersion(c.Token, estimated)
+	return c.Token, nil
+}
+
+// setApplianceVersion sets ApplianceVersion to the controller version, or the estimated
+// version from the client version if the controller version can not be detected.
+func (c *Client) setApplianceVersion(token string, estimated *version.Version) {
+	// we only need to detect the version once, not every time the token is renewed.
+	if c.ApplianceVersion != nil && c.ApplianceVersion.Metadata() != estimatedVersionMetadata {
+		return
+	}
+	v, err := c.detectApplianceVersion(token)
+	if err != nil {
+		log.Printf("[WARN] Could not detect controller version, fallback to %s: %s", estimated, err)
+		c.ApplianceVersion = estimated
+		return
+	}
+	log.Printf("[DEBUG] Detected controller version %s", v)
+	c.ApplianceVersion = v
+}
+
+// detectApplianceVersion returns the version of the controllers in the collective from GET /appliances/status.
+// During an upgrade the controllers can run different versions, so we use the lowest one.
+func (c *Client) detectApplianceVersion(token string) (*version.Version, error) {
+	// we are holding c.mu, so we can't authenticate again within this request.
+	ctx := context.WithValue(context.Background(), noReauthCtx, true)
+	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	stats, _, err := c.API.AppliancesApi.AppliancesStatusGet(ctx).Execute()
+	if err != nil {
+		return nil, prettyPrintAPIError(err)
+	}
+	var lowest *version.Version
+	for _, appliance := range stats.GetData() {
+		ctrl := appliance.GetController()
+		if !ctrl.GetEnabled() {
+			continue
+		}
+		v, err := parseApplianceVersion(appliance.GetApplianceVersion())
+		if err != nil {
+			log.Printf("[DEBUG] Could not parse version of controller %s: %s", appliance.GetName(), err)
+			continue
+		}
+		if lowest == nil || v.LessThan(lowest) {
+			lowest = v
+		}
+	}
+	if lowest == nil {
+		return nil, errors.New("no controller version found in appliance status")
+	}
+	return lowest, nil
+}
+
+// parseApplianceVersion parses the appliance build version, for example 6.2.1-27835-release.
+// The build number is kept as metadata, since go-version would otherwise treat it
+// as a pre-release, and 6.2.0-27835-release would be lower than 6.2.0.
+func parseApplianceVersion(s string) (*version.Version, error) {
+	v, err := version.NewVersion(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(v.Prerelease()) == 0 {
+		return v, nil
+	}
+	return version.NewVersion(fmt.Sprintf("%s+%s", v.Core(), v.Prerelease()))
+}
+
+// reauthTransport replays a request once with a new token if the controller
+// responds with HTTP 401, for example when the token has expired or been revoked
+// during a long running terraform apply.
+type reauthTransport struct {
+	client *Client
+	next   http.RoundTripper
+}
+
+func (t *reauthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.next.RoundTrip(req)
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+	// requests without authorization header, such as POST /login, can not be replayed with a new token.
+	auth := req.Header.Get("Authorization")
+	if len(auth) == 0 || (req.Body != nil && req.GetBody == nil) || req.Context().Value(noReauthCtx) != nil {
+		return res, err
+	}
+	token, refreshErr := t.client.refreshToken(strings.TrimPrefix(auth, "Bearer "))
+	if refreshErr != nil {
+		log.Printf("[WARN] Could not authenticate again after HTTP 401 on %s %s: %s", req.Method, req.URL.Path, refreshErr)
+		return res, err
+	}
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, bodyErr := req.GetBody()
+		if bodyErr != nil {
+			return res, err
+		}
+		retry.Body = body
+	}
+	retry.Header.Set("Authorization", "Bearer "+token)
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+	log.Printf("[DEBUG] Replay %s %s with new token", req.Method, req.URL.Path)
+	return t.next.RoundTrip(retry)
 }
 
 var exponentialBackOff = backoff.ExponentialBackOff{
@@ -329,6 +604,10 @@ func (c *Client) login(ctx context.Context) (*openapi.LoginAuthorizationResponse
 					Err: fmt.Errorf("Import certificate or toggle APPGATE_INSECURE - %s", err),
 				}
 			}
+			var pinErr *pinnedCertificateError
+			if err != nil && errors.As(err, &pinErr) {
+				return &backoff.PermanentError{Err: pinErr}
+			}
 			log.Printf("[DEBUG] Login failed, No response %s", err)
 			return fmt.Errorf("No response from controller %w", err)
 		}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -365,7 +366,7 @@ func TestClient(t *testing.T) {
 				return
 			}
 			hc := appgateClient.API.GetConfig().HTTPClient
-			tr := hc.Transport.(*http.Transport)
+			tr := httpTransport(t, hc.Transport)
 			if tr.TLSClientConfig.InsecureSkipVerify != tt.wantInsecure {
 				t.Fatalf("got %v expected %v", tr.TLSClientConfig.InsecureSkipVerify, tt.wantInsecure)
 			}
@@ -379,6 +380,203 @@ func TestClient(t *testing.T) {
 	}
 }
 
+func TestClientDetectApplianceVersion(t *testing.T) {
+	_, _, mux, _, port, teardown := setup()
+	defer teardown()
+	mux.HandleFunc("/admin/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, loginResponse)
+	})
+	mux.HandleFunc("/admin/appliances/status", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+	"data": [
+		{"name": "controller-one", "hostname": "one", "controller": {"enabled": true}, "applianceVersion": "6.5.2-31870-release"},
+		{"name": "controller-two", "hostname": "two", "controller": {"enabled": true}, "applianceVersion": "6.5.1-31654-release"},
+		{"name": "gateway", "hostname": "three", "controller": {"enabled": false}, "applianceVersion": "6.4.0-29900-release"}
+	]
+}`)
+	})
+	c := &Config{
+		URL:          fmt.Sprintf("http://localhost:%d", port),
+		Username:     "admin",
+		Password:     "admin",
+		Version:      22,
+		LoginTimeout: 1,
+	}
+	appgateClient, err := c.Client()
+	if err != nil {
+		t.Fatalf("got err %s expected nil", err)
+	}
+	if _, err := appgateClient.GetToken(); err != nil {
+		t.Fatalf("got err %s expected nil", err)
+	}
+	expected, _ := version.NewVersion("6.5.1")
+	if !appgateClient.ApplianceVersion.Equal(expected) {
+		t.Fatalf("Expected %s, got %s", expected, appgateClient.ApplianceVersion)
+	}
+	if !appgateClient.ApplianceVersion.GreaterThanOrEqual(Appliance65Version) {
+		t.Fatalf("Expected %s to be >= %s", appgateClient.ApplianceVersion, Appliance65Version)
+	}
+}
+
+func TestParseApplianceVersion(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "6.2.1-27835-release", want: "6.2.1+27835-release"},
+		{input: "6.2.0-27835-release", want: "6.2.0+27835-release"},
+		{input: "6.7.0", want: "6.7.0"},
+		{input: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseApplianceVersion(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseApplianceVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.String() != tt.want {
+				t.Errorf("got %s want %s", got, tt.want)
+			}
+			core, _ := version.NewVersion(got.Core().String())
+			if !got.Equal(core) {
+				t.Errorf("expected %s to equal %s", got, core)
+			}
+		})
+	}
+}
+
+// httpTransport returns the underlying *http.Transport from the client RoundTripper chain.
+func httpTransport(t *testing.T, rt http.RoundTripper) *http.Transport {
+	t.Helper()
+	for {
+		switch v := rt.(type) {
+		case *http.Transport:
+			return v
+		case *reauthTransport:
+			rt = v.next
+		case *failoverTransport:
+			rt = v.next
+		case *loggingTransport:
+			rt = v.next
+		case *retryTransport:
+			rt = v.next
+		case *throttleTransport:
+			rt = v.next
+		case *timeoutTransport:
+			rt = v.next
+		case *readOnlyTransport:
+			rt = v.next
+		default:
+			t.Fatalf("unexpected http.RoundTripper %T", rt)
+		}
+	}
+}
+
+func TestClientReauthenticate(t *testing.T) {
+	_, _, mux, _, port, teardown := setup()
+	defer teardown()
+	logins := 0
+	mux.HandleFunc("/admin/login", func(w http.ResponseWriter, r *http.Request) {
+		logins++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"token": "token-%d", "expires": %q}`, logins, time.Now().Add(time.Hour).Format(time.RFC3339))
+	})
+	requests := 0
+	mux.HandleFunc("/admin/global-settings", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		// the first token is revoked, only accept the second one.
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"id": "unauthorized", "message": "Token is invalid"}`)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	})
+	c := &Config{
+		URL:          fmt.Sprintf("http://localhost:%d", port),
+		Username:     "admin",
+		Password:     "admin",
+		Version:      22,
+		LoginTimeout: 1,
+	}
+	appgateClient, err := c.Client()
+	if err != nil {
+		t.Fatalf("got err %s expected nil", err)
+	}
+	token, err := appgateClient.GetToken()
+	if err != nil {
+		t.Fatalf("got err %s expected nil", err)
+	}
+	if token != "token-1" {
+		t.Fatalf("expected token-1, got %s", token)
+	}
+	ctx := context.WithValue(context.Background(), openapi.ContextAccessToken, token)
+	if _, _, err := appgateClient.API.GlobalSettingsApi.GlobalSettingsGet(ctx).Execute(); err != nil {
+		t.Fatalf("expected request to be replayed with a new token, got %s", err)
+	}
+	if logins != 2 {
+		t.Errorf("expected 2 logins, got %d", logins)
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+	token, err = appgateClient.GetToken()
+	if err != nil {
+		t.Fatalf("got err %s expected nil", err)
+	}
+	if token != "token-2" {
+		t.Errorf("expected cached token-2, got %s", token)
+	}
+	if logins != 2 {
+		t.Errorf("expected cached token to be reused, got %d logins", logins)
+	}
+}
+
+func TestClientReauthenticateBearerToken(t *testing.T) {
+	_, _, mux, _, port, teardown := setup()
+	defer teardown()
+	mux.HandleFunc("/admin/global-settings", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if !strings.HasSuffix(r.Header.Get("Authorization"), "rotated") {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"id": "unauthorized", "message": "Token is invalid"}`)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	})
+	c := &Config{
+		URL:         fmt.Sprintf("http://localhost:%d", port),
+		BearerToken: "expired",
+		Version:     22,
+		RefreshBearerToken: func() (string, error) {
+			return "rotated", nil
+		},
+	}
+	appgateClient, err := c.Client()
+	if err != nil {
+		t.Fatalf("got err %s expected nil", err)
+	}
+	token, err := appgateClient.GetToken()
+	if err != nil {
+		t.Fatalf("got err %s expected nil", err)
+	}
+	ctx := context.WithValue(context.Background(), openapi.ContextAccessToken, token)
+	if _, _, err := appgateClient.API.GlobalSettingsApi.GlobalSettingsGet(ctx).Execute(); err != nil {
+		t.Fatalf("expected request to be replayed with the refreshed bearer token, got %s", err)
+	}
+	if c.BearerToken != "rotated" {
+		t.Errorf("expected rotated bearer token, got %s", c.BearerToken)
+	}
+}
+
 func TestConfigValidate(t *testing.T) {
 	type fields struct {
 		URL          string
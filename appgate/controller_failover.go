@@ -0,0 +1,172 @@
+package appgate
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	// URLsOrdered tries the controllers in urls in the order they are listed.
+	URLsOrdered = "ordered"
+	// URLsRandom tries the controllers in urls in random order, to spread the load.
+	URLsRandom = "random"
+)
+
+// controllerURLs returns the controllers we can use, urls takes precedence over url.
+func (c *Config) controllerURLs() []string {
+	if len(c.URLs) > 0 {
+		return c.URLs
+	}
+	return []string{c.URL}
+}
+
+// failoverTransport sends the requests to the first controller that works in an HA collective.
+// On connection errors and HTTP 502, 503 and 504 the request is sent to the next controller, and
+// the one that worked is used for all requests that follows. Only requests that are safe to send
+// twice fail over, unless the connection to the controller could not be opened at all.
+type failoverTransport struct {
+	mu          sync.Mutex
+	controllers []*url.URL
+	active      int
+	logged      bool
+	next        http.RoundTripper
+}
+
+func newFailoverTransport(urls []string, order string, next http.RoundTripper) (*failoverTransport, error) {
+	controllers := make([]*url.URL, 0, len(urls))
+	for _, u := range urls {
+		normalized, err := NormalizeConfigurationURL(u)
+		if err != nil {
+			return nil, fmt.Errorf("invalid controller url %q %w", u, err)
+		}
+		controller, err := url.Parse(normalized)
+		if err != nil {
+			return nil, err
+		}
+		controllers = append(controllers, controller)
+	}
+	if order == URLsRandom {
+		rand.Shuffle(len(controllers), func(i, j int) {
+			controllers[i], controllers[j] = controllers[j], controllers[i]
+		})
+	}
+	return &failoverTransport{controllers: controllers, next: next}, nil
+}
+
+// primary is the controller used as server url in the api client configuration.
+func (t *failoverTransport) primary() string {
+	return t.controllers[0].String()
+}
+
+// current is the controller the requests are sent to first.
+func (t *failoverTransport) current() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.controllers[t.active].String()
+}
+
+// controllerURL returns the admin API URL of the controller the client uses, in the same
+// form as the server url of the api client. With urls, it is the controller in use after failover.
+func (c *Client) controllerURL() string {
+	if c.failover != nil {
+		return c.failover.current()
+	}
+	return c.API.GetConfig().Servers[0].URL
+}
+
+func (t *failoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	start := t.active
+	t.mu.Unlock()
+	var (
+		res *http.Response
+		err error
+	)
+	for i := 0; i < len(t.controllers); i++ {
+		index := (start + i) % len(t.controllers)
+		controller := t.controllers[index]
+		if i > 0 {
+			if !canFailover(req, res, err) {
+				break
+			}
+			if res != nil {
+				io.Copy(io.Discard, res.Body)
+				res.Body.Close()
+			}
+			log.Printf("[WARN] Controller %s failed, trying %s %s on %s", t.controllers[(index+len(t.controllers)-1)%len(t.controllers)].Host, req.Method, req.URL.Path, controller.Host)
+		}
+		attempt := req.Clone(req.Context())
+		attempt.URL.Scheme = controller.Scheme
+		attempt.URL.Host = controller.Host
+		attempt.Host = controller.Host
+		if i > 0 && req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return nil, bodyErr
+			}
+			attempt.Body = body
+		}
+		res, err = t.next.RoundTrip(attempt)
+		if err == nil && i > 0 {
+			res = createdByEarlierAttempt(t.next, attempt, res)
+		}
+		if req.Context().Err() != nil {
+			return res, err
+		}
+		if err == nil && !controllerUnavailable(res.StatusCode) {
+			t.use(index)
+			return res, nil
+		}
+	}
+	return res, err
+}
+
+// controllerUnavailable returns true for the status codes of a controller that is down, for example
+// during an upgrade, or behind a load balancer that can't reach it. Other 5xx may come from a request
+// the controller has applied, and are returned as is.
+func controllerUnavailable(status int) bool {
+	switch status {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// canFailover returns true if req can be sent to the next controller after it failed with res or err.
+// If the connection could not be opened the controller never got the request, otherwise the request
+// must be safe to send twice, like the requests the retry transport sends again.
+func canFailover(req *http.Request, res *http.Response, err error) bool {
+	// the request can only be sent again if we can rewind the body.
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	var opErr *net.OpError
+	if err != nil && errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	// a new login only creates another token, so it is safe to send twice.
+	if req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/login") {
+		return true
+	}
+	return retryableRequest(req)
+}
+
+// use makes the controller sticky for the following requests.
+func (t *failoverTransport) use(index int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.active == index && t.logged {
+		return
+	}
+	t.active = index
+	t.logged = true
+	log.Printf("[INFO] Using controller %s", t.controllers[index].Host)
+}
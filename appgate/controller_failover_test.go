@@ -0,0 +1,165 @@
+package appgate
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFailoverTransport(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	downURL := down.URL
+
+	unavailable := 0
+	upgrading := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		unavailable++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer upgrading.Close()
+
+	served := 0
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served++
+		body, _ := io.ReadAll(r.Body)
+		fmt.Fprintf(w, "%s %s %s", r.Method, r.URL.Path, body)
+	}))
+	defer healthy.Close()
+	// close the down controller after the others listen, so none of them can reuse its port.
+	down.Close()
+
+	transport, err := newFailoverTransport([]string{downURL, upgrading.URL, healthy.URL}, URLsOrdered, http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: transport}
+	res, err := client.Post(transport.primary()+"/login", "application/json", strings.NewReader(`{"username": "admin"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if want := `POST /admin/login {"username": "admin"}`; string(body) != want {
+		t.Errorf("got %q, want %q", body, want)
+	}
+	// the healthy controller is sticky, so the failing ones are not tried again.
+	res, err = client.Get(transport.primary() + "/global-settings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if served != 2 || unavailable != 1 {
+		t.Errorf("got %d requests to the healthy controller and %d to the upgrading controller, want 2 and 1", served, unavailable)
+	}
+
+	// if all controllers fail, the last response is returned as is.
+	healthy.Close()
+	res, err = client.Get(transport.primary() + "/global-settings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("got HTTP %d, want %d", res.StatusCode, http.StatusServiceUnavailable)
+	}
+}
+
+func TestFailoverTransportUnsafeRequests(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	downURL := down.URL
+
+	failed := 0
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		failed++
+		if r.URL.Path == "/admin/conditions" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer broken.Close()
+
+	served := 0
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served++
+	}))
+	defer healthy.Close()
+
+	// the peer has the object from the create the broken controller applied.
+	replicated := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served++
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusConflict)
+		}
+	}))
+	defer replicated.Close()
+	// close the down controller after the others listen, so none of them can reuse its port.
+	down.Close()
+
+	tests := []struct {
+		name       string
+		urls       []string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantServed int
+	}{
+		{name: "create without id is sent to the next controller if the connection fails", urls: []string{downURL, healthy.URL}, method: http.MethodPost, path: "/conditions", body: `{"name": "a"}`, wantStatus: http.StatusOK, wantServed: 1},
+		{name: "create without id is not sent again on 503", urls: []string{broken.URL, healthy.URL}, method: http.MethodPost, path: "/conditions", body: `{"name": "a"}`, wantStatus: http.StatusServiceUnavailable},
+		{name: "create with id is sent again on 503", urls: []string{broken.URL, healthy.URL}, method: http.MethodPost, path: "/conditions", body: `{"id": "4c07bc67-57ea-42dd-b702-c2d6c45419fc", "name": "a"}`, wantStatus: http.StatusOK, wantServed: 1},
+		{name: "create with id applied by the first controller", urls: []string{broken.URL, replicated.URL}, method: http.MethodPost, path: "/conditions", body: `{"id": "4c07bc67-57ea-42dd-b702-c2d6c45419fc", "name": "a"}`, wantStatus: http.StatusOK, wantServed: 2},
+		{name: "update is not sent again on 500", urls: []string{broken.URL, healthy.URL}, method: http.MethodPut, path: "/sites/4c07bc67-57ea-42dd-b702-c2d6c45419fc", body: `{"name": "a"}`, wantStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			served, failed = 0, 0
+			transport, err := newFailoverTransport(tt.urls, URLsOrdered, http.DefaultTransport)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req, err := http.NewRequest(tt.method, transport.primary()+tt.path, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := transport.RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+			if res.StatusCode != tt.wantStatus || served != tt.wantServed {
+				t.Errorf("got HTTP %d and %d requests to the healthy controller, want %d and %d", res.StatusCode, served, tt.wantStatus, tt.wantServed)
+			}
+		})
+	}
+}
+
+func TestClientURLs(t *testing.T) {
+	_, _, mux, server, _, teardown := setup()
+	defer teardown()
+	mux.HandleFunc("/admin/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, loginResponse)
+	})
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	c := &Config{
+		URLs:         []string{down.URL, server.URL},
+		Username:     "admin",
+		Password:     "admin",
+		Version:      22,
+		LoginTimeout: 1,
+	}
+	if err := c.Validate(false); err != nil {
+		t.Fatalf("got err %s expected nil", err)
+	}
+	appgateClient, err := c.Client()
+	if err != nil {
+		t.Fatalf("got err %s expected nil", err)
+	}
+	if _, err := appgateClient.GetToken(); err != nil {
+		t.Fatalf("got err %s expected nil", err)
+	}
+}
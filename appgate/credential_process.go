@@ -0,0 +1,71 @@
+package appgate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// credentialProcessTimeout is the maximum time we wait for the credential_process to exit.
+const credentialProcessTimeout = 1 * time.Minute
+
+// processCredentials is the json document the credential_process writes to stdout.
+type processCredentials struct {
+	Username    string     `json:"username,omitempty"`
+	Password    string     `json:"password,omitempty"`
+	BearerToken string     `json:"bearer_token,omitempty"`
+	Expires     *time.Time `json:"expires,omitempty"`
+}
+
+func (p *processCredentials) validate() error {
+	if len(p.BearerToken) > 0 {
+		if len(p.Username) > 0 || len(p.Password) > 0 {
+			return errors.New("expected either bearer_token or username and password, got both")
+		}
+		return nil
+	}
+	if len(p.Username) == 0 || len(p.Password) == 0 {
+		return errors.New("expected bearer_token or username and password")
+	}
+	return nil
+}
+
+// loadCredentialProcess runs the credential_process and updates the config with the credentials from it.
+func (c *Config) loadCredentialProcess() error {
+	creds, err := runCredentialProcess(c.CredentialProcess)
+	if err != nil {
+		return err
+	}
+	c.Username = creds.Username
+	c.Password = creds.Password
+	c.BearerToken = creds.BearerToken
+	c.CredentialsExpires = time.Time{}
+	if creds.Expires != nil {
+		c.CredentialsExpires = *creds.Expires
+	}
+	return nil
+}
+
+// credentialsExpired returns true if the credentials from the credential_process are about to expire.
+func (c *Config) credentialsExpired() bool {
+	if c.CredentialsExpires.IsZero() {
+		return false
+	}
+	return time.Now().Add(tokenExpiryLeeway).After(c.CredentialsExpires)
+}
+
+func runCredentialProcess(command string) (*processCredentials, error) {
+	program, stdout, err := runCommand("credential_process", command, credentialProcessTimeout)
+	if err != nil {
+		return nil, err
+	}
+	creds := &processCredentials{}
+	if err := json.Unmarshal(stdout, creds); err != nil {
+		return nil, fmt.Errorf("credential_process %s invalid json output %w", program, err)
+	}
+	if err := creds.validate(); err != nil {
+		return nil, fmt.Errorf("credential_process %s invalid credentials %w", program, err)
+	}
+	return creds, nil
+}
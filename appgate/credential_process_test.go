@@ -0,0 +1,124 @@
+package appgate
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+// TestCredentialProcessHelper is not a real test, it is used as the credential_process in the tests below.
+func TestCredentialProcessHelper(t *testing.T) {
+	output, ok := os.LookupEnv("APPGATE_TEST_CREDENTIAL_PROCESS_OUTPUT")
+	if !ok {
+		return
+	}
+	if output == "fail" {
+		fmt.Fprint(os.Stderr, "vault is sealed")
+		os.Exit(1)
+	}
+	fmt.Fprint(os.Stdout, output)
+	os.Exit(0)
+}
+
+func testCredentialProcess(t *testing.T, output string) string {
+	t.Setenv("APPGATE_TEST_CREDENTIAL_PROCESS_OUTPUT", output)
+	return fmt.Sprintf("%q -test.run=^TestCredentialProcessHelper$", os.Args[0])
+}
+
+func TestRunCredentialProcess(t *testing.T) {
+	expires := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	tests := []struct {
+		name    string
+		output  string
+		want    processCredentials
+		wantErr bool
+	}{
+		{
+			name:   "username and password",
+			output: fmt.Sprintf(`{"username": "admin", "password": "secret", "expires": %q}`, expires.Format(time.RFC3339)),
+			want:   processCredentials{Username: "admin", Password: "secret", Expires: &expires},
+		},
+		{
+			name:   "bearer token",
+			output: `{"bearer_token": "dG9rZW4="}`,
+			want:   processCredentials{BearerToken: "dG9rZW4="},
+		},
+		{
+			name:    "missing password",
+			output:  `{"username": "admin"}`,
+			wantErr: true,
+		},
+		{
+			name:    "both bearer token and password",
+			output:  `{"username": "admin", "password": "secret", "bearer_token": "dG9rZW4="}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid json",
+			output:  `username=admin`,
+			wantErr: true,
+		},
+		{
+			name:    "process failure",
+			output:  "fail",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runCredentialProcess(testCredentialProcess(t, tt.output))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("runCredentialProcess() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Username != tt.want.Username || got.Password != tt.want.Password || got.BearerToken != tt.want.BearerToken {
+				t.Errorf("runCredentialProcess() got %+v, want %+v", got, tt.want)
+			}
+			if (got.Expires == nil) != (tt.want.Expires == nil) || (got.Expires != nil && !got.Expires.Equal(*tt.want.Expires)) {
+				t.Errorf("runCredentialProcess() got expires %v, want %v", got.Expires, tt.want.Expires)
+			}
+		})
+	}
+}
+
+func TestClientCredentialProcessExpired(t *testing.T) {
+	_, _, mux, _, port, teardown := setup()
+	defer teardown()
+	logins := 0
+	mux.HandleFunc("/admin/login", func(w http.ResponseWriter, r *http.Request) {
+		logins++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, loginResponse)
+	})
+	expired := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	c := &Config{
+		URL:               fmt.Sprintf("http://localhost:%d", port),
+		Version:           22,
+		LoginTimeout:      1,
+		CredentialProcess: testCredentialProcess(t, fmt.Sprintf(`{"username": "admin", "password": "secret", "expires": %q}`, expired)),
+	}
+	if err := c.loadCredentialProcess(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Username != "admin" || !c.credentialsExpired() {
+		t.Fatalf("expected expired credentials for admin, got %+v", c)
+	}
+	appgateClient, err := c.Client()
+	if err != nil {
+		t.Fatalf("got err %s expected nil", err)
+	}
+	if _, err := appgateClient.GetToken(); err != nil {
+		t.Fatalf("got err %s expected nil", err)
+	}
+	// the token is cached, but the expired credentials are renewed, which requires a new login.
+	if _, err := appgateClient.GetToken(); err != nil {
+		t.Fatalf("got err %s expected nil", err)
+	}
+	if logins != 2 {
+		t.Fatalf("expected 2 logins with renewed credentials, got %d", logins)
+	}
+}
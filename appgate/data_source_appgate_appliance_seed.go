@@ -1,6 +1,7 @@
 package appgate
 
 import (
+	"context"
 	b64 "encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -9,12 +10,13 @@ import (
 
 	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func dataSourceAppgateApplianceSeed() *schema.Resource {
 	return &schema.Resource{
-		Read: dataSourceAppgateApplianceSeedRead,
+		ReadContext: dataSourceAppgateApplianceSeedRead,
 		Schema: map[string]*schema.Schema{
 			"appliance_id": {
 				Type:     schema.TypeString,
@@ -54,17 +56,17 @@ func dataSourceAppgateApplianceSeed() *schema.Resource {
 	}
 }
 
-func dataSourceAppgateApplianceSeedRead(d *schema.ResourceData, meta interface{}) error {
+func dataSourceAppgateApplianceSeedRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.AppliancesApi
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	applianceID, iok := d.GetOk("appliance_id")
 
 	if !iok {
-		return fmt.Errorf("please provide one of appliance_id attribute")
+		return diag.FromErr(fmt.Errorf("please provide one of appliance_id attribute"))
 	}
 
 	request := api.AppliancesIdGet(ctx, applianceID.(string))
@@ -74,7 +76,7 @@ func dataSourceAppgateApplianceSeedRead(d *schema.ResourceData, meta interface{}
 		if res != nil && res.StatusCode == http.StatusNotFound {
 			return nil
 		}
-		return fmt.Errorf("Failed to read Appliance, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read Appliance, %w", err))
 	}
 
 	d.SetId(applianceID.(string))
@@ -87,7 +89,6 @@ func dataSourceAppgateApplianceSeedRead(d *schema.ResourceData, meta interface{}
 		return nil
 	}
 
-	exportRequest := api.AppliancesIdExportPost(ctx, appliance.GetId())
 	password, passwordOk := d.GetOk("password")
 	sshKey, sshOk := d.GetOk("ssh_key")
 	cloudKey, cloudOk := d.GetOk("provide_cloud_ssh_key")
@@ -105,17 +106,24 @@ func dataSourceAppgateApplianceSeedRead(d *schema.ResourceData, meta interface{}
 		sshConfig.ProvideCloudSSHKey = openapi.PtrBool(cloudKey.(bool))
 		d.Set("provide_cloud_ssh_key", true)
 	}
-	exportRequest = exportRequest.SSHConfig(*sshConfig)
-	seedmap, _, err := exportRequest.Execute()
+	seed, err := exportApplianceSeed(ctx, api, appliance.GetId(), *sshConfig)
 	if err != nil {
-		return fmt.Errorf("Could not export appliance %w", prettyPrintAPIError(err))
+		return diag.FromErr(err)
+	}
+	d.Set("seed_file", seed)
+
+	return nil
+}
+
+// exportApplianceSeed returns the base64 encoded json seed file of an appliance that is not activated.
+func exportApplianceSeed(ctx context.Context, api *openapi.AppliancesApiService, id string, sshConfig openapi.SSHConfig) (string, error) {
+	seedmap, _, err := api.AppliancesIdExportPost(ctx, id).SSHConfig(sshConfig).Execute()
+	if err != nil {
+		return "", fmt.Errorf("Could not export appliance %w", prettyPrintAPIError(err))
 	}
 	encodedSeed, err := json.Marshal(seedmap)
 	if err != nil {
-		return fmt.Errorf("Could not parse json seed file: %w", err)
+		return "", fmt.Errorf("Could not parse json seed file: %w", err)
 	}
-
-	d.Set("seed_file", b64.StdEncoding.EncodeToString([]byte(encodedSeed)))
-
-	return nil
+	return b64.StdEncoding.EncodeToString([]byte(encodedSeed)), nil
 }
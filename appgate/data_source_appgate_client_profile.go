@@ -3,7 +3,6 @@ package appgate
 import (
 	"context"
 	"errors"
-	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -51,7 +50,7 @@ func dataSourceAppgateClientProfileRead(ctx context.Context, d *schema.ResourceD
 	d.Set("client_profile_name", clientProfile.GetName())
 	d.Set("client_profile_id", clientProfile.GetId())
 
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	url, _, err := api.ClientProfilesIdUrlGet(ctx, clientProfile.GetId()).Execute()
 	if err != nil {
 		diags = AppendFromErr(diags, err)
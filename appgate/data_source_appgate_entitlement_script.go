@@ -2,7 +2,6 @@ package appgate
 
 import (
 	"context"
-	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -32,7 +31,7 @@ func dataSourceAppgateEntitlementScriptRead(ctx context.Context, d *schema.Resou
 		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.EntitlementScriptsApi
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	entitlementScript, diags := ResolveEntitlementScriptFromResourceData(ctx, d, api, token)
 	if diags != nil {
 		return diags
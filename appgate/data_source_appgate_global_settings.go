@@ -7,12 +7,13 @@ import (
 	v22 "github.com/appgate/sdp-api-client-go/api/v22/openapi"
 	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func dataSourceGlobalSettings() *schema.Resource {
 	return &schema.Resource{
-		Read: dataSourceAppgateGlobalSettingsRead,
+		ReadContext: dataSourceAppgateGlobalSettingsRead,
 		Schema: map[string]*schema.Schema{
 			"claims_token_expiration": {
 				Type:        schema.TypeInt,
@@ -79,22 +80,22 @@ func dataSourceGlobalSettings() *schema.Resource {
 	}
 }
 
-func dataSourceAppgateGlobalSettingsRead(d *schema.ResourceData, meta interface{}) error {
+func dataSourceAppgateGlobalSettingsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	api := meta.(*Client).API.GlobalSettingsApi
 	oldApi := meta.(*Client).OldAPI.GlobalSettingsApi
 	var settings *openapi.GlobalSettings
 	if meta.(*Client).Config.Version < 23 {
-		settings, err = getGlobalSettings22(oldApi, token)
+		settings, err = getGlobalSettings22(ctx, oldApi, token)
 	} else {
-		settings, err = getGlobalSettings(api, token)
+		settings, err = getGlobalSettings(ctx, api, token)
 	}
 	if err != nil {
-		return fmt.Errorf("Could not read global settings %w", err)
+		return diag.FromErr(fmt.Errorf("Could not read global settings %w", err))
 	}
 	d.SetId(settings.GetCollectiveId())
 	d.Set("claims_token_expiration", settings.GetClaimsTokenExpiration())
@@ -110,8 +111,8 @@ func dataSourceAppgateGlobalSettingsRead(d *schema.ResourceData, meta interface{
 	return nil
 }
 
-func getGlobalSettings(api *openapi.GlobalSettingsApiService, token string) (*openapi.GlobalSettings, error) {
-	ctx := BaseAuthContext(token)
+func getGlobalSettings(ctx context.Context, api *openapi.GlobalSettingsApiService, token string) (*openapi.GlobalSettings, error) {
+	ctx = authContext(ctx, token)
 	globalSettings, _, err := api.GlobalSettingsGet(ctx).Execute()
 	if err != nil {
 		return nil, err
@@ -119,8 +120,8 @@ func getGlobalSettings(api *openapi.GlobalSettingsApiService, token string) (*op
 	return globalSettings, nil
 }
 
-func getGlobalSettings22(api *v22.GlobalSettingsApiService, token string) (*openapi.GlobalSettings, error) {
-	ctx := context.WithValue(context.Background(), v22.ContextAccessToken, token)
+func getGlobalSettings22(ctx context.Context, api *v22.GlobalSettingsApiService, token string) (*openapi.GlobalSettings, error) {
+	ctx = authContext(ctx, token)
 	globalSettings, _, err := api.GlobalSettingsGet(ctx).Execute()
 	if err != nil {
 		return nil, err
@@ -1,17 +1,19 @@
 package appgate
 
 import (
+	"context"
 	"fmt"
 	"log"
 
 	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func dataSourceAppgateIdentityProvider() *schema.Resource {
 	return &schema.Resource{
-		Read: dataSourceAppgateIdentityProviderRead,
+		ReadContext: dataSourceAppgateIdentityProviderRead,
 		Schema: map[string]*schema.Schema{
 			"identity_provider_id": {
 				Type:          schema.TypeString,
@@ -27,11 +29,11 @@ func dataSourceAppgateIdentityProvider() *schema.Resource {
 	}
 }
 
-func dataSourceAppgateIdentityProviderRead(d *schema.ResourceData, meta interface{}) error {
+func dataSourceAppgateIdentityProviderRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Data source identity provider")
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.IdentityProvidersApi
 
@@ -39,18 +41,18 @@ func dataSourceAppgateIdentityProviderRead(d *schema.ResourceData, meta interfac
 	providerName, nok := d.GetOk("identity_provider_name")
 
 	if !iok && !nok {
-		return fmt.Errorf("please provide one of identity_provider_id or identity_provider_name attributes")
+		return diag.FromErr(fmt.Errorf("please provide one of identity_provider_id or identity_provider_name attributes"))
 	}
 	var reqErr error
 	// var provider *openapi.BaseIdentityProvider
 	var provider map[string]interface{}
 	if iok {
-		provider, reqErr = findIdentityProviderByUUID(api, providerID.(string), token)
+		provider, reqErr = findIdentityProviderByUUID(ctx, api, providerID.(string), token)
 	} else {
-		provider, reqErr = findIdentityProviderByName(api, providerName.(string), token)
+		provider, reqErr = findIdentityProviderByName(ctx, api, providerName.(string), token)
 	}
 	if reqErr != nil {
-		return reqErr
+		return diag.FromErr(reqErr)
 	}
 	log.Printf("[DEBUG] Got identity provider: %+v", provider)
 
@@ -60,8 +62,8 @@ func dataSourceAppgateIdentityProviderRead(d *schema.ResourceData, meta interfac
 	return nil
 }
 
-func findIdentityProviderByUUID(api *openapi.IdentityProvidersApiService, id string, token string) (map[string]interface{}, error) {
-	ctx := BaseAuthContext(token)
+func findIdentityProviderByUUID(ctx context.Context, api *openapi.IdentityProvidersApiService, id string, token string) (map[string]interface{}, error) {
+	ctx = authContext(ctx, token)
 	provider, _, err := api.IdentityProvidersIdGet(ctx, id).Execute()
 	if err != nil {
 		return nil, err
@@ -69,8 +71,8 @@ func findIdentityProviderByUUID(api *openapi.IdentityProvidersApiService, id str
 	return provider, nil
 }
 
-func findIdentityProviderByName(api *openapi.IdentityProvidersApiService, name string, token string) (map[string]interface{}, error) {
-	ctx := BaseAuthContext(token)
+func findIdentityProviderByName(ctx context.Context, api *openapi.IdentityProvidersApiService, name string, token string) (map[string]interface{}, error) {
+	ctx = authContext(ctx, token)
 	request := api.IdentityProvidersGet(ctx)
 	provider, _, err := request.Query(name).OrderBy("name").Range_("0-1").Execute()
 	if err != nil {
@@ -2,7 +2,6 @@ package appgate
 
 import (
 	"context"
-	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
 	"log"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -34,7 +33,7 @@ func dataSourceAppgateLocalUserRead(ctx context.Context, d *schema.ResourceData,
 		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.LocalUsersApi
-	localUser, diags := ResolveLocalUserFromResourceData(context.WithValue(ctx, openapi.ContextAccessToken, token), d, api, token)
+	localUser, diags := ResolveLocalUserFromResourceData(authContext(ctx, token), d, api, token)
 	if diags != nil {
 		return diags
 	}
@@ -6,7 +6,6 @@ import (
 	"log"
 	"net/http"
 
-	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -47,7 +46,7 @@ func dataSourceAppgateReplicationTargetRead(ctx context.Context, d *schema.Resou
 	if diags != nil {
 		return diags
 	}
-	authCtx := context.WithValue(ctx, openapi.ContextAccessToken, token)
+	authCtx := authContext(ctx, token)
 	replToken, response, err := api.ReplicationTargetsIdExportGet(authCtx, replicationTarget.GetId()).Execute()
 	if err != nil && response.StatusCode != http.StatusPreconditionFailed {
 		if response != nil && response.StatusCode == http.StatusNotFound {
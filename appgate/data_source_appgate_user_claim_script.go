@@ -2,7 +2,6 @@ package appgate
 
 import (
 	"context"
-	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -32,7 +31,7 @@ func dataSourceAppgateUserClaimScriptRead(ctx context.Context, d *schema.Resourc
 		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.UserClaimScriptsApi
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	userClaimScript, diags := ResolveUserScriptFromResourceData(ctx, d, api, token)
 	if diags != nil {
 		return diags
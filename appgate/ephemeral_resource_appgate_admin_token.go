@@ -0,0 +1,91 @@
+package appgate
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// adminTokenEphemeralResource is appgatesdp_admin_token, the token is available during plan and apply
+// but never saved in the state or plan.
+type adminTokenEphemeralResource struct {
+	client *Client
+}
+
+var _ ephemeral.EphemeralResourceWithConfigure = &adminTokenEphemeralResource{}
+
+func newAdminTokenEphemeralResource() ephemeral.EphemeralResource {
+	return &adminTokenEphemeralResource{}
+}
+
+type adminTokenModel struct {
+	Token   types.String `tfsdk:"token"`
+	Expires types.String `tfsdk:"expires"`
+	URL     types.String `tfsdk:"url"`
+}
+
+func (r *adminTokenEphemeralResource) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = "appgatesdp_admin_token"
+}
+
+func (r *adminTokenEphemeralResource) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Bearer token of the provider admin session, for example to call the admin API from http or local-exec.",
+		Attributes: map[string]schema.Attribute{
+			"token": schema.StringAttribute{
+				Description: "Bearer token for the Authorization header.",
+				Computed:    true,
+				Sensitive:   true,
+			},
+			"expires": schema.StringAttribute{
+				Description: "When the token expires, in RFC 3339 format. Empty if the provider is configured with bearer_token.",
+				Computed:    true,
+			},
+			"url": schema.StringAttribute{
+				Description: "URL of the controller admin API used by the provider. With urls, it is the controller in use after failover.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (r *adminTokenEphemeralResource) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	c, err := frameworkClient(req.ProviderData)
+	if err != nil {
+		resp.Diagnostics.AddError("Unexpected provider data", err.Error())
+		return
+	}
+	r.client = c
+}
+
+func (r *adminTokenEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	if r.client == nil {
+		resp.Diagnostics.AddError("Provider not configured", "appgatesdp_admin_token requires a configured provider")
+		return
+	}
+	log.Printf("[DEBUG] Opening admin token")
+	token, err := r.client.GetToken()
+	if err != nil {
+		resp.Diagnostics.AddError("Could not login", err.Error())
+		return
+	}
+	// with bearer_token, the client token already includes the Bearer scheme.
+	token = strings.TrimPrefix(token, "Bearer ")
+	r.client.mu.Lock()
+	expires := r.client.TokenExpires
+	r.client.mu.Unlock()
+	result := adminTokenModel{
+		Token:   types.StringValue(token),
+		Expires: types.StringValue(""),
+		URL:     types.StringValue(r.client.controllerURL()),
+	}
+	if !expires.IsZero() {
+		result.Expires = types.StringValue(expires.UTC().Format(time.RFC3339))
+	}
+	resp.Diagnostics.Append(resp.Result.Set(ctx, result)...)
+}
@@ -0,0 +1,151 @@
+package appgate
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/ephemeralvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// applianceSeedEphemeralResource is appgatesdp_appliance_seed, the seed file is available during
+// plan and apply but never saved in the state or plan, unlike the appgatesdp_appliance_seed data source.
+type applianceSeedEphemeralResource struct {
+	client *Client
+}
+
+var (
+	_ ephemeral.EphemeralResourceWithConfigure        = &applianceSeedEphemeralResource{}
+	_ ephemeral.EphemeralResourceWithConfigValidators = &applianceSeedEphemeralResource{}
+)
+
+func newApplianceSeedEphemeralResource() ephemeral.EphemeralResource {
+	return &applianceSeedEphemeralResource{}
+}
+
+type applianceSeedModel struct {
+	ApplianceID        types.String `tfsdk:"appliance_id"`
+	ProvideCloudSSHKey types.Bool   `tfsdk:"provide_cloud_ssh_key"`
+	SSHKey             types.String `tfsdk:"ssh_key"`
+	Password           types.String `tfsdk:"password"`
+	Activated          types.Bool   `tfsdk:"activated"`
+	SeedFile           types.String `tfsdk:"seed_file"`
+}
+
+func (r *applianceSeedEphemeralResource) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = "appgatesdp_appliance_seed"
+}
+
+func (r *applianceSeedEphemeralResource) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Seed file of an appliance that is not activated, the seed file is never saved in the state.",
+		Attributes: map[string]schema.Attribute{
+			"appliance_id": schema.StringAttribute{
+				Required: true,
+			},
+			"provide_cloud_ssh_key": schema.BoolAttribute{
+				Description: "Use the SSH key provided by the cloud provider. Conflicts with ssh_key and password.",
+				Optional:    true,
+			},
+			"ssh_key": schema.StringAttribute{
+				Description: "SSH public key for the cz user. Conflicts with provide_cloud_ssh_key and password.",
+				Optional:    true,
+			},
+			"password": schema.StringAttribute{
+				Description: "Password for the cz user. Conflicts with provide_cloud_ssh_key and ssh_key.",
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"activated": schema.BoolAttribute{
+				Computed: true,
+			},
+			"seed_file": schema.StringAttribute{
+				Description: "Seed file (json) generated from appliance, base64 encoded. Empty if the appliance is already activated.",
+				Computed:    true,
+				Sensitive:   true,
+			},
+		},
+	}
+}
+
+func (r *applianceSeedEphemeralResource) ConfigValidators(ctx context.Context) []ephemeral.ConfigValidator {
+	return []ephemeral.ConfigValidator{
+		ephemeralvalidator.Conflicting(
+			path.MatchRoot("provide_cloud_ssh_key"),
+			path.MatchRoot("ssh_key"),
+			path.MatchRoot("password"),
+		),
+	}
+}
+
+func (r *applianceSeedEphemeralResource) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	c, err := frameworkClient(req.ProviderData)
+	if err != nil {
+		resp.Diagnostics.AddError("Unexpected provider data", err.Error())
+		return
+	}
+	r.client = c
+}
+
+func (r *applianceSeedEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	if r.client == nil {
+		resp.Diagnostics.AddError("Provider not configured", "appgatesdp_appliance_seed requires a configured provider")
+		return
+	}
+	var config applianceSeedModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	applianceID := config.ApplianceID.ValueString()
+	log.Printf("[DEBUG] Opening appliance seed for appliance id: %s", applianceID)
+	token, err := r.client.GetToken()
+	if err != nil {
+		resp.Diagnostics.AddError("Could not login", err.Error())
+		return
+	}
+	api := r.client.API.AppliancesApi
+	ctx = authContext(ctx, token)
+	appliance, res, err := api.AppliancesIdGet(ctx, applianceID).Execute()
+	if err != nil {
+		if res != nil && res.StatusCode == http.StatusNotFound {
+			resp.Diagnostics.AddAttributeError(path.Root("appliance_id"), "Appliance not found", fmt.Sprintf("Appliance %q does not exist", applianceID))
+			return
+		}
+		resp.Diagnostics.AddError("Failed to read Appliance", prettyPrintAPIError(err).Error())
+		return
+	}
+	result := config
+	result.Activated = types.BoolValue(appliance.GetActivated())
+	result.SeedFile = types.StringValue("")
+	if appliance.GetActivated() {
+		log.Printf("[DEBUG] Appliance is already seeded")
+		resp.Diagnostics.Append(resp.Result.Set(ctx, result)...)
+		return
+	}
+
+	sshConfig := openapi.NewSSHConfig()
+	if isKnown(config.Password) {
+		sshConfig.SetPassword(config.Password.ValueString())
+	}
+	if isKnown(config.SSHKey) {
+		sshConfig.SetSshKey(config.SSHKey.ValueString())
+	}
+	if isKnown(config.ProvideCloudSSHKey) {
+		sshConfig.SetProvideCloudSSHKey(config.ProvideCloudSSHKey.ValueBool())
+	}
+	seed, err := exportApplianceSeed(ctx, api, appliance.GetId(), *sshConfig)
+	if err != nil {
+		resp.Diagnostics.AddError("Could not export the appliance seed", err.Error())
+		return
+	}
+	result.SeedFile = types.StringValue(seed)
+	resp.Diagnostics.Append(resp.Result.Set(ctx, result)...)
+}
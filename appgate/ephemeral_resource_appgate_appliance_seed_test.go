@@ -0,0 +1,98 @@
+package appgate
+
+import (
+	"context"
+	b64 "encoding/base64"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestApplianceSeedEphemeralValidate(t *testing.T) {
+	s, schemas := testFrameworkServer(t, nil)
+	ty := schemas.EphemeralResourceSchemas["appgatesdp_appliance_seed"].ValueType()
+	validate := func(values map[string]tftypes.Value) []*tfprotov5.Diagnostic {
+		values["appliance_id"] = tftypes.NewValue(tftypes.String, "4c07bc67-57ea-42dd-b702-c2d6c45419fc")
+		resp, err := s.ValidateEphemeralResourceConfig(context.Background(), &tfprotov5.ValidateEphemeralResourceConfigRequest{
+			TypeName: "appgatesdp_appliance_seed",
+			Config:   testDynamicValue(t, testObjectValue(ty, values)),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.Diagnostics
+	}
+	if diags := validate(map[string]tftypes.Value{
+		"password": tftypes.NewValue(tftypes.String, "cz"),
+	}); len(diags) > 0 {
+		t.Fatalf("got %v", diags[0])
+	}
+	diags := validate(map[string]tftypes.Value{
+		"password": tftypes.NewValue(tftypes.String, "cz"),
+		"ssh_key":  tftypes.NewValue(tftypes.String, "ssh-ed25519 AAAA"),
+	})
+	if !hasError(diags) {
+		t.Fatal("expected an error for ssh_key with password")
+	}
+}
+
+func TestApplianceSeedEphemeralOpen(t *testing.T) {
+	const id = "4c07bc67-57ea-42dd-b702-c2d6c45419fc"
+	_, _, mux, _, port, teardown := setup()
+	defer teardown()
+	mux.HandleFunc("/admin/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, loginResponse)
+	})
+	mux.HandleFunc("/admin/appliances/"+id, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id": %q, "name": "gateway", "hostname": "gateway.devops", "activated": false}`, id)
+	})
+	mux.HandleFunc("/admin/appliances/"+id+"/export", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("got %s", r.Method)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"hostname": "gateway.devops"}`)
+	})
+	c := &Config{
+		URL:          fmt.Sprintf("http://localhost:%d", port),
+		Username:     "admin",
+		Password:     "admin",
+		Version:      22,
+		LoginTimeout: 1 * time.Minute,
+	}
+	client, err := c.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, schemas := testFrameworkServer(t, client)
+	result, diags := testOpenEphemeralResource(t, s, schemas, "appgatesdp_appliance_seed", map[string]tftypes.Value{
+		"appliance_id": tftypes.NewValue(tftypes.String, id),
+		"password":     tftypes.NewValue(tftypes.String, "cz"),
+	})
+	if hasError(diags) {
+		t.Fatalf("got %v", diags[0])
+	}
+	decoded, err := b64.StdEncoding.DecodeString(testStringValue(t, result["seed_file"]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(decoded) != `{"hostname":"gateway.devops"}` {
+		t.Fatalf("got seed %s", decoded)
+	}
+	if password := testStringValue(t, result["password"]); password != "cz" {
+		t.Fatalf("got password %q", password)
+	}
+
+	_, diags = testOpenEphemeralResource(t, s, schemas, "appgatesdp_appliance_seed", map[string]tftypes.Value{
+		"appliance_id": tftypes.NewValue(tftypes.String, "a5c7e0a6-8b4e-4e0d-9c5d-0b6a0d2c9f11"),
+	})
+	if !hasError(diags) || diags[0].Summary != "Appliance not found" {
+		t.Fatalf("got %+v, expected Appliance not found", diags)
+	}
+}
@@ -12,7 +12,7 @@ import (
 
 func findEntitlementByUUID(ctx context.Context, api *openapi.EntitlementsApiService, id, token string) (*openapi.Entitlement, diag.Diagnostics) {
 	log.Printf("[DEBUG] Data source Entitlement get by UUID %s", id)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.EntitlementsIdGet(ctx, id).Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -23,7 +23,7 @@ func findEntitlementByUUID(ctx context.Context, api *openapi.EntitlementsApiServ
 func findEntitlementByName(ctx context.Context, api *openapi.EntitlementsApiService, name, token string) (*openapi.Entitlement, diag.Diagnostics) {
 	var diags diag.Diagnostics
 	log.Printf("[DEBUG] Data source Entitlement get by name %s", name)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.EntitlementsGet(ctx).Query(name).OrderBy("name").Range_("0-10").Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -54,9 +54,17 @@ func ResolveEntitlementFromResourceData(ctx context.Context, d *schema.ResourceD
 	return findEntitlementByName(ctx, api, resourceName.(string), token)
 }
 
+func listEntitlementImportCandidates(ctx context.Context, c *Client, token, query string) ([]importCandidate, error) {
+	list, _, err := c.API.EntitlementsApi.EntitlementsGet(authContext(ctx, token)).Query(query).OrderBy("name").Execute()
+	if err != nil {
+		return nil, err
+	}
+	return importCandidates(list.GetData()), nil
+}
+
 func findAdministrativeRoleByUUID(ctx context.Context, api *openapi.AdminRolesApiService, id, token string) (*openapi.AdministrativeRole, diag.Diagnostics) {
 	log.Printf("[DEBUG] Data source AdministrativeRole get by UUID %s", id)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.AdministrativeRolesIdGet(ctx, id).Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -67,7 +75,7 @@ func findAdministrativeRoleByUUID(ctx context.Context, api *openapi.AdminRolesAp
 func findAdministrativeRoleByName(ctx context.Context, api *openapi.AdminRolesApiService, name, token string) (*openapi.AdministrativeRole, diag.Diagnostics) {
 	var diags diag.Diagnostics
 	log.Printf("[DEBUG] Data source AdministrativeRole get by name %s", name)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.AdministrativeRolesGet(ctx).Query(name).OrderBy("name").Range_("0-10").Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -100,7 +108,7 @@ func ResolveAdministrativeRoleFromResourceData(ctx context.Context, d *schema.Re
 
 func findApplianceCustomizationByUUID(ctx context.Context, api *openapi.ApplianceCustomizationsApiService, id, token string) (*openapi.ApplianceCustomization, diag.Diagnostics) {
 	log.Printf("[DEBUG] Data source ApplianceCustomization get by UUID %s", id)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.ApplianceCustomizationsIdGet(ctx, id).Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -111,7 +119,7 @@ func findApplianceCustomizationByUUID(ctx context.Context, api *openapi.Applianc
 func findApplianceCustomizationByName(ctx context.Context, api *openapi.ApplianceCustomizationsApiService, name, token string) (*openapi.ApplianceCustomization, diag.Diagnostics) {
 	var diags diag.Diagnostics
 	log.Printf("[DEBUG] Data source ApplianceCustomization get by name %s", name)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.ApplianceCustomizationsGet(ctx).Query(name).OrderBy("name").Range_("0-10").Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -144,7 +152,7 @@ func ResolveApplianceCustomizationFromResourceData(ctx context.Context, d *schem
 
 func findApplianceByUUID(ctx context.Context, api *openapi.AppliancesApiService, id, token string) (*openapi.Appliance, diag.Diagnostics) {
 	log.Printf("[DEBUG] Data source Appliance get by UUID %s", id)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.AppliancesIdGet(ctx, id).Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -155,7 +163,7 @@ func findApplianceByUUID(ctx context.Context, api *openapi.AppliancesApiService,
 func findApplianceByName(ctx context.Context, api *openapi.AppliancesApiService, name, token string) (*openapi.Appliance, diag.Diagnostics) {
 	var diags diag.Diagnostics
 	log.Printf("[DEBUG] Data source Appliance get by name %s", name)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.AppliancesGet(ctx).Query(name).OrderBy("name").Range_("0-10").Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -186,9 +194,17 @@ func ResolveApplianceFromResourceData(ctx context.Context, d *schema.ResourceDat
 	return findApplianceByName(ctx, api, resourceName.(string), token)
 }
 
+func listApplianceImportCandidates(ctx context.Context, c *Client, token, query string) ([]importCandidate, error) {
+	list, _, err := c.API.AppliancesApi.AppliancesGet(authContext(ctx, token)).Query(query).OrderBy("name").Execute()
+	if err != nil {
+		return nil, err
+	}
+	return importCandidates(list.GetData()), nil
+}
+
 func findConditionByUUID(ctx context.Context, api *openapi.ConditionsApiService, id, token string) (*openapi.Condition, diag.Diagnostics) {
 	log.Printf("[DEBUG] Data source Condition get by UUID %s", id)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.ConditionsIdGet(ctx, id).Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -199,7 +215,7 @@ func findConditionByUUID(ctx context.Context, api *openapi.ConditionsApiService,
 func findConditionByName(ctx context.Context, api *openapi.ConditionsApiService, name, token string) (*openapi.Condition, diag.Diagnostics) {
 	var diags diag.Diagnostics
 	log.Printf("[DEBUG] Data source Condition get by name %s", name)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.ConditionsGet(ctx).Query(name).OrderBy("name").Range_("0-10").Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -230,9 +246,17 @@ func ResolveConditionFromResourceData(ctx context.Context, d *schema.ResourceDat
 	return findConditionByName(ctx, api, resourceName.(string), token)
 }
 
+func listConditionImportCandidates(ctx context.Context, c *Client, token, query string) ([]importCandidate, error) {
+	list, _, err := c.API.ConditionsApi.ConditionsGet(authContext(ctx, token)).Query(query).OrderBy("name").Execute()
+	if err != nil {
+		return nil, err
+	}
+	return importCandidates(list.GetData()), nil
+}
+
 func findCriteriaScriptByUUID(ctx context.Context, api *openapi.CriteriaScriptsApiService, id, token string) (*openapi.CriteriaScript, diag.Diagnostics) {
 	log.Printf("[DEBUG] Data source CriteriaScript get by UUID %s", id)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.CriteriaScriptsIdGet(ctx, id).Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -243,7 +267,7 @@ func findCriteriaScriptByUUID(ctx context.Context, api *openapi.CriteriaScriptsA
 func findCriteriaScriptByName(ctx context.Context, api *openapi.CriteriaScriptsApiService, name, token string) (*openapi.CriteriaScript, diag.Diagnostics) {
 	var diags diag.Diagnostics
 	log.Printf("[DEBUG] Data source CriteriaScript get by name %s", name)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.CriteriaScriptsGet(ctx).Query(name).OrderBy("name").Range_("0-10").Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -274,9 +298,17 @@ func ResolveCriteriaScriptFromResourceData(ctx context.Context, d *schema.Resour
 	return findCriteriaScriptByName(ctx, api, resourceName.(string), token)
 }
 
+func listCriteriaScriptImportCandidates(ctx context.Context, c *Client, token, query string) ([]importCandidate, error) {
+	list, _, err := c.API.CriteriaScriptsApi.CriteriaScriptsGet(authContext(ctx, token)).Query(query).OrderBy("name").Execute()
+	if err != nil {
+		return nil, err
+	}
+	return importCandidates(list.GetData()), nil
+}
+
 func findDeviceScriptByUUID(ctx context.Context, api *openapi.DeviceClaimScriptsApiService, id, token string) (*openapi.DeviceScript, diag.Diagnostics) {
 	log.Printf("[DEBUG] Data source DeviceScript get by UUID %s", id)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.DeviceScriptsIdGet(ctx, id).Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -287,7 +319,7 @@ func findDeviceScriptByUUID(ctx context.Context, api *openapi.DeviceClaimScripts
 func findDeviceScriptByName(ctx context.Context, api *openapi.DeviceClaimScriptsApiService, name, token string) (*openapi.DeviceScript, diag.Diagnostics) {
 	var diags diag.Diagnostics
 	log.Printf("[DEBUG] Data source DeviceScript get by name %s", name)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.DeviceScriptsGet(ctx).Query(name).OrderBy("name").Range_("0-10").Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -318,9 +350,17 @@ func ResolveDeviceScriptFromResourceData(ctx context.Context, d *schema.Resource
 	return findDeviceScriptByName(ctx, api, resourceName.(string), token)
 }
 
+func listDeviceScriptImportCandidates(ctx context.Context, c *Client, token, query string) ([]importCandidate, error) {
+	list, _, err := c.API.DeviceClaimScriptsApi.DeviceScriptsGet(authContext(ctx, token)).Query(query).OrderBy("name").Execute()
+	if err != nil {
+		return nil, err
+	}
+	return importCandidates(list.GetData()), nil
+}
+
 func findEntitlementScriptByUUID(ctx context.Context, api *openapi.EntitlementScriptsApiService, id, token string) (*openapi.EntitlementScript, diag.Diagnostics) {
 	log.Printf("[DEBUG] Data source EntitlementScript get by UUID %s", id)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.EntitlementScriptsIdGet(ctx, id).Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -331,7 +371,7 @@ func findEntitlementScriptByUUID(ctx context.Context, api *openapi.EntitlementSc
 func findEntitlementScriptByName(ctx context.Context, api *openapi.EntitlementScriptsApiService, name, token string) (*openapi.EntitlementScript, diag.Diagnostics) {
 	var diags diag.Diagnostics
 	log.Printf("[DEBUG] Data source EntitlementScript get by name %s", name)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.EntitlementScriptsGet(ctx).Query(name).OrderBy("name").Range_("0-10").Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -362,9 +402,17 @@ func ResolveEntitlementScriptFromResourceData(ctx context.Context, d *schema.Res
 	return findEntitlementScriptByName(ctx, api, resourceName.(string), token)
 }
 
+func listEntitlementScriptImportCandidates(ctx context.Context, c *Client, token, query string) ([]importCandidate, error) {
+	list, _, err := c.API.EntitlementScriptsApi.EntitlementScriptsGet(authContext(ctx, token)).Query(query).OrderBy("name").Execute()
+	if err != nil {
+		return nil, err
+	}
+	return importCandidates(list.GetData()), nil
+}
+
 func findIpPoolByUUID(ctx context.Context, api *openapi.IPPoolsApiService, id, token string) (*openapi.IpPool, diag.Diagnostics) {
 	log.Printf("[DEBUG] Data source IpPool get by UUID %s", id)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.IpPoolsIdGet(ctx, id).Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -375,7 +423,7 @@ func findIpPoolByUUID(ctx context.Context, api *openapi.IPPoolsApiService, id, t
 func findIpPoolByName(ctx context.Context, api *openapi.IPPoolsApiService, name, token string) (*openapi.IpPool, diag.Diagnostics) {
 	var diags diag.Diagnostics
 	log.Printf("[DEBUG] Data source IpPool get by name %s", name)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.IpPoolsGet(ctx).Query(name).OrderBy("name").Range_("0-10").Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -406,9 +454,17 @@ func ResolveIpPoolFromResourceData(ctx context.Context, d *schema.ResourceData,
 	return findIpPoolByName(ctx, api, resourceName.(string), token)
 }
 
+func listIpPoolImportCandidates(ctx context.Context, c *Client, token, query string) ([]importCandidate, error) {
+	list, _, err := c.API.IPPoolsApi.IpPoolsGet(authContext(ctx, token)).Query(query).OrderBy("name").Execute()
+	if err != nil {
+		return nil, err
+	}
+	return importCandidates(list.GetData()), nil
+}
+
 func findLocalUserByUUID(ctx context.Context, api *openapi.LocalUsersApiService, id, token string) (*openapi.LocalUser, diag.Diagnostics) {
 	log.Printf("[DEBUG] Data source LocalUser get by UUID %s", id)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.LocalUsersIdGet(ctx, id).Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -419,7 +475,7 @@ func findLocalUserByUUID(ctx context.Context, api *openapi.LocalUsersApiService,
 func findLocalUserByName(ctx context.Context, api *openapi.LocalUsersApiService, name, token string) (*openapi.LocalUser, diag.Diagnostics) {
 	var diags diag.Diagnostics
 	log.Printf("[DEBUG] Data source LocalUser get by name %s", name)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.LocalUsersGet(ctx).Query(name).OrderBy("name").Range_("0-10").Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -452,7 +508,7 @@ func ResolveLocalUserFromResourceData(ctx context.Context, d *schema.ResourceDat
 
 func findPolicyByUUID(ctx context.Context, api *openapi.PoliciesApiService, id, token string) (*openapi.Policy, diag.Diagnostics) {
 	log.Printf("[DEBUG] Data source Policy get by UUID %s", id)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.PoliciesIdGet(ctx, id).Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -463,7 +519,7 @@ func findPolicyByUUID(ctx context.Context, api *openapi.PoliciesApiService, id,
 func findPolicyByName(ctx context.Context, api *openapi.PoliciesApiService, name, token string) (*openapi.Policy, diag.Diagnostics) {
 	var diags diag.Diagnostics
 	log.Printf("[DEBUG] Data source Policy get by name %s", name)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.PoliciesGet(ctx).Query(name).OrderBy("name").Range_("0-10").Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -496,7 +552,7 @@ func ResolvePolicyFromResourceData(ctx context.Context, d *schema.ResourceData,
 
 func findRingfenceRuleByUUID(ctx context.Context, api *openapi.RingfenceRulesApiService, id, token string) (*openapi.RingfenceRule, diag.Diagnostics) {
 	log.Printf("[DEBUG] Data source RingfenceRule get by UUID %s", id)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.RingfenceRulesIdGet(ctx, id).Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -507,7 +563,7 @@ func findRingfenceRuleByUUID(ctx context.Context, api *openapi.RingfenceRulesApi
 func findRingfenceRuleByName(ctx context.Context, api *openapi.RingfenceRulesApiService, name, token string) (*openapi.RingfenceRule, diag.Diagnostics) {
 	var diags diag.Diagnostics
 	log.Printf("[DEBUG] Data source RingfenceRule get by name %s", name)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.RingfenceRulesGet(ctx).Query(name).OrderBy("name").Range_("0-10").Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -540,7 +596,7 @@ func ResolveRingfenceRuleFromResourceData(ctx context.Context, d *schema.Resourc
 
 func findSiteByUUID(ctx context.Context, api *openapi.SitesApiService, id, token string) (*openapi.Site, diag.Diagnostics) {
 	log.Printf("[DEBUG] Data source Site get by UUID %s", id)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.SitesIdGet(ctx, id).Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -551,7 +607,7 @@ func findSiteByUUID(ctx context.Context, api *openapi.SitesApiService, id, token
 func findSiteByName(ctx context.Context, api *openapi.SitesApiService, name, token string) (*openapi.Site, diag.Diagnostics) {
 	var diags diag.Diagnostics
 	log.Printf("[DEBUG] Data source Site get by name %s", name)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.SitesGet(ctx).Query(name).OrderBy("name").Range_("0-10").Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -582,9 +638,17 @@ func ResolveSiteFromResourceData(ctx context.Context, d *schema.ResourceData, ap
 	return findSiteByName(ctx, api, resourceName.(string), token)
 }
 
+func listSiteImportCandidates(ctx context.Context, c *Client, token, query string) ([]importCandidate, error) {
+	list, _, err := c.API.SitesApi.SitesGet(authContext(ctx, token)).Query(query).OrderBy("name").Execute()
+	if err != nil {
+		return nil, err
+	}
+	return importCandidates(list.GetData()), nil
+}
+
 func findTrustedCertificateByUUID(ctx context.Context, api *openapi.TrustedCertificatesApiService, id, token string) (*openapi.TrustedCertificate, diag.Diagnostics) {
 	log.Printf("[DEBUG] Data source TrustedCertificate get by UUID %s", id)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.TrustedCertificatesIdGet(ctx, id).Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -595,7 +659,7 @@ func findTrustedCertificateByUUID(ctx context.Context, api *openapi.TrustedCerti
 func findTrustedCertificateByName(ctx context.Context, api *openapi.TrustedCertificatesApiService, name, token string) (*openapi.TrustedCertificate, diag.Diagnostics) {
 	var diags diag.Diagnostics
 	log.Printf("[DEBUG] Data source TrustedCertificate get by name %s", name)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.TrustedCertificatesGet(ctx).Query(name).OrderBy("name").Range_("0-10").Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -628,7 +692,7 @@ func ResolveTrustedCertificateFromResourceData(ctx context.Context, d *schema.Re
 
 func findUserScriptByUUID(ctx context.Context, api *openapi.UserClaimScriptsApiService, id, token string) (*openapi.UserScript, diag.Diagnostics) {
 	log.Printf("[DEBUG] Data source UserScript get by UUID %s", id)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.UserScriptsIdGet(ctx, id).Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -639,7 +703,7 @@ func findUserScriptByUUID(ctx context.Context, api *openapi.UserClaimScriptsApiS
 func findUserScriptByName(ctx context.Context, api *openapi.UserClaimScriptsApiService, name, token string) (*openapi.UserScript, diag.Diagnostics) {
 	var diags diag.Diagnostics
 	log.Printf("[DEBUG] Data source UserScript get by name %s", name)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.UserScriptsGet(ctx).Query(name).OrderBy("name").Range_("0-10").Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -670,9 +734,17 @@ func ResolveUserScriptFromResourceData(ctx context.Context, d *schema.ResourceDa
 	return findUserScriptByName(ctx, api, resourceName.(string), token)
 }
 
+func listUserScriptImportCandidates(ctx context.Context, c *Client, token, query string) ([]importCandidate, error) {
+	list, _, err := c.API.UserClaimScriptsApi.UserScriptsGet(authContext(ctx, token)).Query(query).OrderBy("name").Execute()
+	if err != nil {
+		return nil, err
+	}
+	return importCandidates(list.GetData()), nil
+}
+
 func findMfaProviderByUUID(ctx context.Context, api *openapi.MFAProvidersApiService, id, token string) (*openapi.MfaProvider, diag.Diagnostics) {
 	log.Printf("[DEBUG] Data source MfaProvider get by UUID %s", id)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.MfaProvidersIdGet(ctx, id).Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -683,7 +755,7 @@ func findMfaProviderByUUID(ctx context.Context, api *openapi.MFAProvidersApiServ
 func findMfaProviderByName(ctx context.Context, api *openapi.MFAProvidersApiService, name, token string) (*openapi.MfaProvider, diag.Diagnostics) {
 	var diags diag.Diagnostics
 	log.Printf("[DEBUG] Data source MfaProvider get by name %s", name)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.MfaProvidersGet(ctx).Query(name).OrderBy("name").Range_("0-10").Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -716,7 +788,7 @@ func ResolveMfaProviderFromResourceData(ctx context.Context, d *schema.ResourceD
 
 func findClientProfileByUUID(ctx context.Context, api *openapi.ClientProfilesApiService, id, token string) (*openapi.ClientProfile, diag.Diagnostics) {
 	log.Printf("[DEBUG] Data source ClientProfile get by UUID %s", id)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.ClientProfilesIdGet(ctx, id).Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -776,7 +848,7 @@ func findClientProfileByUUID(ctx context.Context, api *openapi.ClientProfilesApi
 func findClientProfileByName(ctx context.Context, api *openapi.ClientProfilesApiService, name, token string) (*openapi.ClientProfile, diag.Diagnostics) {
 	var diags diag.Diagnostics
 	log.Printf("[DEBUG] Data source ClientProfile get by name %s", name)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.ClientProfilesGet(ctx).Query(name).OrderBy("name").Range_("0-10").Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -812,7 +884,7 @@ func ResolveClientProfileFromResourceData(ctx context.Context, d *schema.Resourc
 
 func findReplicationTargetByUUID(ctx context.Context, api *openapi.ReplicationTargetsApiService, id, token string) (*openapi.ReplicationTarget, diag.Diagnostics) {
 	log.Printf("[DEBUG] Data source ReplicationTarget get by UUID %s", id)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.ReplicationTargetsIdGet(ctx, id).Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -823,7 +895,7 @@ func findReplicationTargetByUUID(ctx context.Context, api *openapi.ReplicationTa
 func findReplicationTargetByName(ctx context.Context, api *openapi.ReplicationTargetsApiService, name, token string) (*openapi.ReplicationTarget, diag.Diagnostics) {
 	var diags diag.Diagnostics
 	log.Printf("[DEBUG] Data source ReplicationTarget get by name %s", name)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.ReplicationTargetsGet(ctx).Query(name).OrderBy("name").Range_("0-10").Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
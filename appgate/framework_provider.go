@@ -0,0 +1,249 @@
+package appgate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	providerschema "github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// frameworkProvider serves the resources that have been moved from the SDK provider to terraform-plugin-framework,
+// and the ephemeral resources and functions the SDK doesn't support. It is muxed with the SDK provider in
+// ProtocolServer, and uses the *Client configured by the SDK provider, so both providers share the same
+// login and configuration.
+type frameworkProvider struct {
+	sdk *schema.Provider
+}
+
+var (
+	_ provider.ProviderWithEphemeralResources = &frameworkProvider{}
+	_ provider.ProviderWithFunctions          = &frameworkProvider{}
+)
+
+func newFrameworkProvider(sdk *schema.Provider) func() provider.Provider {
+	return func() provider.Provider {
+		return &frameworkProvider{sdk: sdk}
+	}
+}
+
+func (p *frameworkProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "appgatesdp"
+}
+
+// Schema returns the schema of the SDK provider, the provider schema must be the same in all muxed providers.
+// The configuration is validated and read by the SDK provider.
+func (p *frameworkProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
+	attributes := make(map[string]providerschema.Attribute, len(p.sdk.Schema))
+	for name, s := range p.sdk.Schema {
+		a, err := frameworkProviderAttribute(s)
+		if err != nil {
+			resp.Diagnostics.AddError("Invalid provider schema", fmt.Sprintf("%s: %s", name, err))
+			continue
+		}
+		attributes[name] = a
+	}
+	resp.Schema = providerschema.Schema{Attributes: attributes}
+}
+
+// frameworkProviderAttribute converts a provider argument from the SDK schema, the provider only has primitive
+// arguments and lists and sets of strings.
+func frameworkProviderAttribute(s *schema.Schema) (providerschema.Attribute, error) {
+	switch s.Type {
+	case schema.TypeString:
+		return providerschema.StringAttribute{Optional: s.Optional, Required: s.Required, Sensitive: s.Sensitive, Description: s.Description}, nil
+	case schema.TypeBool:
+		return providerschema.BoolAttribute{Optional: s.Optional, Required: s.Required, Sensitive: s.Sensitive, Description: s.Description}, nil
+	case schema.TypeInt:
+		return providerschema.Int64Attribute{Optional: s.Optional, Required: s.Required, Sensitive: s.Sensitive, Description: s.Description}, nil
+	case schema.TypeFloat:
+		return providerschema.Float64Attribute{Optional: s.Optional, Required: s.Required, Sensitive: s.Sensitive, Description: s.Description}, nil
+	}
+	elem, ok := s.Elem.(*schema.Schema)
+	if !ok || elem.Type != schema.TypeString {
+		return nil, fmt.Errorf("unsupported element type %T", s.Elem)
+	}
+	switch s.Type {
+	case schema.TypeList:
+		return providerschema.ListAttribute{ElementType: types.StringType, Optional: s.Optional, Required: s.Required, Sensitive: s.Sensitive, Description: s.Description}, nil
+	case schema.TypeSet:
+		return providerschema.SetAttribute{ElementType: types.StringType, Optional: s.Optional, Required: s.Required, Sensitive: s.Sensitive, Description: s.Description}, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", s.Type)
+}
+
+// Configure passes the *Client to the resources. ConfigureProvider is sent to the muxed providers in order,
+// the SDK provider is first so it has already been configured.
+func (p *frameworkProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	c, ok := p.sdk.Meta().(*Client)
+	if !ok || c == nil {
+		return
+	}
+	resp.ResourceData = c
+	resp.DataSourceData = c
+	resp.EphemeralResourceData = c
+}
+
+func (p *frameworkProvider) Resources(ctx context.Context) []func() resource.Resource {
+	return []func() resource.Resource{
+		newPolicyResource,
+		newAccessPolicyResource,
+		newAdminPolicyResource,
+		newDevicePolicyResource,
+		newDNSPolicyResource,
+		newStopPolicyResource,
+	}
+}
+
+func (p *frameworkProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
+	return nil
+}
+
+func (p *frameworkProvider) EphemeralResources(ctx context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		newAdminTokenEphemeralResource,
+		newApplianceSeedEphemeralResource,
+	}
+}
+
+func (p *frameworkProvider) Functions(ctx context.Context) []func() function.Function {
+	return []func() function.Function{
+		newClaimExpressionFunction,
+		newHostFunction,
+		newPortRangeFunction,
+	}
+}
+
+// frameworkClient returns the *Client from the provider data passed to Configure of a resource,
+// it is nil before the provider is configured, for example during validation.
+func frameworkClient(providerData any) (*Client, error) {
+	if providerData == nil {
+		return nil, nil
+	}
+	c, ok := providerData.(*Client)
+	if !ok {
+		return nil, fmt.Errorf("expected *Client, got %T", providerData)
+	}
+	return c, nil
+}
+
+// stringsFromSet returns the elements of a set of strings, nil if it is null or unknown.
+func stringsFromSet(ctx context.Context, set types.Set) []string {
+	if set.IsNull() || set.IsUnknown() {
+		return nil
+	}
+	var result []string
+	set.ElementsAs(ctx, &result, false)
+	return result
+}
+
+// stringSet returns a set of strings.
+func stringSet(values []string) types.Set {
+	elements := make([]attr.Value, 0, len(values))
+	for _, v := range values {
+		elements = append(elements, types.StringValue(v))
+	}
+	return types.SetValueMust(types.StringType, elements)
+}
+
+// setFromStrings returns a set of strings, null if values is empty and prior is null,
+// so an optional argument that is not in the configuration stays null.
+func setFromStrings(values []string, prior types.Set) types.Set {
+	if len(values) == 0 && prior.IsNull() {
+		return types.SetNull(types.StringType)
+	}
+	return stringSet(values)
+}
+
+// optionalString returns v, or null if v is empty and prior is null.
+func optionalString(v string, prior types.String) types.String {
+	if len(v) == 0 && prior.IsNull() {
+		return types.StringNull()
+	}
+	return types.StringValue(v)
+}
+
+// optionalBool returns v, or null if v is false and prior is null.
+func optionalBool(v bool, prior types.Bool) types.Bool {
+	if !v && prior.IsNull() {
+		return types.BoolNull()
+	}
+	return types.BoolValue(v)
+}
+
+// isKnown returns true if v is neither null nor unknown.
+func isKnown(v attr.Value) bool {
+	return !v.IsNull() && !v.IsUnknown()
+}
+
+// fullyKnown returns true if v and all the values nested in it are known.
+func fullyKnown(ctx context.Context, v attr.Value) bool {
+	tv, err := v.ToTerraformValue(ctx)
+	return err == nil && tv.IsFullyKnown()
+}
+
+// withPlannedValues returns result with the values that are known in plan, nested values in
+// objects and lists are merged, sets are taken from plan if they are fully known.
+// Terraform requires the state after apply to have the planned values.
+func withPlannedValues(plan, result tftypes.Value) (tftypes.Value, error) {
+	if plan.IsFullyKnown() {
+		return plan, nil
+	}
+	if !plan.IsKnown() || !result.IsKnown() || result.IsNull() {
+		return result, nil
+	}
+	ty := plan.Type()
+	switch {
+	case ty.Is(tftypes.Object{}):
+		var planned, results map[string]tftypes.Value
+		if err := plan.As(&planned); err != nil {
+			return result, err
+		}
+		if err := result.As(&results); err != nil {
+			return result, err
+		}
+		merged := make(map[string]tftypes.Value, len(results))
+		for k, v := range results {
+			p, ok := planned[k]
+			if !ok {
+				merged[k] = v
+				continue
+			}
+			m, err := withPlannedValues(p, v)
+			if err != nil {
+				return result, err
+			}
+			merged[k] = m
+		}
+		return tftypes.NewValue(result.Type(), merged), nil
+	case ty.Is(tftypes.List{}):
+		var planned, results []tftypes.Value
+		if err := plan.As(&planned); err != nil {
+			return result, err
+		}
+		if err := result.As(&results); err != nil {
+			return result, err
+		}
+		if len(planned) != len(results) {
+			return result, nil
+		}
+		merged := make([]tftypes.Value, 0, len(results))
+		for i := range results {
+			m, err := withPlannedValues(planned[i], results[i])
+			if err != nil {
+				return result, err
+			}
+			merged = append(merged, m)
+		}
+		return tftypes.NewValue(result.Type(), merged), nil
+	}
+	return result, nil
+}
@@ -0,0 +1,91 @@
+package appgate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+var claimSegmentRegex = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// claimOperators are the JavaScript templates for each operator, with the claim path and the json encoded value.
+var claimOperators = map[string]string{
+	"equals":       "%[1]s === %[2]s",
+	"not_equals":   "%[1]s !== %[2]s",
+	"contains":     "(%[1]s && %[1]s.indexOf(%[2]s) >= 0)",
+	"not_contains": "!(%[1]s && %[1]s.indexOf(%[2]s) >= 0)",
+}
+
+// claimExpressionFunction is provider::appgatesdp::claim_expression.
+type claimExpressionFunction struct{}
+
+var _ function.Function = claimExpressionFunction{}
+
+func newClaimExpressionFunction() function.Function {
+	return claimExpressionFunction{}
+}
+
+func (f claimExpressionFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "claim_expression"
+}
+
+func (f claimExpressionFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Build a claims expression",
+		Description: "Returns a JavaScript expression that compares a claim with a value, for the expression of policies, conditions and criteria scripts. The value is escaped as a JavaScript string.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "claim",
+				Description: "Path of the claim, for example user.groups or claims.user.groups.",
+			},
+			function.StringParameter{
+				Name:        "operator",
+				Description: "One of equals, not_equals, contains or not_contains. contains checks if a list claim, such as user.groups, includes the value.",
+			},
+			function.StringParameter{
+				Name:        "value",
+				Description: "Value to compare the claim with.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f claimExpressionFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var claim, operator, value string
+	resp.Error = req.Arguments.Get(ctx, &claim, &operator, &value)
+	if resp.Error != nil {
+		return
+	}
+	expression, err := claimExpression(claim, operator, value)
+	if err != nil {
+		resp.Error = err
+		return
+	}
+	resp.Error = resp.Result.Set(ctx, expression)
+}
+
+func claimExpression(claim, operator, value string) (string, *function.FuncError) {
+	path := strings.Split(strings.TrimPrefix(claim, "claims."), ".")
+	for _, segment := range path {
+		if !claimSegmentRegex.MatchString(segment) {
+			return "", functionArgumentError(0, "invalid claim %q, expected a path such as user.groups", claim)
+		}
+	}
+	template, ok := claimOperators[operator]
+	if !ok {
+		return "", functionArgumentError(1, "invalid operator %q, expected one of equals, not_equals, contains or not_contains", operator)
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return "", functionArgumentError(2, "could not escape %q: %s", value, err)
+	}
+	return fmt.Sprintf(template, "claims."+strings.Join(path, "."), strings.TrimSuffix(buf.String(), "\n")), nil
+}
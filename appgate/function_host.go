@@ -0,0 +1,75 @@
+package appgate
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+var hostnameLabelRegex = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?$`)
+
+// hostFunction is provider::appgatesdp::host.
+type hostFunction struct{}
+
+var _ function.Function = hostFunction{}
+
+func newHostFunction() function.Function {
+	return hostFunction{}
+}
+
+func (f hostFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "host"
+}
+
+func (f hostFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Build a host selector",
+		Description: "Returns a host for entitlement actions that is resolved by the site name resolvers, such as dns://hostname.company.com or aws://tag:Name=web.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "resolver",
+				Description: "One of dns, aws or azure.",
+			},
+			function.StringParameter{
+				Name:        "selector",
+				Description: "Hostname for dns, or the resolver query for aws and azure, for example tag:Name=web.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f hostFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var resolver, selector string
+	resp.Error = req.Arguments.Get(ctx, &resolver, &selector)
+	if resp.Error != nil {
+		return
+	}
+	host, err := hostSelector(resolver, selector)
+	if err != nil {
+		resp.Error = err
+		return
+	}
+	resp.Error = resp.Result.Set(ctx, host)
+}
+
+func hostSelector(resolver, selector string) (string, *function.FuncError) {
+	switch resolver {
+	case "dns":
+		name := strings.TrimPrefix(strings.TrimSuffix(selector, "."), "*.")
+		for _, label := range strings.Split(name, ".") {
+			if !hostnameLabelRegex.MatchString(label) {
+				return "", functionArgumentError(1, "invalid hostname %q", selector)
+			}
+		}
+	case "aws", "azure":
+		if len(selector) == 0 || strings.ContainsAny(selector, " \t\r\n") || strings.Contains(selector, "://") {
+			return "", functionArgumentError(1, "invalid %s selector %q", resolver, selector)
+		}
+	default:
+		return "", functionArgumentError(0, "invalid resolver %q, expected one of dns, aws or azure", resolver)
+	}
+	return resolver + "://" + selector, nil
+}
@@ -0,0 +1,62 @@
+package appgate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// portRangeFunction is provider::appgatesdp::port_range.
+type portRangeFunction struct{}
+
+var _ function.Function = portRangeFunction{}
+
+func newPortRangeFunction() function.Function {
+	return portRangeFunction{}
+}
+
+func (f portRangeFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "port_range"
+}
+
+func (f portRangeFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Build a port range",
+		Description: "Returns the port range for entitlement actions and ringfence rules, for example 1024-65535, or a single port if from and to are equal.",
+		Parameters: []function.Parameter{
+			function.Int64Parameter{
+				Name:        "from",
+				Description: "First port of the range.",
+			},
+			function.Int64Parameter{
+				Name:        "to",
+				Description: "Last port of the range.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f portRangeFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var from, to int64
+	resp.Error = req.Arguments.Get(ctx, &from, &to)
+	if resp.Error != nil {
+		return
+	}
+	for i, port := range []int64{from, to} {
+		if port < 1 || port > 65535 {
+			resp.Error = functionArgumentError(i, "invalid port %d, expected a whole number between 1 and 65535", port)
+			return
+		}
+	}
+	if from > to {
+		resp.Error = functionArgumentError(1, "to (%d) must not be lower than from (%d)", to, from)
+		return
+	}
+	if from == to {
+		resp.Error = resp.Result.Set(ctx, fmt.Sprintf("%d", from))
+		return
+	}
+	resp.Error = resp.Result.Set(ctx, fmt.Sprintf("%d-%d", from, to))
+}
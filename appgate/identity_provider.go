@@ -2,13 +2,14 @@ package appgate
 
 import (
 	"bytes"
-	"errors"
+	"context"
 	"fmt"
 	"log"
 
 	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
 	"github.com/appgate/terraform-provider-appgatesdp/appgate/hashcode"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -24,13 +25,10 @@ const (
 	builtinProviderConnector        = "Connector"
 )
 
-var (
-	ErrNetworkInactivityTimeoutEnabled = errors.New("network_inactivity_timeout_enabled is only available in 6.1 or higher")
-)
-
 func identityProviderSchema() map[string]*schema.Schema {
 	return mergeSchemaMaps(baseEntitySchema(), identityProviderIPPoolSchema(), identityProviderClaimsSchema(), func() map[string]*schema.Schema {
 		ip := map[string]*schema.Schema{
+			"etag": etagSchema(),
 			"type": {
 				Optional: true,
 				Type:     schema.TypeString,
@@ -419,8 +417,8 @@ func ldapProviderSchema() map[string]*schema.Schema {
 }
 
 // readProviderFromConfig reads all the common attributes for the IdentityProviders.
-func readProviderFromConfig(d *schema.ResourceData, provider openapi.ConfigurableIdentityProvider) (*openapi.ConfigurableIdentityProvider, error) {
-	base, err := readBaseEntityFromConfig(d)
+func readProviderFromConfig(d *schema.ResourceData, meta interface{}, provider openapi.ConfigurableIdentityProvider) (*openapi.ConfigurableIdentityProvider, error) {
+	base, err := readBaseEntityFromConfig(d, meta)
 	if err != nil {
 		return &provider, err
 	}
@@ -576,16 +574,16 @@ func readIdentityProviderOnDemandClaimMappingFromConfig(input []interface{}) []o
 	return claims
 }
 
-func identityProviderDelete(d *schema.ResourceData, meta interface{}) error {
+func identityProviderDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Delete LdapProvider: %s", d.Get("name").(string))
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.IdentityProvidersApi
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	if _, err := api.IdentityProvidersIdDelete(ctx, d.Id()).Execute(); err != nil {
-		return fmt.Errorf("Could not delete LdapProvider %w", prettyPrintAPIError(err))
+		return diag.FromErr(fmt.Errorf("Could not delete LdapProvider %w", prettyPrintAPIError(err)))
 	}
 	d.SetId("")
 	return nil
@@ -0,0 +1,165 @@
+package appgate
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	importPrefixName = "name:"
+	importPrefixTag  = "tag:"
+)
+
+// importCandidate is an object returned by an importLister, the importer resolves
+// name:<name> and tag:<tag> to the ID of the only candidate that matches.
+type importCandidate struct {
+	ID   string
+	Name string
+	Tags []string
+}
+
+// importLister returns the objects matching query, the API query matches on several fields
+// so the importer filters the result on the exact name or tag.
+// The listers are generated in find_resource_by_name.go by gen/gen-accessors.go, except for the
+// policies and identity providers, which are filtered on the type.
+type importLister func(ctx context.Context, c *Client, token, query string) ([]importCandidate, error)
+
+type importable interface {
+	GetId() string
+	GetName() string
+	GetTags() []string
+}
+
+func importCandidates[T any, P interface {
+	*T
+	importable
+}](data []T) []importCandidate {
+	candidates := make([]importCandidate, 0, len(data))
+	for i := range data {
+		p := P(&data[i])
+		candidates = append(candidates, importCandidate{ID: p.GetId(), Name: p.GetName(), Tags: p.GetTags()})
+	}
+	return candidates
+}
+
+// importStateByNameOrTag returns an importer that accepts the UUID of the object, as
+// schema.ImportStatePassthroughContext, or name:<name> or tag:<tag> if exactly one object matches.
+func importStateByNameOrTag(kind string, list importLister) schema.StateContextFunc {
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+		id, err := resolveImportID(ctx, meta.(*Client), kind, d.Id(), list)
+		if err != nil {
+			return nil, err
+		}
+		d.SetId(id)
+		return []*schema.ResourceData{d}, nil
+	}
+}
+
+// resolveImportID returns the UUID of the object matching name:<name> or tag:<tag>,
+// any other import ID is returned as it is.
+func resolveImportID(ctx context.Context, c *Client, kind, id string, list importLister) (string, error) {
+	var name, tag string
+	switch {
+	case strings.HasPrefix(id, importPrefixName):
+		name = strings.TrimPrefix(id, importPrefixName)
+	case strings.HasPrefix(id, importPrefixTag):
+		tag = strings.ToLower(strings.TrimPrefix(id, importPrefixTag))
+	default:
+		return id, nil
+	}
+	query := name + tag
+	if len(query) == 0 {
+		return "", fmt.Errorf("invalid import ID %q, expected <uuid>, name:<name> or tag:<tag>", id)
+	}
+	log.Printf("[DEBUG] Importing %s by %s", kind, id)
+	token, err := c.GetToken()
+	if err != nil {
+		return "", err
+	}
+	candidates, err := list(ctx, c, token, query)
+	if err != nil {
+		return "", fmt.Errorf("could not list %s matching %q: %w", kind, query, err)
+	}
+	var matches []importCandidate
+	for _, candidate := range candidates {
+		if len(name) > 0 && candidate.Name == name {
+			matches = append(matches, candidate)
+		}
+		if len(tag) > 0 && inArray(tag, lowercaseTags(candidate.Tags)) {
+			matches = append(matches, candidate)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("could not find %s with %s - please note that names are case sensitive", kind, id)
+	case 1:
+		return matches[0].ID, nil
+	}
+	ids := make([]string, 0, len(matches))
+	for _, m := range matches {
+		ids = append(ids, fmt.Sprintf("%s (%s)", m.ID, m.Name))
+	}
+	return "", fmt.Errorf("%s matched %d %s objects, import one of them by UUID instead: %s", id, len(matches), kind, strings.Join(ids, ", "))
+}
+
+func lowercaseTags(tags []string) []string {
+	lower := make([]string, 0, len(tags))
+	for _, t := range tags {
+		lower = append(lower, strings.ToLower(t))
+	}
+	return lower
+}
+
+// listPolicyImportCandidates returns the policies of policyType, or all policies if policyType is empty.
+func listPolicyImportCandidates(policyType string) importLister {
+	return func(ctx context.Context, c *Client, token, query string) ([]importCandidate, error) {
+		list, _, err := c.API.PoliciesApi.PoliciesGet(authContext(ctx, token)).Query(query).OrderBy("name").Execute()
+		if err != nil {
+			return nil, err
+		}
+		data := list.GetData()
+		if len(policyType) > 0 {
+			filtered := data[:0]
+			for _, p := range data {
+				if p.GetType() == policyType {
+					filtered = append(filtered, p)
+				}
+			}
+			data = filtered
+		}
+		return importCandidates(data), nil
+	}
+}
+
+// listIdentityProviderImportCandidates returns the identity providers of providerType, the
+// identity provider list is untyped so the fields are read from the maps.
+func listIdentityProviderImportCandidates(providerType string) importLister {
+	return func(ctx context.Context, c *Client, token, query string) ([]importCandidate, error) {
+		list, _, err := c.API.IdentityProvidersApi.IdentityProvidersGet(authContext(ctx, token)).Query(query).OrderBy("name").Execute()
+		if err != nil {
+			return nil, err
+		}
+		var candidates []importCandidate
+		for _, provider := range list.GetData() {
+			if t, _ := provider["type"].(string); t != providerType {
+				continue
+			}
+			candidate := importCandidate{}
+			candidate.ID, _ = provider["id"].(string)
+			candidate.Name, _ = provider["name"].(string)
+			if tags, ok := provider["tags"].([]interface{}); ok {
+				for _, tag := range tags {
+					if s, ok := tag.(string); ok {
+						candidate.Tags = append(candidate.Tags, s)
+					}
+				}
+			}
+			candidates = append(candidates, candidate)
+		}
+		return candidates, nil
+	}
+}
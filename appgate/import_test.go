@@ -0,0 +1,120 @@
+package appgate
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImportStateByNameOrTag(t *testing.T) {
+	_, _, mux, _, port, teardown := setup()
+	defer teardown()
+	mux.HandleFunc("/admin/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, loginResponse)
+	})
+	mux.HandleFunc("/admin/conditions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data": [
+			{"id": "a5a9e3a5-45e1-4d2b-a8d3-1d3a1f0f8e01", "name": "Always", "tags": ["builtin"], "expression": "return true;"},
+			{"id": "b5a9e3a5-45e1-4d2b-a8d3-1d3a1f0f8e02", "name": "Always copy", "tags": ["builtin", "Api"], "expression": "return true;"},
+			{"id": "c5a9e3a5-45e1-4d2b-a8d3-1d3a1f0f8e03", "name": "Never", "tags": ["api"], "expression": "return false;"}
+		]}`)
+	})
+	c := &Config{
+		URL:          fmt.Sprintf("http://localhost:%d", port),
+		Username:     "admin",
+		Password:     "admin",
+		Version:      22,
+		LoginTimeout: 1 * time.Minute,
+	}
+	client, err := c.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	importer := importStateByNameOrTag("condition", listConditionImportCandidates)
+
+	tests := []struct {
+		importID string
+		want     string
+		wantErr  string
+	}{
+		{importID: "d3131f83-10d1-4abc-ac0b-7349538e8300", want: "d3131f83-10d1-4abc-ac0b-7349538e8300"},
+		{importID: "name:Always", want: "a5a9e3a5-45e1-4d2b-a8d3-1d3a1f0f8e01"},
+		{importID: "name:always", wantErr: "could not find condition"},
+		{importID: "tag:never", wantErr: "could not find condition"},
+		{importID: "tag:api", wantErr: "tag:api matched 2 condition objects"},
+		{importID: "tag:builtin", wantErr: "b5a9e3a5-45e1-4d2b-a8d3-1d3a1f0f8e02 (Always copy)"},
+		{importID: "name:", wantErr: "invalid import ID"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.importID, func(t *testing.T) {
+			d := resourceAppgateCondition().Data(nil)
+			d.SetId(tt.importID)
+			result, err := importer(context.Background(), d, client)
+			if len(tt.wantErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result) != 1 || result[0].Id() != tt.want {
+				t.Fatalf("got %v, want %s", result[0].Id(), tt.want)
+			}
+		})
+	}
+}
+
+func TestListPolicyImportCandidatesFiltersType(t *testing.T) {
+	_, _, mux, _, port, teardown := setup()
+	defer teardown()
+	mux.HandleFunc("/admin/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, loginResponse)
+	})
+	mux.HandleFunc("/admin/policies", func(w http.ResponseWriter, r *http.Request) {
+		if q := r.URL.Query().Get("query"); q != "developers" {
+			t.Errorf("got query %q", q)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data": [
+			{"id": "f1", "name": "developers", "type": "Access", "expression": "return true;"},
+			{"id": "f2", "name": "developers", "type": "Stop", "expression": "return true;"}
+		]}`)
+	})
+	c := &Config{
+		URL:          fmt.Sprintf("http://localhost:%d", port),
+		Username:     "admin",
+		Password:     "admin",
+		Version:      22,
+		LoginTimeout: 1 * time.Minute,
+	}
+	client, err := c.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := client.GetToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	candidates, err := listPolicyImportCandidates(PolicyTypeStop)(context.Background(), client, token, "developers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(candidates) != 1 || candidates[0].ID != "f2" {
+		t.Fatalf("got %+v", candidates)
+	}
+	all, err := listPolicyImportCandidates("")(context.Background(), client, token, "developers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("got %+v", all)
+	}
+}
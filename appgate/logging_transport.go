@@ -0,0 +1,159 @@
+package appgate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// tflogCtx marks request contexts that carry the terraform logger, requests
+// without it, such as POST /login, are logged with the provider configure context.
+const tflogCtx contextKey = "tflog"
+
+// redactedValue replaces sensitive values in the logged json bodies.
+const redactedValue = "***"
+
+// sensitiveKeys are json keys, case insensitive, whose values are never logged.
+var sensitiveKeys = []string{"password", "passphrase", "secret", "token", "privatekey"}
+
+// requestIDHeaders are response headers used to correlate the request with the controller logs.
+var requestIDHeaders = []string{"X-Request-Id", "X-Correlation-Id", "Request-Id"}
+
+// loggingTransport logs the requests to the controller with tflog, the json bodies
+// are only logged with debug enabled, and the sensitive values are always redacted.
+type loggingTransport struct {
+	logCtx context.Context
+	bodies bool
+	next   http.RoundTripper
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if ctx.Value(tflogCtx) == nil && t.logCtx != nil {
+		ctx = t.logCtx
+	}
+	fields := map[string]interface{}{
+		"http_method": req.Method,
+		"http_host":   req.URL.Host,
+		"http_path":   req.URL.Path,
+	}
+	if t.bodies && req.Body != nil && req.Body != http.NoBody {
+		body, err := peekRequestBody(req)
+		if err != nil {
+			return nil, err
+		}
+		fields["http_request_body"] = redactBody(req.Header.Get("Content-Type"), body)
+	}
+	tflog.Debug(ctx, "Sending HTTP request", fields)
+
+	start := time.Now()
+	res, err := t.next.RoundTrip(req)
+	fields = map[string]interface{}{
+		"http_method": req.Method,
+		"http_host":   req.URL.Host,
+		"http_path":   req.URL.Path,
+		"latency_ms":  time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+		tflog.Debug(ctx, "HTTP request failed", fields)
+		return res, err
+	}
+	fields["http_status"] = res.StatusCode
+	for _, header := range requestIDHeaders {
+		if id := res.Header.Get(header); len(id) > 0 {
+			fields["request_id"] = id
+			break
+		}
+	}
+	if t.bodies && res.Body != nil {
+		body, readErr := io.ReadAll(res.Body)
+		res.Body.Close()
+		res.Body = io.NopCloser(bytes.NewReader(body))
+		if readErr != nil {
+			return res, readErr
+		}
+		fields["http_response_body"] = redactBody(res.Header.Get("Content-Type"), body)
+	}
+	tflog.Debug(ctx, "Received HTTP response", fields)
+	return res, nil
+}
+
+// peekRequestBody reads the request body, and leaves it unread for the next http.RoundTripper.
+func peekRequestBody(req *http.Request) ([]byte, error) {
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return io.ReadAll(body)
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, err
+}
+
+// redactBody pretty prints json bodies with the sensitive values redacted. Other
+// content types, such as file uploads, are never logged since we can't redact them.
+func redactBody(contentType string, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if len(mediaType) > 0 && !strings.HasSuffix(mediaType, "json") {
+		return fmt.Sprintf("<%d bytes %s>", len(body), mediaType)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return fmt.Sprintf("<%d bytes>", len(body))
+	}
+	pretty, err := json.MarshalIndent(redactJSON(v), "", "  ")
+	if err != nil {
+		return fmt.Sprintf("<%d bytes>", len(body))
+	}
+	return string(pretty)
+}
+
+func redactJSON(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, nested := range value {
+			if isSensitiveKey(key) && nested != nil {
+				value[key] = redactedValue
+				continue
+			}
+			value[key] = redactJSON(nested)
+		}
+	case []interface{}:
+		for i, nested := range value {
+			value[i] = redactJSON(nested)
+		}
+	}
+	return v
+}
+
+func isSensitiveKey(key string) bool {
+	k := strings.ToLower(key)
+	// content is used for file uploads, such as p12 files and scripts.
+	if k == "content" {
+		return true
+	}
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(k, sensitive) {
+			return true
+		}
+	}
+	return false
+}
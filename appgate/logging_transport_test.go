@@ -0,0 +1,136 @@
+package appgate
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+	}{
+		{
+			name:        "login",
+			contentType: "application/json",
+			body:        `{"providerName":"local","username":"admin","password":"admin","deviceId":"4c07bc67-57ea-42dd-b702-c2d6c45419fc"}`,
+			want: `{
+  "deviceId": "4c07bc67-57ea-42dd-b702-c2d6c45419fc",
+  "password": "***",
+  "providerName": "local",
+  "username": "admin"
+}`,
+		},
+		{
+			name:        "nested secrets",
+			contentType: "application/vnd.appgate.peer-v18+json",
+			body:        `{"token":"abc","user":{"name":"admin"},"p12":{"content":"MIIJ","subjectName":"CN=x"},"providers":[{"sharedSecret":"s","bindPassword":"p","privateKey":"k","clientSecret":null}],"backupPassphrase":"b","port":8443}`,
+			want: `{
+  "backupPassphrase": "***",
+  "p12": {
+    "content": "***",
+    "subjectName": "CN=x"
+  },
+  "port": 8443,
+  "providers": [
+    {
+      "bindPassword": "***",
+      "clientSecret": null,
+      "privateKey": "***",
+      "sharedSecret": "***"
+    }
+  ],
+  "token": "***",
+  "user": {
+    "name": "admin"
+  }
+}`,
+		},
+		{
+			name:        "not json",
+			contentType: "application/octet-stream",
+			body:        "password=admin",
+			want:        "<14 bytes application/octet-stream>",
+		},
+		{
+			name: "invalid json",
+			body: `{"password": "adm`,
+			want: "<17 bytes>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactBody(tt.contentType, []byte(tt.body)); got != tt.want {
+				t.Errorf("redactBody() got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoggingTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), "s3cret") {
+			t.Errorf("expected the request body to reach the controller, got %s", body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "req-1234")
+		fmt.Fprint(w, `{"token": "very-secret-token", "user": {"name": "admin"}}`)
+	}))
+	defer server.Close()
+
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+	client := &http.Client{Transport: &loggingTransport{logCtx: ctx, bodies: true, next: http.DefaultTransport}}
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/admin/login", strings.NewReader(`{"username": "admin", "password": "s3cret"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body), "very-secret-token") {
+		t.Errorf("expected the response body to be unchanged, got %s", body)
+	}
+
+	entries, err := tflogtest.MultilineJSONDecode(&output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 log entries, got %d: %v", len(entries), entries)
+	}
+	response := entries[1]
+	for key, want := range map[string]interface{}{
+		"http_method": "POST",
+		"http_path":   "/admin/login",
+		"http_status": float64(200),
+		"request_id":  "req-1234",
+	} {
+		if got := response[key]; got != want {
+			t.Errorf("expected %s %v, got %v", key, want, got)
+		}
+	}
+	if _, ok := response["latency_ms"]; !ok {
+		t.Error("expected latency_ms in the log entry")
+	}
+	for _, secret := range []string{"s3cret", "very-secret-token"} {
+		if strings.Contains(fmt.Sprint(entries), secret) {
+			t.Errorf("log entries contain %q: %v", secret, entries)
+		}
+	}
+}
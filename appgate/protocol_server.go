@@ -0,0 +1,40 @@
+package appgate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-mux/tf5muxserver"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// ProtocolServer returns the provider server used by main, Provider is still used for acceptance
+// tests of the SDK resources.
+func ProtocolServer() tfprotov5.ProviderServer {
+	server, err := newMuxServer(Provider())
+	if err != nil {
+		panic(fmt.Sprintf("could not create the provider server: %s", err))
+	}
+	return server
+}
+
+// newMuxServer muxes the SDK provider p with the framework provider, which serves the resources
+// that have been moved to terraform-plugin-framework, the ephemeral resources and the functions.
+// Both share the *Client configured by p.
+func newMuxServer(p *schema.Provider) (tfprotov5.ProviderServer, error) {
+	server, err := tf5muxserver.NewMuxServer(
+		context.Background(),
+		// ConfigureProvider is sent to the servers in this order, the framework provider uses the
+		// *Client from the SDK provider so it must be configured first.
+		func() tfprotov5.ProviderServer {
+			return readOnlyDestroyServer{ProviderServer: p.GRPCProvider(), provider: p}
+		},
+		providerserver.NewProtocol5(newFrameworkProvider(p)()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return server.ProviderServer(), nil
+}
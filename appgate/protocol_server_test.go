@@ -0,0 +1,175 @@
+package appgate
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func hasError(diags []*tfprotov5.Diagnostic) bool {
+	for _, d := range diags {
+		if d.Severity == tfprotov5.DiagnosticSeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+func TestProtocolServerSchema(t *testing.T) {
+	s := ProtocolServer()
+	schemas, err := s.GetProviderSchema(context.Background(), &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schemas.Diagnostics) > 0 {
+		t.Fatalf("unexpected diagnostics %+v", schemas.Diagnostics[0])
+	}
+	if _, ok := schemas.ResourceSchemas["appgatesdp_site"]; !ok {
+		t.Fatal("expected the SDK resources in the schema")
+	}
+	for _, name := range []string{"appgatesdp_policy", "appgatesdp_access_policy", "appgatesdp_admin_policy", "appgatesdp_device_policy", "appgatesdp_dns_policy", "appgatesdp_stop_policy"} {
+		if _, ok := schemas.ResourceSchemas[name]; !ok {
+			t.Errorf("expected the framework resource %s in the schema", name)
+		}
+	}
+	if _, ok := schemas.DataSourceSchemas["appgatesdp_policy"]; !ok {
+		t.Error("expected the SDK data source appgatesdp_policy in the schema")
+	}
+	metadata, err := s.GetMetadata(context.Background(), &tfprotov5.GetMetadataRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"appgatesdp_admin_token", "appgatesdp_appliance_seed"} {
+		if _, ok := schemas.EphemeralResourceSchemas[name]; !ok {
+			t.Errorf("%s is not in the provider schema", name)
+		}
+		found := false
+		for _, m := range metadata.EphemeralResources {
+			found = found || m.TypeName == name
+		}
+		if !found {
+			t.Errorf("%s is not in the provider metadata", name)
+		}
+	}
+	for _, name := range []string{"claim_expression", "host", "port_range"} {
+		if _, ok := schemas.Functions[name]; !ok {
+			t.Errorf("%s is not in the provider schema", name)
+		}
+	}
+}
+
+// testOpenEphemeralResource opens the ephemeral resource name with values in the configuration.
+func testOpenEphemeralResource(t *testing.T, s tfprotov5.ProviderServer, schemas *tfprotov5.GetProviderSchemaResponse, name string, values map[string]tftypes.Value) (map[string]tftypes.Value, []*tfprotov5.Diagnostic) {
+	ty := schemas.EphemeralResourceSchemas[name].ValueType()
+	resp, err := s.OpenEphemeralResource(context.Background(), &tfprotov5.OpenEphemeralResourceRequest{
+		TypeName: name,
+		Config:   testDynamicValue(t, testObjectValue(ty, values)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hasError(resp.Diagnostics) {
+		return nil, resp.Diagnostics
+	}
+	return testAttributes(t, resp.Result, ty), resp.Diagnostics
+}
+
+func TestOpenAdminToken(t *testing.T) {
+	_, _, mux, _, port, teardown := setup()
+	defer teardown()
+	mux.HandleFunc("/admin/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, loginResponse)
+	})
+	s, schemas := testFrameworkServer(t, nil)
+	if _, diags := testOpenEphemeralResource(t, s, schemas, "appgatesdp_admin_token", nil); !hasError(diags) {
+		t.Fatal("expected an error before the provider is configured")
+	}
+
+	c := &Config{
+		URL:          fmt.Sprintf("http://localhost:%d", port),
+		Username:     "admin",
+		Password:     "admin",
+		Version:      22,
+		LoginTimeout: 1 * time.Minute,
+	}
+	client, err := c.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, schemas = testFrameworkServer(t, client)
+	result, diags := testOpenEphemeralResource(t, s, schemas, "appgatesdp_admin_token", nil)
+	if hasError(diags) {
+		t.Fatalf("got %v", diags[0])
+	}
+	if token := testStringValue(t, result["token"]); token != client.Token || len(token) == 0 {
+		t.Fatalf("got token %q, want %q", token, client.Token)
+	}
+	if url := testStringValue(t, result["url"]); url != fmt.Sprintf("http://localhost:%d/admin", port) {
+		t.Fatalf("got url %q, want the normalized admin API url", url)
+	}
+}
+
+func TestOpenAdminTokenWithURLs(t *testing.T) {
+	_, _, mux, server, _, teardown := setup()
+	defer teardown()
+	mux.HandleFunc("/admin/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, loginResponse)
+	})
+	down := httptest.NewServer(http.NotFoundHandler())
+	downURL := down.URL
+	down.Close()
+	c := &Config{
+		URLs:         []string{downURL, server.URL},
+		URLsOrder:    URLsOrdered,
+		Username:     "admin",
+		Password:     "admin",
+		Version:      22,
+		LoginTimeout: 1 * time.Minute,
+	}
+	client, err := c.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, schemas := testFrameworkServer(t, client)
+	result, diags := testOpenEphemeralResource(t, s, schemas, "appgatesdp_admin_token", nil)
+	if hasError(diags) {
+		t.Fatalf("got %v", diags[0])
+	}
+	if url := testStringValue(t, result["url"]); url != server.URL+"/admin" {
+		t.Fatalf("got url %q, want the controller the provider failed over to %q", url, server.URL+"/admin")
+	}
+}
+
+func TestOpenAdminTokenWithBearerToken(t *testing.T) {
+	_, _, _, _, port, teardown := setup()
+	defer teardown()
+	c := &Config{
+		URL:          fmt.Sprintf("http://localhost:%d", port),
+		BearerToken:  "c2VjcmV0",
+		Version:      22,
+		LoginTimeout: 1 * time.Minute,
+	}
+	client, err := c.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, schemas := testFrameworkServer(t, client)
+	result, diags := testOpenEphemeralResource(t, s, schemas, "appgatesdp_admin_token", nil)
+	if hasError(diags) {
+		t.Fatalf("got %v", diags[0])
+	}
+	if token := testStringValue(t, result["token"]); token != "c2VjcmV0" {
+		t.Fatalf("got token %q, want the bearer_token without the Bearer scheme", token)
+	}
+	if expires := testStringValue(t, result["expires"]); len(expires) > 0 {
+		t.Fatalf("got expires %q", expires)
+	}
+}
@@ -5,7 +5,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	pkgversion "github.com/appgate/terraform-provider-appgatesdp/version"
@@ -62,6 +66,18 @@ func Provider() *schema.Provider {
 				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("APPGATE_ADDRESS", nil),
 			},
+			"urls": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "List of controller URLs in a HA collective, used instead of url. The provider fails over to the next controller on connection errors and HTTP 5xx.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"urls_order": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "The order the controllers in urls are tried, ordered or random. Defaults to ordered.",
+				ValidateFunc: validation.StringInSlice([]string{URLsOrdered, URLsRandom}, false),
+			},
 			"username": {
 				Type:          schema.TypeString,
 				Optional:      true,
@@ -102,12 +118,39 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("APPGATE_CONFIG_PATH", nil),
 				Description: "Path to the appgate config file. Can be set with APPGATE_CONFIG_PATH.",
 			},
+			"profile": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("APPGATE_PROFILE", nil),
+				Description: "Name of the profile to use from the config_path file. Can be set with APPGATE_PROFILE.",
+			},
 			"pem_filepath": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("APPGATE_PEM_FILEPATH", nil),
 				Description: "Path to the controller's CA cert file in PEM format",
 			},
+			"client_cert": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("APPGATE_CLIENT_CERT", nil),
+				RequiredWith: []string{"client_key"},
+				Description:  "Client certificate for mutual TLS, PEM content or path to a PEM file.",
+			},
+			"client_key": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				DefaultFunc:  schema.EnvDefaultFunc("APPGATE_CLIENT_KEY", nil),
+				RequiredWith: []string{"client_cert"},
+				Description:  "Private key of the client_cert for mutual TLS, PEM content or path to a PEM file.",
+			},
+			"pinned_fingerprints": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "SHA-256 fingerprints of the SubjectPublicKeyInfo of the controller certificate or CA, in hex or base64. Used instead of the CA to verify the controller certificate.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
 			"bearer_token": {
 				Type:          schema.TypeString,
 				Optional:      true,
@@ -115,6 +158,55 @@ func Provider() *schema.Provider {
 				ConflictsWith: []string{"username", "password"},
 				Description:   "The Token from the LoginResponse, provided from outside terraform.",
 			},
+			"credential_process": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("APPGATE_CREDENTIAL_PROCESS", nil),
+				ConflictsWith: []string{"username", "password", "bearer_token"},
+				Description:   "Command that outputs the credentials as json, used instead of username and password or bearer_token. Can be set with APPGATE_CREDENTIAL_PROCESS.",
+			},
+			"otp_seed": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				DefaultFunc:   schema.EnvDefaultFunc("APPGATE_OTP_SEED", nil),
+				ConflictsWith: []string{"otp_command"},
+				Description:   "Base32 encoded TOTP seed used to complete the admin MFA challenge during login. Can be set with APPGATE_OTP_SEED.",
+			},
+			"otp_command": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("APPGATE_OTP_COMMAND", nil),
+				ConflictsWith: []string{"otp_seed"},
+				Description:   "Command that outputs the one-time password used to complete the admin MFA challenge during login. Can be set with APPGATE_OTP_COMMAND.",
+			},
+			"proxy_url": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("APPGATE_PROXY_URL", nil),
+				ValidateFunc: validation.IsURLWithScheme([]string{"http", "https", "socks5"}),
+				Description:  "URL of the proxy used for the controller, instead of HTTP_PROXY and HTTPS_PROXY. Can be set with APPGATE_PROXY_URL.",
+			},
+			"no_proxy": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("APPGATE_NO_PROXY", nil),
+				Description: "Comma separated hosts that are not accessed through the proxy, instead of NO_PROXY. Can be set with APPGATE_NO_PROXY.",
+			},
+			"proxy_username": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("APPGATE_PROXY_USERNAME", nil),
+				Description: "Username for proxy authentication. Can be set with APPGATE_PROXY_USERNAME.",
+			},
+			"proxy_password": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				DefaultFunc:  schema.EnvDefaultFunc("APPGATE_PROXY_PASSWORD", nil),
+				RequiredWith: []string{"proxy_username"},
+				Description:  "Password for proxy authentication. Can be set with APPGATE_PROXY_PASSWORD.",
+			},
 			"device_id": {
 				Type:         schema.TypeString,
 				Optional:     true,
@@ -122,24 +214,91 @@ func Provider() *schema.Provider {
 				ValidateFunc: validation.IsUUID,
 				Description:  "UUID to distinguish the Client device making the request. It is supposed to be same for every login request from the same server.",
 			},
-			"login_timeout": {
+			"read_only": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Fail plans that would create, update or destroy a resource, and reject all requests that can modify the collective. Can be enabled with APPGATE_READ_ONLY, which can't be disabled from the configuration.",
+			},
+			"force_overwrite": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("APPGATE_FORCE_OVERWRITE", false),
+				Description: "Update objects even if they have been modified outside terraform since they were last read. Can be set with APPGATE_FORCE_OVERWRITE.",
+			},
+			"default_tags": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Tags added to all resources that support tags, in addition to the resource tags.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"token_cache_path": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				DefaultFunc: schema.EnvDefaultFunc("APPGATE_LOGIN_TIMEOUT", "10m"),
-				ValidateFunc: func(v interface{}, name string) (warns []string, errs []error) {
-					s, ok := v.(string)
-					if !ok {
-						errs = append(errs, fmt.Errorf("expected type of %q to be string", name))
-						return
-					}
-
-					if _, err := time.ParseDuration(s); err != nil {
-						errs = append(errs, fmt.Errorf("expected %q to be a valid duration, got %v", name, v))
-					}
-
-					return warns, errs
-				},
-				Description: "Maximum amount of time in seconds to wait for a successful login request to the Controller upon startup.",
+				DefaultFunc: schema.EnvDefaultFunc("APPGATE_TOKEN_CACHE_PATH", nil),
+				Description: "Path to a file where the login token is cached between terraform invocations. Can be set with APPGATE_TOKEN_CACHE_PATH.",
+			},
+			"max_retries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("APPGATE_MAX_RETRIES", DefaultMaxRetries),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum number of retries when the controller responds with HTTP 429, 502 or 503. Defaults to 3.",
+			},
+			"retry_max_wait": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("APPGATE_RETRY_MAX_WAIT", DefaultRetryMaxWait.String()),
+				ValidateFunc: validateDuration,
+				Description:  "Maximum duration (e.g. 1s, 5m) to wait between two retries. Defaults to 30s.",
+			},
+			"max_concurrent_requests": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("APPGATE_MAX_CONCURRENT_REQUESTS", 0),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum number of concurrent requests to the controller, shared by all resources. Defaults to 0, unlimited.",
+			},
+			"max_writes_per_second": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("APPGATE_MAX_WRITES_PER_SECOND", 0.0),
+				ValidateFunc: validation.FloatAtLeast(0),
+				Description:  "Maximum number of POST, PUT and DELETE requests per second to the controller, shared by all resources. Defaults to 0, unlimited.",
+			},
+			"request_timeout": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("APPGATE_REQUEST_TIMEOUT", DefaultRequestTimeout.String()),
+				ValidateFunc: validateDuration,
+				Description:  "Maximum duration (e.g. 30s, 5m) of each HTTP request to the controller, including the response body. Defaults to 40s.",
+			},
+			"tls_handshake_timeout": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("APPGATE_TLS_HANDSHAKE_TIMEOUT", DefaultTLSHandshakeTimeout.String()),
+				ValidateFunc: validateDuration,
+				Description:  "Maximum duration of the TLS handshake with the controller. Defaults to 20s.",
+			},
+			"idle_conn_timeout": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("APPGATE_IDLE_CONN_TIMEOUT", DefaultIdleConnTimeout.String()),
+				ValidateFunc: validateDuration,
+				Description:  "How long idle keep-alive connections to the controller are kept open. Defaults to 1m30s.",
+			},
+			"max_idle_conns_per_host": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("APPGATE_MAX_IDLE_CONNS_PER_HOST", DefaultMaxIdleConnsPerHost),
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "Maximum number of idle keep-alive connections to each controller. Defaults to 10.",
+			},
+			"login_timeout": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("APPGATE_LOGIN_TIMEOUT", "10m"),
+				ValidateFunc: validateDuration,
+				Description:  "Maximum amount of time in seconds to wait for a successful login request to the Controller upon startup.",
 			},
 		},
 		DataSourcesMap: map[string]*schema.Resource{
@@ -173,11 +332,6 @@ func Provider() *schema.Provider {
 			"appgatesdp_site":                               resourceAppgateSite(),
 			"appgatesdp_ringfence_rule":                     resourceAppgateRingfenceRule(),
 			"appgatesdp_condition":                          resourceAppgateCondition(),
-			"appgatesdp_policy":                             resourceAppgatePolicy(),
-			"appgatesdp_device_policy":                      resourceAppgateDevicePolicy(),
-			"appgatesdp_dns_policy":                         resourceAppgateDnsPolicy(),
-			"appgatesdp_access_policy":                      resourceAppgateAccessPolicy(),
-			"appgatesdp_admin_policy":                       resourceAppgateAdminPolicy(),
 			"appgatesdp_criteria_script":                    resourceAppgateCriteriaScript(),
 			"appgatesdp_entitlement_script":                 resourceAppgateEntitlementScript(),
 			"appgatesdp_device_script":                      resourceAppgateDeviceScript(),
@@ -200,39 +354,37 @@ func Provider() *schema.Provider {
 			"appgatesdp_ldap_certificate_identity_provider": resourceAppgateLdapCertificateProvider(),
 			"appgatesdp_connector_identity_provider":        resourceAppgateConnectorProvider(),
 			"appgatesdp_client_profile":                     resourceAppgateClientProfile(),
-			"appgatesdp_stop_policy":                        resourceAppgateStopPolicy(),
 			"appgatesdp_replication_target":                 resourceAppgateReplicationTarget(),
 			"appgatesdp_replication_source":                 resourceAppgateReplicationSource(),
 		},
 	}
 
+	for typeName, r := range provider.ResourcesMap {
+		r.CustomizeDiff = readOnlyCustomizeDiff(typeName, r.CustomizeDiff)
+	}
+
 	provider.ConfigureContextFunc = func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
-		return providerConfigure(d, provider.UserAgent("appgatesdp", pkgversion.ProviderVersion))
+		return providerConfigure(ctx, d, provider.UserAgent("appgatesdp", pkgversion.ProviderVersion))
 	}
 	return provider
 }
 
-func providerConfigure(d *schema.ResourceData, ua string) (interface{}, diag.Diagnostics) {
+func providerConfigure(ctx context.Context, d *schema.ResourceData, ua string) (interface{}, diag.Diagnostics) {
 	// Warning or errors can be collected in a slice type
 	var diags diag.Diagnostics
 	config := Config{
 		UserAgent: ua,
+		logCtx:    ctx,
 	}
-	config.Timeout = 20
 	configFile := Config{}
 	usingFile := false
 
+	profile := d.Get("profile").(string)
 	if path, ok := d.GetOk("config_path"); ok {
 		p := path.(string)
-		file, err := os.OpenFile(p, os.O_RDWR, 0644)
-		if errors.Is(err, os.ErrNotExist) {
-			diags = append(diags, diag.Diagnostic{
-				Severity: diag.Error,
-				Summary:  "Missing Appgate SDP credentials",
-				Detail:   fmt.Sprintf("appgate config_path file not found %s", err),
-			})
-			return nil, diags
-		} else if err != nil {
+		var err error
+		configFile, err = readConfigFile(p, profile)
+		if err != nil {
 			diags = append(diags, diag.Diagnostic{
 				Severity: diag.Error,
 				Summary:  "Missing Appgate SDP credentials",
@@ -240,16 +392,26 @@ func providerConfigure(d *schema.ResourceData, ua string) (interface{}, diag.Dia
 			})
 			return nil, diags
 		}
-		defer file.Close()
-		if err := json.NewDecoder(file).Decode(&configFile); err != nil {
-			diags = append(diags, diag.Diagnostic{
-				Severity: diag.Error,
-				Summary:  "Missing Appgate SDP credentials",
-				Detail:   fmt.Sprintf("appgate config_path invalid json format %s", err),
-			})
-			return nil, diags
-		}
 		usingFile = true
+		// the bearer token in the config file can be rotated by an external process
+		// while terraform is running, so we will read it again if it gets rejected.
+		config.RefreshBearerToken = func() (string, error) {
+			c, err := readConfigFile(p, profile)
+			if err != nil {
+				return "", err
+			}
+			if len(c.BearerToken) == 0 {
+				return "", fmt.Errorf("no appgate_bearer_token in %s", p)
+			}
+			return c.BearerToken, nil
+		}
+	} else if len(profile) > 0 {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Missing Appgate SDP credentials",
+			Detail:   fmt.Sprintf("appgate profile %q is set, but config_path is missing", profile),
+		})
+		return nil, diags
 	}
 
 	if v, ok := d.GetOk("bearer_token"); ok {
@@ -264,6 +426,12 @@ func providerConfigure(d *schema.ResourceData, ua string) (interface{}, diag.Dia
 	if v, ok := d.GetOk("url"); ok {
 		config.URL = v.(string)
 	}
+	if v, ok := d.GetOk("urls"); ok {
+		config.URLs, _ = readArrayOfStringsFromConfig(v.([]interface{}))
+	}
+	if v, ok := d.GetOk("urls_order"); ok {
+		config.URLsOrder = v.(string)
+	}
 	if v, ok := d.GetOk("provider"); ok {
 		config.Provider = v.(string)
 	}
@@ -276,15 +444,96 @@ func providerConfigure(d *schema.ResourceData, ua string) (interface{}, diag.Dia
 	if v, ok := d.GetOk("debug"); ok {
 		config.Debug = v.(bool)
 	}
+	if v, ok := d.GetOk("read_only"); ok {
+		config.ReadOnly = v.(bool)
+	}
+	// APPGATE_READ_ONLY can only enable read_only, so a pipeline that sets it can't be made writable from the configuration.
+	if v := os.Getenv("APPGATE_READ_ONLY"); len(v) > 0 {
+		readOnly, err := strconv.ParseBool(v)
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Invalid APPGATE_READ_ONLY",
+				Detail:   fmt.Sprintf("APPGATE_READ_ONLY must be true or false, got %q", v),
+			})
+			return nil, diags
+		}
+		config.ReadOnly = config.ReadOnly || readOnly
+	}
+	if v, ok := d.GetOk("force_overwrite"); ok {
+		config.ForceOverwrite = v.(bool)
+	}
+	if v, ok := d.GetOk("default_tags"); ok {
+		config.DefaultTags = tagsFromSet(v)
+	}
 	if v, ok := d.GetOk("client_version"); ok {
 		config.Version = v.(int)
 	}
 	if v, ok := d.GetOk("pem_filepath"); ok {
 		config.PemFilePath = v.(string)
 	}
+	if v, ok := d.GetOk("client_cert"); ok {
+		config.ClientCert = v.(string)
+	}
+	if v, ok := d.GetOk("client_key"); ok {
+		config.ClientKey = v.(string)
+	}
+	if v, ok := d.GetOk("pinned_fingerprints"); ok {
+		config.PinnedFingerprints, _ = readArrayOfStringsFromConfig(v.([]interface{}))
+	}
+	if v, ok := d.GetOk("proxy_url"); ok {
+		config.ProxyURL = v.(string)
+	}
+	if v, ok := d.GetOk("no_proxy"); ok {
+		config.NoProxy = v.(string)
+	}
+	if v, ok := d.GetOk("proxy_username"); ok {
+		config.ProxyUsername = v.(string)
+	}
+	if v, ok := d.GetOk("proxy_password"); ok {
+		config.ProxyPassword = v.(string)
+	}
 	if v, ok := d.GetOk("device_id"); ok {
 		config.DeviceID = v.(string)
 	}
+	if v, ok := d.GetOk("otp_seed"); ok {
+		config.OTPSeed = v.(string)
+	}
+	if v, ok := d.GetOk("otp_command"); ok {
+		config.OTPCommand = v.(string)
+	}
+	if v, ok := d.GetOk("credential_process"); ok {
+		config.CredentialProcess = v.(string)
+	}
+	if v, ok := d.GetOk("token_cache_path"); ok {
+		config.TokenCachePath = v.(string)
+	}
+	// max_retries = 0 disables the retries, so we can't use GetOk.
+	config.MaxRetries = d.Get("max_retries").(int)
+	if v, ok := d.GetOk("max_concurrent_requests"); ok {
+		config.MaxConcurrentRequests = v.(int)
+	}
+	if v, ok := d.GetOk("max_writes_per_second"); ok {
+		config.MaxWritesPerSecond = v.(float64)
+	}
+	if v, ok := d.GetOk("retry_max_wait"); ok {
+		// validation is performed at Provider
+		duration, _ := time.ParseDuration(v.(string))
+		config.RetryMaxWait = duration
+	}
+	// validation is performed at Provider
+	if v, ok := d.GetOk("request_timeout"); ok {
+		config.RequestTimeout, _ = time.ParseDuration(v.(string))
+	}
+	if v, ok := d.GetOk("tls_handshake_timeout"); ok {
+		config.TLSHandshakeTimeout, _ = time.ParseDuration(v.(string))
+	}
+	if v, ok := d.GetOk("idle_conn_timeout"); ok {
+		config.IdleConnTimeout, _ = time.ParseDuration(v.(string))
+	}
+	if v, ok := d.GetOk("max_idle_conns_per_host"); ok {
+		config.MaxIdleConnsPerHost = v.(int)
+	}
 	if v, ok := d.GetOk("login_timeout"); ok {
 		// validation is performed at Provider
 		duration, _ := time.ParseDuration(v.(string))
@@ -307,6 +556,16 @@ func providerConfigure(d *schema.ResourceData, ua string) (interface{}, diag.Dia
 			return nil, diags
 		}
 	}
+	if len(config.CredentialProcess) > 0 {
+		if err := config.loadCredentialProcess(); err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Missing Appgate SDP credentials",
+				Detail:   fmt.Sprintf("Unable to get credentials from credential_process. %s", err),
+			})
+			return nil, diags
+		}
+	}
 	// if no device_id is set by the user, we will set
 	// the value based on the machine id, fallback to random UUID
 	_, errs := validation.IsUUID(config.DeviceID, "device_id")
@@ -343,6 +602,67 @@ func providerConfigure(d *schema.ResourceData, ua string) (interface{}, diag.Dia
 	return c, diags
 }
 
+func validateDuration(v interface{}, name string) (warns []string, errs []error) {
+	s, ok := v.(string)
+	if !ok {
+		errs = append(errs, fmt.Errorf("expected type of %q to be string", name))
+		return
+	}
+
+	if _, err := time.ParseDuration(s); err != nil {
+		errs = append(errs, fmt.Errorf("expected %q to be a valid duration, got %v", name, v))
+	}
+
+	return warns, errs
+}
+
+// DefaultProfile is the profile used from the config_path file if no profile is set.
+const DefaultProfile = "default"
+
+// configFile is the format of the config_path file. The top level keys are shared
+// by all profiles, and the selected profile takes precedence over them.
+type configFile struct {
+	Config
+	Profiles map[string]Config `json:"profiles,omitempty"`
+}
+
+// readConfigFile reads the json config file from config_path, merged with the named profile.
+// if profile is empty, the DefaultProfile is used if it exists in the file.
+func readConfigFile(path, profile string) (Config, error) {
+	f := configFile{}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return f.Config, fmt.Errorf("appgate config_path file not found %s", err)
+	} else if err != nil {
+		return f.Config, err
+	}
+	defer file.Close()
+	if err := json.NewDecoder(file).Decode(&f); err != nil {
+		return f.Config, fmt.Errorf("appgate config_path invalid json format %s", err)
+	}
+	name := profile
+	if len(name) == 0 {
+		name = DefaultProfile
+	}
+	p, ok := f.Profiles[name]
+	if !ok {
+		if len(profile) == 0 {
+			return f.Config, nil
+		}
+		names := make([]string, 0, len(f.Profiles))
+		for k := range f.Profiles {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		return f.Config, fmt.Errorf("appgate profile %q not found in %s, available profiles: [%s]", profile, path, strings.Join(names, ", "))
+	}
+	log.Printf("[DEBUG] Using appgate profile %q from %s", name, path)
+	if err := mergo.Merge(&f.Config, p, mergo.WithOverride); err != nil {
+		return f.Config, fmt.Errorf("could not merge appgate profile %q %w", name, err)
+	}
+	return f.Config, nil
+}
+
 func defaultDeviceID() string {
 	readAndParseUUID := func() (uuid.UUID, error) {
 		// machine.ID() tries to read
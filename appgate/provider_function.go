@@ -0,0 +1,14 @@
+package appgate
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Provider-defined functions are called as provider::appgatesdp::<name>(...), they run during
+// plan without any request to the controller. They are served by frameworkProvider.
+
+func functionArgumentError(i int, format string, args ...interface{}) *function.FuncError {
+	return function.NewArgumentFuncError(int64(i), fmt.Sprintf(format, args...))
+}
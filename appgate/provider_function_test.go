@@ -0,0 +1,153 @@
+package appgate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func callTestFunction(t *testing.T, name string, args ...tftypes.Value) (string, *tfprotov5.FunctionError) {
+	t.Helper()
+	arguments := make([]*tfprotov5.DynamicValue, 0, len(args))
+	for _, a := range args {
+		dv, err := tfprotov5.NewDynamicValue(a.Type(), a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		arguments = append(arguments, &dv)
+	}
+	resp, err := ProtocolServer().CallFunction(context.Background(), &tfprotov5.CallFunctionRequest{Name: name, Arguments: arguments})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Error != nil {
+		return "", resp.Error
+	}
+	v, err := resp.Result.Unmarshal(tftypes.String)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testStringValue(t, v), nil
+}
+
+func TestGetFunctions(t *testing.T) {
+	resp, err := ProtocolServer().GetFunctions(context.Background(), &tfprotov5.GetFunctionsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"claim_expression", "host", "port_range"} {
+		if _, ok := resp.Functions[name]; !ok {
+			t.Errorf("%s is not in GetFunctions", name)
+		}
+	}
+}
+
+func TestProviderFunctions(t *testing.T) {
+	str := func(s string) tftypes.Value { return tftypes.NewValue(tftypes.String, s) }
+	num := func(n float64) tftypes.Value { return tftypes.NewValue(tftypes.Number, n) }
+	tests := []struct {
+		name       string
+		function   string
+		args       []tftypes.Value
+		want       string
+		wantErrArg int64
+	}{
+		{
+			name:     "claim equals",
+			function: "claim_expression",
+			args:     []tftypes.Value{str("user.username"), str("equals"), str("admin")},
+			want:     `claims.user.username === "admin"`,
+		},
+		{
+			name:     "claim contains",
+			function: "claim_expression",
+			args:     []tftypes.Value{str("claims.user.groups"), str("contains"), str("developers")},
+			want:     `(claims.user.groups && claims.user.groups.indexOf("developers") >= 0)`,
+		},
+		{
+			name:     "claim value is escaped",
+			function: "claim_expression",
+			args:     []tftypes.Value{str("user.tag"), str("not_equals"), str(`a"); return true; //<b>`)},
+			want:     `claims.user.tag !== "a\"); return true; //<b>"`,
+		},
+		{
+			name:       "invalid claim",
+			function:   "claim_expression",
+			args:       []tftypes.Value{str("user.groups || true"), str("equals"), str("x")},
+			wantErrArg: 0,
+		},
+		{
+			name:       "invalid operator",
+			function:   "claim_expression",
+			args:       []tftypes.Value{str("user.groups"), str("=="), str("x")},
+			wantErrArg: 1,
+		},
+		{
+			name:     "dns host",
+			function: "host",
+			args:     []tftypes.Value{str("dns"), str("hostname.company.com")},
+			want:     "dns://hostname.company.com",
+		},
+		{
+			name:     "aws host",
+			function: "host",
+			args:     []tftypes.Value{str("aws"), str("tag:Name=web")},
+			want:     "aws://tag:Name=web",
+		},
+		{
+			name:       "invalid hostname",
+			function:   "host",
+			args:       []tftypes.Value{str("dns"), str("host name.company.com")},
+			wantErrArg: 1,
+		},
+		{
+			name:       "unknown resolver",
+			function:   "host",
+			args:       []tftypes.Value{str("gcp"), str("web")},
+			wantErrArg: 0,
+		},
+		{
+			name:     "port range",
+			function: "port_range",
+			args:     []tftypes.Value{num(1024), num(65535)},
+			want:     "1024-65535",
+		},
+		{
+			name:     "single port",
+			function: "port_range",
+			args:     []tftypes.Value{num(443), num(443)},
+			want:     "443",
+		},
+		{
+			name:       "reversed range",
+			function:   "port_range",
+			args:       []tftypes.Value{num(443), num(80)},
+			wantErrArg: 1,
+		},
+		{
+			name:       "invalid port",
+			function:   "port_range",
+			args:       []tftypes.Value{num(80.5), num(81)},
+			wantErrArg: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ferr := callTestFunction(t, tt.function, tt.args...)
+			if len(tt.want) == 0 {
+				if ferr == nil || ferr.FunctionArgument == nil || *ferr.FunctionArgument != tt.wantErrArg {
+					t.Fatalf("got %q %v, expected an error for argument %d", got, ferr, tt.wantErrArg)
+				}
+				return
+			}
+			if ferr != nil {
+				t.Fatalf("got error %s", ferr.Text)
+			}
+			if got != tt.want {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
@@ -30,12 +32,22 @@ var testAccProvider *schema.Provider
 // for tests requiring special provider configurations.
 var testAccProviderFactories map[string]func() (*schema.Provider, error)
 
+// testAccProtoV5ProviderFactories serves testAccProvider muxed with the terraform-plugin-framework
+// resources, it is used by the tests of the policy resources.
+var testAccProtoV5ProviderFactories map[string]func() (tfprotov5.ProviderServer, error)
+
 func init() {
 	testAccProvider = Provider()
 	testAccProviders = map[string]*schema.Provider{
 		"appgatesdp": testAccProvider,
 	}
 
+	testAccProtoV5ProviderFactories = map[string]func() (tfprotov5.ProviderServer, error){
+		"appgatesdp": func() (tfprotov5.ProviderServer, error) {
+			return newMuxServer(testAccProvider)
+		},
+	}
+
 	// Always allocate a new provider instance each invocation, otherwise gRPC
 	// ProviderConfigure() can overwrite configuration during concurrent testing.
 	testAccProviderFactories = map[string]func() (*schema.Provider, error){
@@ -65,6 +77,100 @@ func TestProvider_impl(t *testing.T) {
 	var _ *schema.Provider = Provider()
 }
 
+func TestReadConfigFile(t *testing.T) {
+	content := `{
+	"appgate_provider": "local",
+	"appgate_client_version": 20,
+	"profiles": {
+		"default": {
+			"appgate_url": "https://default.appgate.com/admin",
+			"appgate_username": "admin"
+		},
+		"prod": {
+			"appgate_url": "https://prod.appgate.com/admin",
+			"appgate_bearer_token": "dG9rZW4=",
+			"appgate_client_version": 22
+		}
+	}
+}`
+	path := filepath.Join(t.TempDir(), "appgate.json")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	flat := filepath.Join(t.TempDir(), "flat.json")
+	if err := os.WriteFile(flat, []byte(`{"appgate_url": "https://flat.appgate.com/admin"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		path    string
+		profile string
+		want    Config
+		wantErr bool
+	}{
+		{
+			name: "default profile",
+			path: path,
+			want: Config{
+				URL:      "https://default.appgate.com/admin",
+				Username: "admin",
+				Provider: "local",
+				Version:  20,
+			},
+		},
+		{
+			name:    "named profile",
+			path:    path,
+			profile: "prod",
+			want: Config{
+				URL:         "https://prod.appgate.com/admin",
+				BearerToken: "dG9rZW4=",
+				Provider:    "local",
+				Version:     22,
+			},
+		},
+		{
+			name:    "unknown profile",
+			path:    path,
+			profile: "staging",
+			wantErr: true,
+		},
+		{
+			name: "config without profiles",
+			path: flat,
+			want: Config{
+				URL: "https://flat.appgate.com/admin",
+			},
+		},
+		{
+			name:    "unknown profile without profiles",
+			path:    flat,
+			profile: "prod",
+			wantErr: true,
+		},
+		{
+			name:    "missing file",
+			path:    filepath.Join(t.TempDir(), "missing.json"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readConfigFile(tt.path, tt.profile)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readConfigFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.URL != tt.want.URL || got.Username != tt.want.Username || got.BearerToken != tt.want.BearerToken ||
+				got.Provider != tt.want.Provider || got.Version != tt.want.Version {
+				t.Errorf("readConfigFile() got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
 // testAccProviderConfigure ensures Provider is only configured once
 //
 // The PreCheck(t) function is invoked for every test and this prevents
@@ -0,0 +1,60 @@
+package appgate
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// proxyFunc returns the proxy function for the client http.Transport. The proxy_url and no_proxy
+// from the provider configuration take precedence over HTTP_PROXY, HTTPS_PROXY and NO_PROXY.
+// It is created for each Client, so provider aliases can use different proxies.
+func (c *Config) proxyFunc() (func(*http.Request) (*url.URL, error), error) {
+	proxyConfig := httpproxy.FromEnvironment()
+	// for backwards compatibility, HTTP_PROXY is also used for HTTPS if HTTPS_PROXY is not set.
+	if len(proxyConfig.HTTPSProxy) == 0 {
+		proxyConfig.HTTPSProxy = proxyConfig.HTTPProxy
+	}
+	if len(c.ProxyURL) > 0 {
+		if !isUrl(c.ProxyURL) {
+			return nil, fmt.Errorf("invalid proxy_url %q", c.ProxyURL)
+		}
+		proxyConfig.HTTPProxy = c.ProxyURL
+		proxyConfig.HTTPSProxy = c.ProxyURL
+		// NO_PROXY belongs to the proxy from the environment, so it does not apply to proxy_url.
+		proxyConfig.NoProxy = c.NoProxy
+	} else if len(c.NoProxy) > 0 {
+		proxyConfig.NoProxy = c.NoProxy
+	}
+	if len(proxyConfig.HTTPSProxy) > 0 {
+		log.Printf("[DEBUG] Using HTTP PROXY %s NO_PROXY %q", redactProxyURL(proxyConfig.HTTPSProxy), proxyConfig.NoProxy)
+	}
+	proxyForURL := proxyConfig.ProxyFunc()
+	username, password := c.ProxyUsername, c.ProxyPassword
+	return func(req *http.Request) (*url.URL, error) {
+		proxyURL, err := proxyForURL(req.URL)
+		if err != nil || proxyURL == nil {
+			return proxyURL, err
+		}
+		if len(username) > 0 && proxyURL.User == nil {
+			// proxyForURL returns the same *url.URL for every request, so it is copied
+			// before the credentials are added.
+			u := *proxyURL
+			u.User = url.UserPassword(username, password)
+			return &u, nil
+		}
+		return proxyURL, nil
+	}, nil
+}
+
+// redactProxyURL removes the proxy password before it is logged.
+func redactProxyURL(proxy string) string {
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return proxy
+	}
+	return u.Redacted()
+}
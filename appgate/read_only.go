@@ -0,0 +1,70 @@
+package appgate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// isReadOnly returns true if meta is a client configured with read_only.
+func isReadOnly(meta interface{}) bool {
+	c, ok := meta.(*Client)
+	return ok && c != nil && c.Config != nil && c.Config.ReadOnly
+}
+
+func readOnlyPlanSummary(typeName string) string {
+	return fmt.Sprintf("%s can't be changed with read_only", typeName)
+}
+
+func readOnlyPlanDetail(action string) string {
+	return fmt.Sprintf(
+		"The provider is configured with read_only = true, the plan would %s the resource. "+
+			"Remove the change from the configuration, or apply it without read_only.",
+		action,
+	)
+}
+
+// readOnlyCustomizeDiff fails the plan of a resource that will be created or updated when the provider
+// is configured with read_only, instead of failing during apply in readOnlyTransport.
+func readOnlyCustomizeDiff(typeName string, next schema.CustomizeDiffFunc) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+		if isReadOnly(meta) {
+			if d.Id() == "" {
+				return fmt.Errorf("%s: %s", readOnlyPlanSummary(typeName), readOnlyPlanDetail("create"))
+			}
+			if len(d.GetChangedKeysPrefix("")) > 0 {
+				return fmt.Errorf("%s: %s", readOnlyPlanSummary(typeName), readOnlyPlanDetail("update"))
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		return next(ctx, d, meta)
+	}
+}
+
+// readOnlyDestroyServer fails the plan of SDK resources that will be destroyed when the provider is
+// configured with read_only. The SDK doesn't call CustomizeDiff when a resource is destroyed.
+type readOnlyDestroyServer struct {
+	tfprotov5.ProviderServer
+	provider *schema.Provider
+}
+
+func (s readOnlyDestroyServer) PlanResourceChange(ctx context.Context, req *tfprotov5.PlanResourceChangeRequest) (*tfprotov5.PlanResourceChangeResponse, error) {
+	resp, err := s.ProviderServer.PlanResourceChange(ctx, req)
+	if err != nil || resp == nil || !isReadOnly(s.provider.Meta()) || req.PriorState == nil || req.ProposedNewState == nil {
+		return resp, err
+	}
+	create, _ := req.PriorState.IsNull()
+	destroy, _ := req.ProposedNewState.IsNull()
+	if destroy && !create {
+		resp.Diagnostics = append(resp.Diagnostics, &tfprotov5.Diagnostic{
+			Severity: tfprotov5.DiagnosticSeverityError,
+			Summary:  readOnlyPlanSummary(req.TypeName),
+			Detail:   readOnlyPlanDetail("destroy"),
+		})
+	}
+	return resp, nil
+}
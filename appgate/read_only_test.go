@@ -0,0 +1,158 @@
+package appgate
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestReadOnlyCustomizeDiff(t *testing.T) {
+	const id = "ee7f8e9c-6e4a-4c3b-8b5d-3b1c2a1e0f55"
+	state := &terraform.InstanceState{
+		ID: id,
+		Attributes: map[string]string{
+			"id":                 id,
+			"condition_id":       id,
+			"name":               "condition",
+			"expression":         "return true;",
+			"notes":              DefaultDescription,
+			"repeat_schedules.#": "0",
+			"tags.#":             "0",
+			"tags_all.#":         "0",
+		},
+	}
+	tests := []struct {
+		name     string
+		readOnly bool
+		state    *terraform.InstanceState
+		notes    string
+		wantErr  string
+	}{
+		{
+			name:     "create",
+			readOnly: true,
+			wantErr:  "plan would create",
+		},
+		{
+			name:     "update",
+			readOnly: true,
+			state:    state,
+			notes:    "updated by terraform",
+			wantErr:  "plan would update",
+		},
+		{
+			name:     "no changes",
+			readOnly: true,
+			state:    state,
+		},
+		{
+			name:  "update without read_only",
+			state: state,
+			notes: "updated by terraform",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := map[string]interface{}{
+				"name":       "condition",
+				"expression": "return true;",
+			}
+			if len(tt.notes) > 0 {
+				raw["notes"] = tt.notes
+			}
+			meta := &Client{Config: &Config{ReadOnly: tt.readOnly}}
+			r := Provider().ResourcesMap["appgatesdp_condition"]
+			_, err := r.Diff(context.Background(), tt.state, terraform.NewResourceConfigRaw(raw), meta)
+			if len(tt.wantErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got %v, expected error %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("got %v, expected no error", err)
+			}
+		})
+	}
+}
+
+// planServer returns the plan it is given, the other methods are not used by the test.
+type planServer struct {
+	tfprotov5.ProviderServer
+}
+
+func (planServer) PlanResourceChange(ctx context.Context, req *tfprotov5.PlanResourceChangeRequest) (*tfprotov5.PlanResourceChangeResponse, error) {
+	return &tfprotov5.PlanResourceChangeResponse{PlannedState: req.ProposedNewState}, nil
+}
+
+func TestReadOnlyDestroyServer(t *testing.T) {
+	ty := tftypes.Object{AttributeTypes: map[string]tftypes.Type{"id": tftypes.String}}
+	null := testDynamicValue(t, tftypes.NewValue(ty, nil))
+	object := testDynamicValue(t, tftypes.NewValue(ty, map[string]tftypes.Value{"id": tftypes.NewValue(tftypes.String, "id")}))
+	tests := []struct {
+		name     string
+		readOnly bool
+		prior    *tfprotov5.DynamicValue
+		proposed *tfprotov5.DynamicValue
+		wantErr  bool
+	}{
+		{name: "destroy", readOnly: true, prior: object, proposed: null, wantErr: true},
+		{name: "refresh", readOnly: true, prior: object, proposed: object},
+		{name: "destroy without read_only", prior: object, proposed: null},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &schema.Provider{}
+			p.SetMeta(&Client{Config: &Config{ReadOnly: tt.readOnly}})
+			s := readOnlyDestroyServer{ProviderServer: planServer{}, provider: p}
+			resp, err := s.PlanResourceChange(context.Background(), &tfprotov5.PlanResourceChangeRequest{
+				TypeName:         "appgatesdp_condition",
+				PriorState:       tt.prior,
+				ProposedNewState: tt.proposed,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hasError(resp.Diagnostics); got != tt.wantErr {
+				t.Fatalf("got error %t, expected %t: %+v", got, tt.wantErr, resp.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestPolicyResourceReadOnlyPlan(t *testing.T) {
+	const typeName = "appgatesdp_policy"
+	s, schemas := testFrameworkServer(t, &Client{Config: &Config{ReadOnly: true}, ApplianceVersion: Appliance65Version})
+	ty := schemas.ResourceSchemas[typeName].ValueType()
+	config := testObjectValue(ty, map[string]tftypes.Value{
+		"name": tftypes.NewValue(tftypes.String, "developers"),
+	})
+	null := tftypes.NewValue(ty, nil)
+
+	created, err := s.PlanResourceChange(context.Background(), &tfprotov5.PlanResourceChangeRequest{
+		TypeName:         typeName,
+		PriorState:       testDynamicValue(t, null),
+		ProposedNewState: testDynamicValue(t, config),
+		Config:           testDynamicValue(t, config),
+	})
+	if err != nil || !hasError(created.Diagnostics) || !strings.Contains(created.Diagnostics[0].Detail, "plan would create") {
+		t.Fatalf("expected the create to fail, got %v %+v", err, created.Diagnostics)
+	}
+
+	state := testObjectValue(ty, map[string]tftypes.Value{
+		"id":   tftypes.NewValue(tftypes.String, "ee7f8e9c-6e4a-4c3b-8b5d-3b1c2a1e0f55"),
+		"name": tftypes.NewValue(tftypes.String, "developers"),
+	})
+	destroyed, err := s.PlanResourceChange(context.Background(), &tfprotov5.PlanResourceChangeRequest{
+		TypeName:         typeName,
+		PriorState:       testDynamicValue(t, state),
+		ProposedNewState: testDynamicValue(t, null),
+		Config:           testDynamicValue(t, null),
+	})
+	if err != nil || !hasError(destroyed.Diagnostics) || !strings.Contains(destroyed.Diagnostics[0].Detail, "plan would destroy") {
+		t.Fatalf("expected the destroy to fail, got %v %+v", err, destroyed.Diagnostics)
+	}
+}
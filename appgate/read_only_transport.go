@@ -0,0 +1,45 @@
+package appgate
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// readOnlyPaths are the writes allowed with read_only, they are needed to login and complete the admin MFA challenge.
+var readOnlyPaths = []string{
+	"/login",
+	"/authentication/otp/initialize",
+	"/authentication/otp",
+}
+
+// readOnlyError is returned for requests that would modify the collective when the provider is read only.
+type readOnlyError struct {
+	Method string
+	Path   string
+}
+
+func (e *readOnlyError) Error() string {
+	return fmt.Sprintf("the provider is configured with read_only = true, %s %s is not allowed", e.Method, e.Path)
+}
+
+// readOnlyTransport rejects all requests that can modify the collective, before they are sent to the controller.
+type readOnlyTransport struct {
+	next http.RoundTripper
+}
+
+func (t *readOnlyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return t.next.RoundTrip(req)
+	}
+	for _, p := range readOnlyPaths {
+		if strings.HasSuffix(req.URL.Path, p) {
+			return t.next.RoundTrip(req)
+		}
+	}
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return nil, &readOnlyError{Method: req.Method, Path: req.URL.Path}
+}
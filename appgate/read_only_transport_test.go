@@ -0,0 +1,73 @@
+package appgate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestReadOnlyTransport(t *testing.T) {
+	_, _, mux, _, port, teardown := setup()
+	defer teardown()
+	mux.HandleFunc("/admin/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, loginResponse)
+	})
+	mux.HandleFunc("/admin/conditions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("%s %s reached the controller", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data": [], "range": "0-0/0"}`)
+	})
+	c := &Config{
+		URL:          fmt.Sprintf("http://localhost:%d", port),
+		Username:     "admin",
+		Password:     "admin",
+		Version:      Version22,
+		LoginTimeout: 1 * time.Minute,
+		ReadOnly:     true,
+	}
+	client, err := c.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := client.GetToken()
+	if err != nil {
+		t.Fatalf("login should be allowed, got %s", err)
+	}
+	ctx := authContext(context.Background(), token)
+	if _, _, err := client.API.ConditionsApi.ConditionsGet(ctx).Execute(); err != nil {
+		t.Fatalf("GET should be allowed, got %s", err)
+	}
+	condition := openapi.NewConditionWithDefaults()
+	condition.SetName("read-only")
+	condition.SetExpression("return true;")
+	_, _, err = client.API.ConditionsApi.ConditionsPost(ctx).Condition(*condition).Execute()
+	var readOnlyErr *readOnlyError
+	if !errors.As(err, &readOnlyErr) {
+		t.Fatalf("got %v, want readOnlyError", err)
+	}
+	if readOnlyErr.Method != http.MethodPost || readOnlyErr.Path != "/admin/conditions" {
+		t.Fatalf("got %+v", readOnlyErr)
+	}
+}
+
+func TestProviderReadOnlyEnv(t *testing.T) {
+	t.Setenv("APPGATE_READ_ONLY", "maybe")
+	p := Provider()
+	diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"url":      "http://localhost:1/admin",
+		"username": "admin",
+		"password": "admin",
+	}))
+	if !diags.HasError() {
+		t.Fatal("expected an error for an invalid APPGATE_READ_ONLY")
+	}
+}
@@ -1,40 +1,18 @@
 package appgate
 
 import (
-	"context"
-
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
 )
 
-func resourceAppgateAdminPolicy() *schema.Resource {
-	return &schema.Resource{
-		CreateContext: func(ctx context.Context, rd *schema.ResourceData, meta interface{}) diag.Diagnostics {
-			return resourceAppgatePolicyCreate(context.WithValue(ctx, PolicyTypeCtx, PolicyTypeAdmin), rd, meta)
-		},
-		ReadContext:   resourceAppgatePolicyRead,
-		UpdateContext: resourceAppgatePolicyUpdate,
-		DeleteContext: resourceAppgatePolicyDelete,
-		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
-		},
-
-		Schema: func() map[string]*schema.Schema {
-			s := mergeSchemaMaps(
-				basePolicySchema(),
-				basePolicyAdminAttributes(),
-			)
-			s["expression"] = &schema.Schema{
-				Type:     schema.TypeString,
-				Optional: true,
-				Default:  emptyPolicyExpression,
-			}
-			// Type is computed in CreateContext
-			s["type"] = &schema.Schema{
-				Type:     schema.TypeString,
-				Computed: true,
-			}
-			return s
-		}(),
+func newAdminPolicyResource() resource.Resource {
+	return &policyResource{
+		typeName:    "appgatesdp_admin_policy",
+		kind:        "admin policy",
+		policyType:  PolicyTypeAdmin,
+		upgradeFrom: []int64{0},
+		schema: policySchema(1,
+			typedPolicySchema(),
+			basePolicyAdminAttributes(),
+		),
 	}
 }
@@ -16,6 +16,7 @@ import (
 
 	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -32,13 +33,15 @@ func resourceAppgateAdministrativeRole() *schema.Resource {
 		UpdateContext: resourceAppgateAdministrativeRoleUpdate,
 		DeleteContext: resourceAppgateAdministrativeRoleDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		SchemaVersion: 1,
+		CustomizeDiff: customdiff.All(tagsCustomizeDiff, etagCustomizeDiff),
 		Schema: map[string]*schema.Schema{
 
 			"administrative_role_id": resourceUUID(),
+			"etag":                   etagSchema(),
 
 			"name": {
 				Type:        schema.TypeString,
@@ -55,6 +58,8 @@ func resourceAppgateAdministrativeRole() *schema.Resource {
 
 			"tags": tagsSchema(),
 
+			"tags_all": tagsAllSchema(),
+
 			"privileges": {
 				Type:     schema.TypeSet,
 				Required: true,
@@ -199,15 +204,13 @@ func resourceAppgateAdministrativeRoleCreate(ctx context.Context, d *schema.Reso
 	currentVersion := meta.(*Client).ApplianceVersion
 	api := meta.(*Client).API.AdminRolesApi
 	args := openapi.NewAdministrativeRoleWithDefaults()
-	if v, ok := d.GetOk("administrative_role_id"); ok {
-		args.SetId(v.(string))
-	}
+	args.SetId(resourceObjectID(d, "administrative_role_id"))
 	args.SetName(d.Get("name").(string))
 	args.SetNotes(d.Get("notes").(string))
-	args.SetTags(schemaExtractTags(d))
+	args.SetTags(schemaExtractTags(d, meta))
 
 	if v, ok := d.GetOk("privileges"); ok {
-		ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+		ctx = authContext(ctx, token)
 		targetMap, _, err := api.AdministrativeRolesTypeTargetMapGet(ctx).Execute()
 		if err != nil {
 			return diag.FromErr(err)
@@ -224,11 +227,11 @@ func resourceAppgateAdministrativeRoleCreate(ctx context.Context, d *schema.Reso
 		}
 		args.SetPrivileges(privileges)
 	}
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	request := api.AdministrativeRolesPost(ctx)
 	administrativeRole, _, err := request.AdministrativeRole(*args).Execute()
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("Could not create Administrative role %w", prettyPrintAPIError(err)))
+		return apiErrorDiagnostics("Could not create Administrative role", err, resourceAppgateAdministrativeRole().Schema)
 	}
 
 	d.SetId(administrativeRole.GetId())
@@ -363,7 +366,7 @@ func resourceAppgateAdministrativeRoleRead(ctx context.Context, d *schema.Resour
 		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.AdminRolesApi
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	request := api.AdministrativeRolesIdGet(ctx, d.Id())
 	administrativeRole, res, err := request.Execute()
 	if err != nil {
@@ -374,10 +377,11 @@ func resourceAppgateAdministrativeRoleRead(ctx context.Context, d *schema.Resour
 		return diag.FromErr(fmt.Errorf("Failed to read Administrative role, %w", err))
 	}
 	d.SetId(administrativeRole.GetId())
+	d.Set("etag", objectETag(administrativeRole))
 	d.Set("administrative_role_id", administrativeRole.GetId())
 	d.Set("name", administrativeRole.GetName())
 	d.Set("notes", administrativeRole.GetNotes())
-	d.Set("tags", administrativeRole.GetTags())
+	setTags(d, meta, administrativeRole.GetTags())
 
 	privileges, err := flattenAdministrativeRolePrivileges(administrativeRole.GetPrivileges())
 	if err != nil {
@@ -438,12 +442,15 @@ func resourceAppgateAdministrativeRoleUpdate(ctx context.Context, d *schema.Reso
 	}
 	api := meta.(*Client).API.AdminRolesApi
 	currentVersion := meta.(*Client).ApplianceVersion
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	request := api.AdministrativeRolesIdGet(ctx, d.Id())
 	originalAdministrativeRole, _, err := request.Execute()
 	if err != nil {
 		return diag.FromErr(fmt.Errorf("Failed to read Administrative role while updating, %w", err))
 	}
+	if diags := checkNotModified(d, meta, "Administrative role", originalAdministrativeRole); diags.HasError() {
+		return diags
+	}
 	if d.HasChange("name") {
 		originalAdministrativeRole.SetName(d.Get("name").(string))
 	}
@@ -452,13 +459,13 @@ func resourceAppgateAdministrativeRoleUpdate(ctx context.Context, d *schema.Reso
 		originalAdministrativeRole.SetNotes(d.Get("notes").(string))
 	}
 
-	if d.HasChange("tags") {
-		originalAdministrativeRole.SetTags(schemaExtractTags(d))
+	if d.HasChanges("tags", "tags_all") {
+		originalAdministrativeRole.SetTags(schemaExtractTags(d, meta))
 	}
 
 	if d.HasChange("privileges") {
 		_, v := d.GetChange("privileges")
-		ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+		ctx = authContext(ctx, token)
 		targetMap, _, err := api.AdministrativeRolesTypeTargetMapGet(ctx).Execute()
 		if err != nil {
 			return diag.FromErr(err)
@@ -477,7 +484,7 @@ func resourceAppgateAdministrativeRoleUpdate(ctx context.Context, d *schema.Reso
 	}
 	_, _, err = api.AdministrativeRolesIdPut(ctx, d.Id()).AdministrativeRole(*originalAdministrativeRole).Execute()
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("Could not update Administrative role %w", prettyPrintAPIError(err)))
+		return apiErrorDiagnostics("Could not update Administrative role", err, resourceAppgateAdministrativeRole().Schema)
 	}
 	return resourceAppgateAdministrativeRoleRead(ctx, d, meta)
 }
@@ -491,7 +498,7 @@ func resourceAppgateAdministrativeRoleDelete(ctx context.Context, d *schema.Reso
 		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.AdminRolesApi
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	if _, err := api.AdministrativeRolesIdDelete(ctx, d.Id()).Execute(); err != nil {
 		return diag.FromErr(fmt.Errorf("Could not delete Administrative role %w", prettyPrintAPIError(err)))
 	}
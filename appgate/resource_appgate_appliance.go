@@ -15,6 +15,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -34,14 +35,16 @@ func resourceAppgateAppliance() *schema.Resource {
 		ReadContext:   resourceAppgateApplianceRead,
 		UpdateContext: resourceAppgateApplianceUpdate,
 		DeleteContext: resourceAppgateApplianceDelete,
+		CustomizeDiff: customdiff.All(tagsCustomizeDiff, etagCustomizeDiff, attributeVersionCustomizeDiff("appgatesdp_appliance")),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: importStateByNameOrTag("appliance", listApplianceImportCandidates),
 		},
 
 		SchemaVersion: 1,
 		Schema: map[string]*schema.Schema{
 
 			"appliance_id": resourceUUID(),
+			"etag":         etagSchema(),
 			"activated": {
 				Type:     schema.TypeBool,
 				Computed: true,
@@ -61,6 +64,8 @@ func resourceAppgateAppliance() *schema.Resource {
 
 			"tags": tagsSchema(),
 
+			"tags_all": tagsAllSchema(),
+
 			"hostname": {
 				Type:        schema.TypeString,
 				Description: "Hostname of the Appliance. It's used by other Appliances to communicate with and identify this Appliances.",
@@ -1400,13 +1405,11 @@ func resourceAppgateApplianceCreate(ctx context.Context, d *schema.ResourceData,
 	api := meta.(*Client).API.AppliancesApi
 	currentVersion := meta.(*Client).ApplianceVersion
 	args := openapi.NewApplianceWithDefaults()
-	if v, ok := d.GetOk("appliance_id"); ok {
-		args.SetId(v.(string))
-	}
+	args.SetId(resourceObjectID(d, "appliance_id"))
 	args.SetName(d.Get("name").(string))
 	args.SetHostname(d.Get("hostname").(string))
 
-	args.SetTags(schemaExtractTags(d))
+	args.SetTags(schemaExtractTags(d, meta))
 
 	if v, ok := d.GetOk("notes"); ok {
 		args.SetNotes(v.(string))
@@ -1576,9 +1579,9 @@ func resourceAppgateApplianceCreate(ctx context.Context, d *schema.ResourceData,
 		args.SetHostnameAliases(hostnames)
 	}
 
-	appliance, _, err := api.AppliancesPost(context.WithValue(ctx, openapi.ContextAccessToken, token)).Appliance(*args).Execute()
+	appliance, _, err := api.AppliancesPost(authContext(ctx, token)).Appliance(*args).Execute()
 	if err != nil {
-		return diag.Errorf("Could not create appliance %s", prettyPrintAPIError(err))
+		return apiErrorDiagnostics("Could not create appliance", err, resourceAppgateAppliance().Schema)
 	}
 
 	d.SetId(appliance.GetId())
@@ -1814,9 +1817,8 @@ func resourceAppgateApplianceRead(ctx context.Context, d *schema.ResourceData, m
 		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.AppliancesApi
-	currentVersion := meta.(*Client).ApplianceVersion
 
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	request := api.AppliancesIdGet(ctx, d.Id())
 	appliance, res, err := request.Execute()
 	if err != nil {
@@ -1826,9 +1828,10 @@ func resourceAppgateApplianceRead(ctx context.Context, d *schema.ResourceData, m
 		}
 		return diag.Errorf("Failed to read Appliance, %s", err)
 	}
+	d.Set("etag", objectETag(appliance))
 	d.Set("appliance_id", appliance.GetId())
 	d.Set("name", appliance.GetName())
-	d.Set("tags", appliance.GetTags())
+	setTags(d, meta, appliance.GetTags())
 	d.Set("notes", appliance.GetNotes())
 	d.Set("hostname", appliance.GetHostname())
 
@@ -1971,8 +1974,8 @@ func resourceAppgateApplianceRead(ctx context.Context, d *schema.ResourceData, m
 
 		exporter["allowed_users"] = allowedUsers
 
-		if currentVersion.GreaterThanOrEqual(Appliance63Version) {
-			exporter["labels_disabled"] = v.GetLabelsDisabled()
+		if labels, ok := v.GetLabelsDisabledOk(); ok {
+			exporter["labels_disabled"] = labels
 		}
 
 		if err := d.Set("prometheus_exporter", []interface{}{exporter}); err != nil {
@@ -2017,7 +2020,7 @@ func resourceAppgateApplianceRead(ctx context.Context, d *schema.ResourceData, m
 	}
 
 	if v, ok := appliance.GetGatewayOk(); ok {
-		gateway, err := flatttenApplianceGateway(*v, currentVersion)
+		gateway, err := flatttenApplianceGateway(*v)
 		if err != nil {
 			return diag.FromErr(err)
 		}
@@ -2027,7 +2030,7 @@ func resourceAppgateApplianceRead(ctx context.Context, d *schema.ResourceData, m
 	}
 
 	if v, ok := appliance.GetLogForwarderOk(); ok {
-		logforward, err := flatttenApplianceLogForwarder(*v, currentVersion, d)
+		logforward, err := flatttenApplianceLogForwarder(*v, d)
 		if err != nil {
 			return diag.FromErr(err)
 		}
@@ -2037,7 +2040,7 @@ func resourceAppgateApplianceRead(ctx context.Context, d *schema.ResourceData, m
 	}
 
 	if v, ok := appliance.GetMetricsAggregatorOk(); ok {
-		metricsAggr, err := flattenApplianceMetricsAggregator(*v, currentVersion, d)
+		metricsAggr, err := flattenApplianceMetricsAggregator(*v, d)
 		if err != nil {
 			return diag.FromErr(err)
 		}
@@ -2185,17 +2188,15 @@ func flattenAppliancePortalSignInCustomziation(d *schema.ResourceData, customiza
 	return result, nil
 }
 
-func flatttenApplianceGateway(in openapi.ApplianceAllOfGateway, currentVersion *version.Version) ([]map[string]interface{}, error) {
+func flatttenApplianceGateway(in openapi.ApplianceAllOfGateway) ([]map[string]interface{}, error) {
 	var gateways []map[string]interface{}
 	gateway := make(map[string]interface{})
 	if v, ok := in.GetEnabledOk(); ok {
 		gateway["enabled"] = v
 	}
 
-	if currentVersion.GreaterThanOrEqual(Appliance61Version) {
-		if v, ok := in.GetSuspendedOk(); ok {
-			gateway["suspended"] = v
-		}
+	if v, ok := in.GetSuspendedOk(); ok {
+		gateway["suspended"] = v
 	}
 
 	if v, ok := in.GetVpnOk(); ok {
@@ -2204,10 +2205,8 @@ func flatttenApplianceGateway(in openapi.ApplianceAllOfGateway, currentVersion *
 			vpn["weight"] = *v
 		}
 
-		if currentVersion.GreaterThanOrEqual(Appliance62Version) {
-			if v, ok := v.GetLocalWeightOk(); ok {
-				vpn["local_weight"] = *v
-			}
+		if v, ok := v.GetLocalWeightOk(); ok {
+			vpn["local_weight"] = *v
 		}
 		if v, ok := v.GetAllowDestinationsOk(); ok {
 			destinations := make([]map[string]interface{}, 0)
@@ -2227,7 +2226,7 @@ func flatttenApplianceGateway(in openapi.ApplianceAllOfGateway, currentVersion *
 	return gateways, nil
 }
 
-func flatttenApplianceLogForwarder(in openapi.ApplianceAllOfLogForwarder, currentVersion *version.Version, d *schema.ResourceData) ([]map[string]interface{}, error) {
+func flatttenApplianceLogForwarder(in openapi.ApplianceAllOfLogForwarder, d *schema.ResourceData) ([]map[string]interface{}, error) {
 	var logforwarders []map[string]interface{}
 	logforward := make(map[string]interface{})
 	if v, ok := in.GetEnabledOk(); ok {
@@ -2309,104 +2308,98 @@ func flatttenApplianceLogForwarder(in openapi.ApplianceAllOfLogForwarder, curren
 		logforward["aws_kineses"] = kinesesList
 	}
 
-	if currentVersion.GreaterThanOrEqual(Appliance61Version) {
-		if v, ok := in.GetSumoLogicClientsOk(); ok {
-			sumoList := make([]map[string]interface{}, 0)
-			for _, sumo := range v {
-				sumoList = append(sumoList, map[string]interface{}{"url": sumo.GetUrl()})
-			}
-			logforward["sumo_logic"] = sumoList
+	if v, ok := in.GetSumoLogicClientsOk(); ok {
+		sumoList := make([]map[string]interface{}, 0)
+		for _, sumo := range v {
+			sumoList = append(sumoList, map[string]interface{}{"url": sumo.GetUrl()})
 		}
-		if v, ok := in.GetSplunkClientsOk(); ok {
-			splunkList := make([]map[string]interface{}, 0)
-			for index, splunk := range v {
-				s := map[string]interface{}{
-					"url":   splunk.GetUrl(),
-					"token": splunk.GetToken(),
-				}
-				if state := d.Get(fmt.Sprintf("log_forwarder.0.splunk.%d.token", index)).(string); len(state) > 0 {
-					s["token"] = state
-				}
-				splunkList = append(splunkList, s)
+		logforward["sumo_logic"] = sumoList
+	}
+	if v, ok := in.GetSplunkClientsOk(); ok {
+		splunkList := make([]map[string]interface{}, 0)
+		for index, splunk := range v {
+			s := map[string]interface{}{
+				"url":   splunk.GetUrl(),
+				"token": splunk.GetToken(),
 			}
-			logforward["splunk"] = splunkList
+			if state := d.Get(fmt.Sprintf("log_forwarder.0.splunk.%d.token", index)).(string); len(state) > 0 {
+				s["token"] = state
+			}
+			splunkList = append(splunkList, s)
 		}
+		logforward["splunk"] = splunkList
 	}
 
-	if currentVersion.GreaterThanOrEqual(Appliance62Version) {
-		if v, ok := in.GetAzureMonitorsOk(); ok {
-			azureList := make([]map[string]interface{}, 0)
-			for index, azure := range v {
-				s := map[string]interface{}{
-					"app_id":              azure.GetAppId(),
-					"app_secret":          azure.GetAppSecret(),
-					"token_request_url":   azure.GetTokenRequestUrl(),
-					"log_destination_url": azure.GetLogDestinationUrl(),
-				}
-				if currentVersion.GreaterThanOrEqual(Appliance63Version) {
-					s["scope"] = azure.GetScope()
-				}
-				if state := d.Get(fmt.Sprintf("log_forwarder.0.azure_monitor.%d.app_secret", index)).(string); len(state) > 0 {
-					s["app_secret"] = state
-				}
-				azureList = append(azureList, s)
+	if v, ok := in.GetAzureMonitorsOk(); ok {
+		azureList := make([]map[string]interface{}, 0)
+		for index, azure := range v {
+			s := map[string]interface{}{
+				"app_id":              azure.GetAppId(),
+				"app_secret":          azure.GetAppSecret(),
+				"token_request_url":   azure.GetTokenRequestUrl(),
+				"log_destination_url": azure.GetLogDestinationUrl(),
+			}
+			if scope, ok := azure.GetScopeOk(); ok {
+				s["scope"] = *scope
 			}
-			logforward["azure_monitor"] = azureList
+			if state := d.Get(fmt.Sprintf("log_forwarder.0.azure_monitor.%d.app_secret", index)).(string); len(state) > 0 {
+				s["app_secret"] = state
+			}
+			azureList = append(azureList, s)
 		}
-		if v, ok := in.GetFalconLogScalesOk(); ok {
-			falconList := make([]map[string]interface{}, 0)
-			for index, falcon := range v {
-				s := map[string]interface{}{
-					"collector_url": falcon.GetCollectorUrl(),
-					"token":         falcon.GetToken(),
-					"index":         falcon.GetIndex(),
-					"source":        falcon.GetSource(),
-					"source_type":   falcon.GetSourceType(),
-				}
-				if state := d.Get(fmt.Sprintf("log_forwarder.0.falcon_log_scale.%d.token", index)).(string); len(state) > 0 {
-					s["token"] = state
-				}
-				falconList = append(falconList, s)
+		logforward["azure_monitor"] = azureList
+	}
+	if v, ok := in.GetFalconLogScalesOk(); ok {
+		falconList := make([]map[string]interface{}, 0)
+		for index, falcon := range v {
+			s := map[string]interface{}{
+				"collector_url": falcon.GetCollectorUrl(),
+				"token":         falcon.GetToken(),
+				"index":         falcon.GetIndex(),
+				"source":        falcon.GetSource(),
+				"source_type":   falcon.GetSourceType(),
+			}
+			if state := d.Get(fmt.Sprintf("log_forwarder.0.falcon_log_scale.%d.token", index)).(string); len(state) > 0 {
+				s["token"] = state
 			}
-			logforward["falcon_log_scale"] = falconList
+			falconList = append(falconList, s)
 		}
+		logforward["falcon_log_scale"] = falconList
 	}
 
-	if currentVersion.GreaterThanOrEqual(Appliance63Version) {
-		if v, ok := in.GetDatadogsOk(); ok {
-			dataDogsList := make([]map[string]interface{}, 0)
-			for index, dd := range v {
-				s := map[string]interface{}{
-					"site":    dd.GetSite(),
-					"api_key": dd.GetApiKey(),
-					"source":  dd.GetSource(),
-					"tags":    dd.GetTags(),
-				}
-				if state := d.Get(fmt.Sprintf("log_forwarder.0.datadogs.%d.token", index)).(string); len(state) > 0 {
-					s["token"] = state
-				}
-				dataDogsList = append(dataDogsList, s)
+	if v, ok := in.GetDatadogsOk(); ok {
+		dataDogsList := make([]map[string]interface{}, 0)
+		for index, dd := range v {
+			s := map[string]interface{}{
+				"site":    dd.GetSite(),
+				"api_key": dd.GetApiKey(),
+				"source":  dd.GetSource(),
+				"tags":    dd.GetTags(),
+			}
+			if state := d.Get(fmt.Sprintf("log_forwarder.0.datadogs.%d.token", index)).(string); len(state) > 0 {
+				s["token"] = state
 			}
-			logforward["datadogs"] = dataDogsList
+			dataDogsList = append(dataDogsList, s)
 		}
+		logforward["datadogs"] = dataDogsList
+	}
 
-		if v, ok := in.GetCoralogixsOk(); ok {
-			coralogixsList := make([]map[string]interface{}, 0)
-			for index, cl := range v {
-				s := map[string]interface{}{
-					"url":              cl.GetUrl(),
-					"private_key":      cl.GetPrivateKey(),
-					"uuid":             cl.GetUuid(),
-					"application_name": cl.GetApplicationName(),
-					"subsystem_name":   cl.GetSubsystemName(),
-				}
-				if state := d.Get(fmt.Sprintf("log_forwarder.0.coralogixs.%d.token", index)).(string); len(state) > 0 {
-					s["token"] = state
-				}
-				coralogixsList = append(coralogixsList, s)
+	if v, ok := in.GetCoralogixsOk(); ok {
+		coralogixsList := make([]map[string]interface{}, 0)
+		for index, cl := range v {
+			s := map[string]interface{}{
+				"url":              cl.GetUrl(),
+				"private_key":      cl.GetPrivateKey(),
+				"uuid":             cl.GetUuid(),
+				"application_name": cl.GetApplicationName(),
+				"subsystem_name":   cl.GetSubsystemName(),
 			}
-			logforward["coralogixs"] = coralogixsList
+			if state := d.Get(fmt.Sprintf("log_forwarder.0.coralogixs.%d.token", index)).(string); len(state) > 0 {
+				s["token"] = state
+			}
+			coralogixsList = append(coralogixsList, s)
 		}
+		logforward["coralogixs"] = coralogixsList
 	}
 
 	logforward["sites"] = in.GetSites()
@@ -2415,7 +2408,7 @@ func flatttenApplianceLogForwarder(in openapi.ApplianceAllOfLogForwarder, curren
 	return logforwarders, nil
 }
 
-func flattenApplianceMetricsAggregator(in openapi.ApplianceAllOfMetricsAggregator, currentVersion *version.Version, d *schema.ResourceData) ([]map[string]interface{}, error) {
+func flattenApplianceMetricsAggregator(in openapi.ApplianceAllOfMetricsAggregator, d *schema.ResourceData) ([]map[string]interface{}, error) {
 	var metricsAggrs []map[string]interface{}
 	metricsAggr := make(map[string]interface{})
 	if v, ok := in.GetEnabledOk(); ok {
@@ -2467,8 +2460,8 @@ func flattenApplianceMetricsAggregator(in openapi.ApplianceAllOfMetricsAggregato
 		}
 		exporter["allowed_users"] = allowedUsers
 
-		if currentVersion.GreaterThanOrEqual(Appliance63Version) {
-			exporter["labels_disabled"] = v.GetLabelsDisabled()
+		if labels, ok := v.GetLabelsDisabledOk(); ok {
+			exporter["labels_disabled"] = labels
 		}
 
 		metricsAggr["prometheus_exporter"] = []interface{}{exporter}
@@ -2734,12 +2727,15 @@ func resourceAppgateApplianceUpdate(ctx context.Context, d *schema.ResourceData,
 	}
 	api := meta.(*Client).API.AppliancesApi
 	currentVersion := meta.(*Client).ApplianceVersion
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	request := api.AppliancesIdGet(ctx, d.Id())
 	originalAppliance, _, err := request.Execute()
 	if err != nil {
 		return diag.Errorf("Failed to read Appliance, %s", err)
 	}
+	if diags := checkNotModified(d, meta, "Appliance", originalAppliance); diags.HasError() {
+		return diags
+	}
 
 	if d.HasChange("name") {
 		originalAppliance.SetName(d.Get("name").(string))
@@ -2749,8 +2745,8 @@ func resourceAppgateApplianceUpdate(ctx context.Context, d *schema.ResourceData,
 		originalAppliance.SetNotes(d.Get("notes").(string))
 	}
 
-	if d.HasChange("tags") {
-		originalAppliance.SetTags(schemaExtractTags(d))
+	if d.HasChanges("tags", "tags_all") {
+		originalAppliance.SetTags(schemaExtractTags(d, meta))
 	}
 
 	if d.HasChange("hostname") {
@@ -2956,7 +2952,7 @@ func resourceAppgateApplianceUpdate(ctx context.Context, d *schema.ResourceData,
 
 	_, _, err = req.Appliance(*originalAppliance).Execute()
 	if err != nil {
-		return diag.Errorf("Could not update appliance %s", prettyPrintAPIError(err))
+		return apiErrorDiagnostics("Could not update appliance", err, resourceAppgateAppliance().Schema)
 	}
 	return resourceAppgateApplianceRead(ctx, d, meta)
 }
@@ -2972,7 +2968,7 @@ func resourceAppgateApplianceDelete(ctx context.Context, d *schema.ResourceData,
 	api := meta.(*Client).API.AppliancesApi
 
 	// Get appliance
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	request := api.AppliancesIdGet(ctx, d.Id())
 	appliance, _, err := request.Execute()
 	if err != nil {
@@ -3263,10 +3259,8 @@ func readGatewayFromConfig(gateways []interface{}, currentVersion *version.Versi
 		if v, ok := r["enabled"]; ok {
 			val.SetEnabled(v.(bool))
 		}
-		if currentVersion.GreaterThanOrEqual(Appliance61Version) {
-			if v, ok := r["suspended"]; ok {
-				val.SetSuspended(v.(bool))
-			}
+		if v, ok := r["suspended"]; ok && attributeSupported("appgatesdp_appliance", "gateway.suspended", currentVersion) {
+			val.SetSuspended(v.(bool))
 		}
 		if v := r["vpn"].([]interface{}); len(v) > 0 {
 			vpn := openapi.ApplianceAllOfGatewayVpn{}
@@ -3326,36 +3320,35 @@ func readPrometheusExporterFromConfig(exporters []interface{}, currentVersion *v
 			}
 			val.SetAllowSources(allowSources)
 		}
-		if currentVersion.GreaterThanOrEqual(Appliance62Version) {
+		// basic_auth and use_https are always in the configuration, with false if they are not set.
+		if attributeSupported("appgatesdp_appliance", "prometheus_exporter.basic_auth", currentVersion) {
 			if v, ok := rawServer["basic_auth"]; ok {
 				val.SetBasicAuth(v.(bool))
 			}
 			if v, ok := rawServer["use_https"]; ok {
 				val.SetUseHTTPS(v.(bool))
 			}
-			if v, ok := rawServer["https_p12"].([]interface{}); ok && len(v) > 0 && v[0] != nil {
-				p12, err := readP12(v[0])
-				if err != nil {
-					return val, err
-				}
-				val.SetHttpsP12(p12)
+		}
+		if v, ok := rawServer["https_p12"].([]interface{}); ok && len(v) > 0 && v[0] != nil {
+			p12, err := readP12(v[0])
+			if err != nil {
+				return val, err
 			}
-			if v, ok := rawServer["allowed_users"].([]interface{}); ok && len(v) > 0 {
-				allowedUsers, err := readAllowedUsers(v)
-				if err != nil {
-					return val, err
-				}
-				val.SetAllowedUsers(allowedUsers)
+			val.SetHttpsP12(p12)
+		}
+		if v, ok := rawServer["allowed_users"].([]interface{}); ok && len(v) > 0 {
+			allowedUsers, err := readAllowedUsers(v)
+			if err != nil {
+				return val, err
 			}
+			val.SetAllowedUsers(allowedUsers)
 		}
-		if currentVersion.GreaterThanOrEqual(Appliance63Version) {
-			if v, ok := rawServer["labels_disabled"].([]interface{}); ok && len(v) > 0 {
-				labelsDisabled, err := readLabelsDisabled(v)
-				if err != nil {
-					return val, err
-				}
-				val.SetLabelsDisabled(labelsDisabled)
+		if v, ok := rawServer["labels_disabled"].([]interface{}); ok && len(v) > 0 {
+			labelsDisabled, err := readLabelsDisabled(v)
+			if err != nil {
+				return val, err
 			}
+			val.SetLabelsDisabled(labelsDisabled)
 		}
 	}
 	return val, nil
@@ -72,7 +72,7 @@ func resourceAppgateApplianceControllerActivationCreate(ctx context.Context, d *
 	api := meta.(*Client).API.AppliancesApi
 	request := api.AppliancesIdGet(ctx, id)
 
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	appliance, res, err := request.Execute()
 	if err != nil {
 		d.SetId("")
@@ -108,7 +108,7 @@ func resourceAppgateApplianceControllerActivationCreate(ctx context.Context, d *
 		state = ApplianceStateControllerReady
 	}
 	retryErr := resource.RetryContext(ctx, d.Timeout(schema.TimeoutCreate), func() *resource.RetryError {
-		ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+		ctx = authContext(ctx, token)
 		_, _, err := api.AppliancesIdPut(ctx, id).Appliance(*appliance).Execute()
 		if err != nil {
 			return resource.NonRetryableError(prettyPrintAPIError(err))
@@ -146,7 +146,7 @@ func resourceAppgateApplianceControllerActivationRead(ctx context.Context, d *sc
 		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.AppliancesApi
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	request := api.AppliancesIdGet(ctx, d.Id())
 	appliance, res, err := request.Execute()
 	if err != nil {
@@ -189,7 +189,7 @@ func resourceAppgateApplianceControllerActivationUpdate(ctx context.Context, d *
 	}
 	api := meta.(*Client).API.AppliancesApi
 	request := api.AppliancesIdGet(ctx, id)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	appliance, _, err := request.Execute()
 	if err != nil {
 		return diag.FromErr(fmt.Errorf("Failed to read Appliance, %w", err))
@@ -230,7 +230,7 @@ func resourceAppgateApplianceControllerActivationUpdate(ctx context.Context, d *
 	}
 	retryErr := resource.RetryContext(ctx, d.Timeout(schema.TimeoutUpdate), func() *resource.RetryError {
 
-		ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+		ctx = authContext(ctx, token)
 		_, _, err := api.AppliancesIdPut(ctx, id).Appliance(*appliance).Execute()
 		if err != nil {
 			return resource.NonRetryableError(fmt.Errorf("Could not update appliance %w", prettyPrintAPIError(err)))
@@ -271,7 +271,7 @@ func resourceAppgateApplianceControllerActivationDelete(ctx context.Context, d *
 	}
 	api := meta.(*Client).API.AppliancesApi
 	request := api.AppliancesIdGet(ctx, id)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	appliance, _, err := request.Execute()
 	if err != nil {
 		return diag.FromErr(fmt.Errorf("Failed to read Appliance, %w", err))
@@ -281,7 +281,7 @@ func resourceAppgateApplianceControllerActivationDelete(ctx context.Context, d *
 	appliance.SetController(c)
 
 	retryErr := resource.RetryContext(ctx, d.Timeout(schema.TimeoutDelete), func() *resource.RetryError {
-		ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+		ctx = authContext(ctx, token)
 		_, _, err := api.AppliancesIdPut(ctx, id).Appliance(*appliance).Execute()
 		if err != nil {
 			return resource.NonRetryableError(fmt.Errorf("Could not update appliance when disable controller on %s %w", appliance.Name, prettyPrintAPIError(err)))
@@ -2,6 +2,7 @@ package appgate
 
 import (
 	"bufio"
+	"context"
 	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
@@ -13,17 +14,19 @@ import (
 
 	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func resourceAppgateApplianceCustomizations() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceAppgateApplianceCustomizationCreate,
-		Read:   resourceAppgateApplianceCustomizationRead,
-		Update: resourceAppgateApplianceCustomizationUpdate,
-		Delete: resourceAppgateApplianceCustomizationDelete,
+		CreateContext: resourceAppgateApplianceCustomizationCreate,
+		ReadContext:   resourceAppgateApplianceCustomizationRead,
+		UpdateContext: resourceAppgateApplianceCustomizationUpdate,
+		DeleteContext: resourceAppgateApplianceCustomizationDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Timeouts: &schema.ResourceTimeout{
@@ -33,9 +36,11 @@ func resourceAppgateApplianceCustomizations() *schema.Resource {
 		},
 
 		SchemaVersion: 1,
+		CustomizeDiff: customdiff.All(tagsCustomizeDiff, etagCustomizeDiff),
 		Schema: map[string]*schema.Schema{
 
 			"appliance_customization_id": resourceUUID(),
+			"etag":                       etagSchema(),
 
 			"name": {
 				Type:        schema.TypeString,
@@ -52,6 +57,8 @@ func resourceAppgateApplianceCustomizations() *schema.Resource {
 
 			"tags": tagsSchema(),
 
+			"tags_all": tagsAllSchema(),
+
 			"file": {
 				Type:        schema.TypeString,
 				Description: "Path to the appliance customization binary.",
@@ -104,97 +111,99 @@ func resourceAppgateApplianceCustomizations() *schema.Resource {
 	}
 }
 
-func resourceAppgateApplianceCustomizationCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateApplianceCustomizationCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Creating Appliance customization: %s", d.Get("name").(string))
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.ApplianceCustomizationsApi
 	args := openapi.NewApplianceCustomizationWithDefaults()
-	if v, ok := d.GetOk("appliance_customization_id"); ok {
-		args.SetId(v.(string))
-	}
+	args.SetId(resourceObjectID(d, "appliance_customization_id"))
 	args.SetName(d.Get("name").(string))
 	args.SetNotes(d.Get("notes").(string))
 
-	args.SetTags(schemaExtractTags(d))
+	args.SetTags(schemaExtractTags(d, meta))
 
 	content, err := getResourceFileContent(d, "file")
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	if len(content) > 0 {
 		encoded := base64.StdEncoding.EncodeToString(content)
 		args.SetFile(encoded)
 	}
 
-	request := api.ApplianceCustomizationsPost(BaseAuthContext(token))
+	request := api.ApplianceCustomizationsPost(authContext(ctx, token))
 	request = request.ApplianceCustomization(*args)
 
 	customization, _, err := request.Execute()
 	if err != nil {
-		return fmt.Errorf("Could not create Appliance customization %w", prettyPrintAPIError(err))
+		return apiErrorDiagnostics("Could not create Appliance customization", err, resourceAppgateApplianceCustomizations().Schema)
 	}
 
 	d.SetId(customization.GetId())
 	d.Set("appliance_customization_id", customization.GetId())
 
-	return resourceAppgateApplianceCustomizationRead(d, meta)
+	return resourceAppgateApplianceCustomizationRead(ctx, d, meta)
 }
 
-func resourceAppgateApplianceCustomizationRead(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateApplianceCustomizationRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Reading Appliance customization id: %+v", d.Id())
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.ApplianceCustomizationsApi
-	request := api.ApplianceCustomizationsIdGet(BaseAuthContext(token), d.Id())
+	request := api.ApplianceCustomizationsIdGet(authContext(ctx, token), d.Id())
 	customization, res, err := request.Execute()
 	if err != nil {
 		d.SetId("")
 		if res != nil && res.StatusCode == http.StatusNotFound {
 			return nil
 		}
-		return fmt.Errorf("Failed to read Appliance customization, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read Appliance customization, %w", err))
 	}
 	d.SetId(customization.GetId())
+	d.Set("etag", objectETag(customization))
 	d.Set("appliance_customization_id", customization.GetId())
 	if err := d.Set("name", customization.GetName()); err != nil {
-		return fmt.Errorf("Error setting name %w", err)
+		return diag.FromErr(fmt.Errorf("Error setting name %w", err))
 	}
 	if err := d.Set("notes", customization.GetNotes()); err != nil {
-		return fmt.Errorf("Error setting notes %w", err)
+		return diag.FromErr(fmt.Errorf("Error setting notes %w", err))
 	}
-	if err := d.Set("tags", customization.GetTags()); err != nil {
-		return fmt.Errorf("Error setting tags %w", err)
+	if err := setTags(d, meta, customization.GetTags()); err != nil {
+		return diag.FromErr(fmt.Errorf("Error setting tags %w", err))
 	}
 	if err := d.Set("size", customization.GetSize()); err != nil {
-		return fmt.Errorf("Error setting size %w", err)
+		return diag.FromErr(fmt.Errorf("Error setting size %w", err))
 	}
 	if err := d.Set("checksum_sha256", customization.GetChecksum()); err != nil {
-		return fmt.Errorf("Error setting checksum_sha256 %w", err)
+		return diag.FromErr(fmt.Errorf("Error setting checksum_sha256 %w", err))
 	}
 	if err := d.Set("detect_sha256", customization.GetChecksum()); err != nil {
-		return fmt.Errorf("Error setting detect_sha256: %w", err)
+		return diag.FromErr(fmt.Errorf("Error setting detect_sha256: %w", err))
 	}
 
 	return nil
 }
 
-func resourceAppgateApplianceCustomizationUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateApplianceCustomizationUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Updating Appliance customization: %s", d.Get("name").(string))
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.ApplianceCustomizationsApi
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	request := api.ApplianceCustomizationsIdGet(ctx, d.Id())
 	originalApplianceCustomization, _, err := request.Execute()
 	if err != nil {
-		return fmt.Errorf("Failed to read Appliance customization while updating, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read Appliance customization while updating, %w", err))
+	}
+	if diags := checkNotModified(d, meta, "Appliance customization", originalApplianceCustomization); diags.HasError() {
+		return diags
 	}
 
 	if d.HasChange("name") {
@@ -205,15 +214,15 @@ func resourceAppgateApplianceCustomizationUpdate(d *schema.ResourceData, meta in
 		originalApplianceCustomization.SetNotes(d.Get("notes").(string))
 	}
 
-	if d.HasChange("tags") {
-		originalApplianceCustomization.SetTags(schemaExtractTags(d))
+	if d.HasChanges("tags", "tags_all") {
+		originalApplianceCustomization.SetTags(schemaExtractTags(d, meta))
 	}
 
 	if v := d.Get("file").(string); len(v) > 0 && d.HasChange("detect_sha256") {
 		var content []byte
 		file, err := os.Open(v)
 		if err != nil {
-			return fmt.Errorf("Error opening file (%s): %w", v, err)
+			return diag.FromErr(fmt.Errorf("Error opening file (%s): %w", v, err))
 		}
 		defer func() {
 			err := file.Close()
@@ -224,7 +233,7 @@ func resourceAppgateApplianceCustomizationUpdate(d *schema.ResourceData, meta in
 		reader := bufio.NewReader(file)
 		content, err = io.ReadAll(reader)
 		if err != nil {
-			return fmt.Errorf("Error reading file (%s): %w", v, err)
+			return diag.FromErr(fmt.Errorf("Error reading file (%s): %w", v, err))
 		}
 		encoded := base64.StdEncoding.EncodeToString(content)
 		originalApplianceCustomization.SetFile(encoded)
@@ -234,22 +243,22 @@ func resourceAppgateApplianceCustomizationUpdate(d *schema.ResourceData, meta in
 	req = req.ApplianceCustomization(*originalApplianceCustomization)
 	_, _, err = req.Execute()
 	if err != nil {
-		return fmt.Errorf("Could not update Appliance customization %w", prettyPrintAPIError(err))
+		return apiErrorDiagnostics("Could not update Appliance customization", err, resourceAppgateApplianceCustomizations().Schema)
 	}
-	return resourceAppgateApplianceCustomizationRead(d, meta)
+	return resourceAppgateApplianceCustomizationRead(ctx, d, meta)
 }
 
-func resourceAppgateApplianceCustomizationDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateApplianceCustomizationDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Reading Appliance customization id: %+v", d.Id())
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.ApplianceCustomizationsApi
 
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	if _, err := api.ApplianceCustomizationsIdDelete(ctx, d.Id()).Execute(); err != nil {
-		return fmt.Errorf("Could not delete Appliance customization %w", prettyPrintAPIError(err))
+		return diag.FromErr(fmt.Errorf("Could not delete Appliance customization %w", prettyPrintAPIError(err)))
 	}
 	d.SetId("")
 	return nil
@@ -7,16 +7,17 @@ import (
 
 	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func resourceAppgateBlacklistUser() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceAppgateBlacklistUserCreate,
-		Read:   resourceAppgateBlacklistUserRead,
-		Delete: resourceAppgateBlacklistUserDelete,
+		CreateContext: resourceAppgateBlacklistUserCreate,
+		ReadContext:   resourceAppgateBlacklistUserRead,
+		DeleteContext: resourceAppgateBlacklistUserDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 		SchemaVersion: 1,
 		Schema: map[string]*schema.Schema{
@@ -48,11 +49,11 @@ func resourceAppgateBlacklistUser() *schema.Resource {
 	}
 }
 
-func resourceAppgateBlacklistUserCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateBlacklistUserCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Creating blacklisted user")
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.BlacklistedUsersApi
 	args := openapi.NewBlacklistEntryWithDefaults()
@@ -69,21 +70,21 @@ func resourceAppgateBlacklistUserCreate(d *schema.ResourceData, meta interface{}
 	if v, ok := d.GetOk("reason"); ok {
 		args.SetReason(v.(string))
 	}
-	request := api.BlacklistPost(BaseAuthContext(token))
+	request := api.BlacklistPost(authContext(ctx, token))
 	request = request.BlacklistEntry(*args)
 
 	entry, _, err := request.Execute()
 	if err != nil {
-		return fmt.Errorf("Could not create blacklisted user %w", prettyPrintAPIError(err))
+		return apiErrorDiagnostics("Could not create blacklisted user", err, resourceAppgateBlacklistUser().Schema)
 	}
 
 	d.SetId(entry.GetUserDistinguishedName())
 
-	return resourceAppgateBlacklistUserRead(d, meta)
+	return resourceAppgateBlacklistUserRead(ctx, d, meta)
 }
 
 func queryEntry(ctx context.Context, api *openapi.BlacklistedUsersApiService, token, distinguishedName string) (*openapi.BlacklistEntry, error) {
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	request := api.BlacklistGet(ctx)
 	list, _, err := request.Execute()
 	if err != nil {
@@ -97,16 +98,16 @@ func queryEntry(ctx context.Context, api *openapi.BlacklistedUsersApiService, to
 	return nil, fmt.Errorf("Failed to find blacklist user %s", distinguishedName)
 }
 
-func resourceAppgateBlacklistUserRead(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateBlacklistUserRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Reading blacklisted user id: %+v", d.Id())
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.BlacklistedUsersApi
-	entry, err := queryEntry(context.TODO(), api, token, d.Id())
+	entry, err := queryEntry(ctx, api, token, d.Id())
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.Set("user_distinguished_name", entry.GetUserDistinguishedName())
@@ -116,15 +117,15 @@ func resourceAppgateBlacklistUserRead(d *schema.ResourceData, meta interface{})
 	return nil
 }
 
-func resourceAppgateBlacklistUserDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateBlacklistUserDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Reading blacklisted user id: %+v", d.Id())
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.BlacklistedUsersApi
-	if _, err := api.BlacklistDistinguishedNameDelete(BaseAuthContext(token), d.Id()).Execute(); err != nil {
-		return fmt.Errorf("Could not delete blacklisted user %w", prettyPrintAPIError(err))
+	if _, err := api.BlacklistDistinguishedNameDelete(authContext(ctx, token), d.Id()).Execute(); err != nil {
+		return diag.FromErr(fmt.Errorf("Could not delete blacklisted user %w", prettyPrintAPIError(err)))
 	}
 	d.SetId("")
 	return nil
@@ -3,12 +3,13 @@ package appgate
 import (
 	"context"
 	"fmt"
-	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
 	"log"
 	"net/http"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -19,7 +20,7 @@ func resourceAppgateClientProfile() *schema.Resource {
 		UpdateContext: resourceAppgateClientProfileUpdate,
 		DeleteContext: resourceAppgateClientProfileDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 		SchemaVersion: 1,
 
@@ -28,12 +29,14 @@ func resourceAppgateClientProfile() *schema.Resource {
 			Delete: schema.DefaultTimeout(10 * time.Minute),
 		},
 
+		CustomizeDiff: customdiff.All(tagsCustomizeDiff, etagCustomizeDiff),
 		Schema: map[string]*schema.Schema{
 			"id": {
 				Type:     schema.TypeString,
 				Computed: true,
 				ForceNew: true,
 			},
+			"etag": etagSchema(),
 
 			"name": {
 				Type:     schema.TypeString,
@@ -49,6 +52,8 @@ func resourceAppgateClientProfile() *schema.Resource {
 
 			"tags": tagsSchema(),
 
+			"tags_all": tagsAllSchema(),
+
 			"spa_key_name": {
 				Type:     schema.TypeString,
 				Required: true,
@@ -85,9 +90,10 @@ func resourceAppgateClientProfileCreate(ctx context.Context, d *schema.ResourceD
 	log.Printf("[DEBUG] Create Client Profile %s", d.Get("name"))
 	api := meta.(*Client).API.ClientProfilesApi
 	args := make(map[string]interface{}, 0)
+	args["id"] = uuid.New().String()
 	args["name"] = d.Get("name").(string)
 	args["notes"] = d.Get("notes").(string)
-	args["tags"] = schemaExtractTags(d)
+	args["tags"] = schemaExtractTags(d, meta)
 	if v, ok := d.GetOk("spa_key_name"); ok {
 		args["spaKeyName"] = v.(string)
 	}
@@ -100,10 +106,10 @@ func resourceAppgateClientProfileCreate(ctx context.Context, d *schema.ResourceD
 	if v, ok := d.GetOk("hostname"); ok {
 		args["hostname"] = v.(string)
 	}
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	profile, _, err := api.ClientProfilesPost(ctx).Body(args).Execute()
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("Could not create client profile %s", prettyPrintAPIError(err)))
+		return apiErrorDiagnostics("Could not create client profile", err, resourceAppgateClientProfile().Schema)
 	}
 	d.SetId(profile.GetId())
 	return resourceAppgateClientProfileRead(ctx, d, meta)
@@ -117,7 +123,7 @@ func resourceAppgateClientProfileRead(ctx context.Context, d *schema.ResourceDat
 	}
 	log.Printf("[DEBUG] Reading Client Profile id: %+v", d.Id())
 	api := meta.(*Client).API.ClientProfilesApi
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	profile, res, err := api.ClientProfilesIdGet(ctx, d.Id()).Execute()
 	if err != nil {
 		d.SetId("")
@@ -130,6 +136,7 @@ func resourceAppgateClientProfileRead(ctx context.Context, d *schema.ResourceDat
 		}
 		return diag.FromErr(fmt.Errorf("Failed to read client profile, %s", err))
 	}
+	d.Set("etag", objectETag(profile))
 
 	id, ok := profile["id"].(string)
 	if ok {
@@ -153,8 +160,17 @@ func resourceAppgateClientProfileRead(ctx context.Context, d *schema.ResourceDat
 	if exported, ok := profile["exported"].(string); ok {
 		d.Set("exported", exported)
 	}
+	if raw, ok := profile["tags"].([]interface{}); ok {
+		tags := make([]string, 0, len(raw))
+		for _, t := range raw {
+			if tag, ok := t.(string); ok {
+				tags = append(tags, tag)
+			}
+		}
+		setTags(d, meta, tags)
+	}
 
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	url, _, err := api.ClientProfilesIdUrlGet(ctx, id).Execute()
 	if err != nil {
 		diags = AppendFromErr(diags, err)
@@ -175,14 +191,16 @@ func resourceAppgateClientProfileUpdate(ctx context.Context, d *schema.ResourceD
 		return nil
 	}
 	log.Printf("[DEBUG] Updating client profile id: %+v", d.Id())
-	var diags diag.Diagnostics
 
 	api := meta.(*Client).API.ClientProfilesApi
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	originalProfile, _, err := api.ClientProfilesIdGet(ctx, d.Id()).Execute()
 	if err != nil {
 		return diag.FromErr(fmt.Errorf("Failed to read profile while updating, %w", err))
 	}
+	if diags := checkNotModified(d, meta, "Client profile", originalProfile); diags.HasError() {
+		return diags
+	}
 
 	if d.HasChange("name") {
 		originalProfile["name"] = d.Get("name").(string)
@@ -191,8 +209,8 @@ func resourceAppgateClientProfileUpdate(ctx context.Context, d *schema.ResourceD
 		originalProfile["notes"] = d.Get("notes").(string)
 	}
 
-	if d.HasChange("tags") {
-		originalProfile["tags"] = schemaExtractTags(d)
+	if d.HasChanges("tags", "tags_all") {
+		originalProfile["tags"] = schemaExtractTags(d, meta)
 	}
 	if d.HasChange("spa_key_name") {
 		originalProfile["spa_key_name"] = d.Get("spa_key_name").(string)
@@ -203,12 +221,12 @@ func resourceAppgateClientProfileUpdate(ctx context.Context, d *schema.ResourceD
 	if d.HasChange("hostname") {
 		originalProfile["hostname"] = d.Get("hostname").(string)
 	}
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	if _, _, err := api.ClientProfilesIdPut(ctx, d.Id()).Body(originalProfile).Execute(); err != nil {
-		return diag.FromErr(fmt.Errorf("Could not update client profile %w", prettyPrintAPIError(err)))
+		return apiErrorDiagnostics("Could not update client profile", err, resourceAppgateClientProfile().Schema)
 
 	}
-	return diags
+	return resourceAppgateClientProfileRead(ctx, d, meta)
 }
 
 func resourceAppgateClientProfileDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -218,7 +236,7 @@ func resourceAppgateClientProfileDelete(ctx context.Context, d *schema.ResourceD
 	}
 	log.Printf("[DEBUG] Delete client profile %+v", d.Id())
 	api := meta.(*Client).API.ClientProfilesApi
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	if _, err := api.ClientProfilesIdDelete(ctx, d.Id()).Execute(); err != nil {
 		return diag.FromErr(fmt.Errorf("Could not delete client profile %w", prettyPrintAPIError(err)))
 	}
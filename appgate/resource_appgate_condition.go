@@ -1,23 +1,27 @@
 package appgate
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"time"
 
 	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func resourceAppgateCondition() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceAppgateConditionCreate,
-		Read:   resourceAppgateConditionRead,
-		Update: resourceAppgateConditionUpdate,
-		Delete: resourceAppgateConditionDelete,
+		CreateContext: resourceAppgateConditionCreate,
+		ReadContext:   resourceAppgateConditionRead,
+		UpdateContext: resourceAppgateConditionUpdate,
+		DeleteContext: resourceAppgateConditionDelete,
+		CustomizeDiff: customdiff.All(tagsCustomizeDiff, etagCustomizeDiff),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: importStateByNameOrTag("condition", listConditionImportCandidates),
 		},
 
 		Timeouts: &schema.ResourceTimeout{
@@ -30,6 +34,7 @@ func resourceAppgateCondition() *schema.Resource {
 		Schema: map[string]*schema.Schema{
 
 			"condition_id": resourceUUID(),
+			"etag":         etagSchema(),
 
 			"name": {
 				Type:        schema.TypeString,
@@ -46,6 +51,8 @@ func resourceAppgateCondition() *schema.Resource {
 
 			"tags": tagsSchema(),
 
+			"tags_all": tagsAllSchema(),
+
 			"expression": {
 				Type:        schema.TypeString,
 				Description: "Boolean expression in JavaScript.",
@@ -119,25 +126,23 @@ func resourceAppgateCondition() *schema.Resource {
 	}
 }
 
-func resourceAppgateConditionCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateConditionCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Creating Condition with name: %s", d.Get("name").(string))
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.ConditionsApi
 
 	args := openapi.Condition{}
-	if v, ok := d.GetOk("condition_id"); ok {
-		args.SetId(v.(string))
-	}
+	args.SetId(resourceObjectID(d, "condition_id"))
 	args.SetName(d.Get("name").(string))
 
 	if c, ok := d.GetOk("notes"); ok {
 		args.SetNotes(c.(string))
 	}
 
-	args.SetTags(schemaExtractTags(d))
+	args.SetTags(schemaExtractTags(d, meta))
 
 	if v, ok := d.GetOk("expression"); ok {
 		args.SetExpression(v.(string))
@@ -150,7 +155,7 @@ func resourceAppgateConditionCreate(d *schema.ResourceData, meta interface{}) er
 	if c, ok := d.GetOk("repeat_schedules"); ok {
 		repeatSchedules, err := readArrayOfStringsFromConfig(c.(*schema.Set).List())
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		args.SetRepeatSchedules(repeatSchedules)
 	}
@@ -158,31 +163,31 @@ func resourceAppgateConditionCreate(d *schema.ResourceData, meta interface{}) er
 	if v, ok := d.GetOk("remedy_methods"); ok {
 		remedyMethods, err := readRemedyMethodsFromConfig(v.([]interface{}))
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		args.SetRemedyMethods(remedyMethods)
 	}
 
-	request := api.ConditionsPost(BaseAuthContext(token))
+	request := api.ConditionsPost(authContext(ctx, token))
 	request = request.Condition(args)
 	condition, _, err := request.Execute()
 	if err != nil {
-		return fmt.Errorf("Could not create condition %w", prettyPrintAPIError(err))
+		return apiErrorDiagnostics("Could not create condition", err, resourceAppgateCondition().Schema)
 	}
 
 	d.SetId(condition.GetId())
 
-	return resourceAppgateConditionRead(d, meta)
+	return resourceAppgateConditionRead(ctx, d, meta)
 }
 
-func resourceAppgateConditionRead(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateConditionRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Reading Condition Name: %s", d.Get("name").(string))
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.ConditionsApi
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	request := api.ConditionsIdGet(ctx, d.Id())
 	remoteCondition, res, err := request.Execute()
 	if err != nil {
@@ -190,19 +195,20 @@ func resourceAppgateConditionRead(d *schema.ResourceData, meta interface{}) erro
 		if res != nil && res.StatusCode == http.StatusNotFound {
 			return nil
 		}
-		return fmt.Errorf("Failed to read Condition, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read Condition, %w", err))
 	}
 	d.SetId(remoteCondition.GetId())
 	d.Set("condition_id", remoteCondition.Id)
+	d.Set("etag", objectETag(remoteCondition))
 	d.Set("name", remoteCondition.Name)
 	d.Set("notes", remoteCondition.Notes)
-	d.Set("tags", remoteCondition.Tags)
+	setTags(d, meta, remoteCondition.Tags)
 	d.Set("expression", remoteCondition.Expression)
 	d.Set("remedy_logic", remoteCondition.GetRemedyLogic())
 	d.Set("repeat_schedules", remoteCondition.RepeatSchedules)
 	if remoteCondition.RemedyMethods != nil {
 		if err = d.Set("remedy_methods", flattenConditionRemedyMethods(remoteCondition.RemedyMethods)); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 	return nil
@@ -222,18 +228,21 @@ func flattenConditionRemedyMethods(in []openapi.RemedyMethod) []map[string]inter
 	return out
 }
 
-func resourceAppgateConditionUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateConditionUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Updating condition: %s", d.Get("name").(string))
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.ConditionsApi
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	request := api.ConditionsIdGet(ctx, d.Id())
 	orginalCondition, _, err := request.Execute()
 	if err != nil {
-		return fmt.Errorf("Failed to read condition, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read condition, %w", err))
+	}
+	if diags := checkNotModified(d, meta, "Condition", orginalCondition); diags.HasError() {
+		return diags
 	}
 	if d.HasChange("name") {
 		orginalCondition.SetName(d.Get("name").(string))
@@ -243,8 +252,8 @@ func resourceAppgateConditionUpdate(d *schema.ResourceData, meta interface{}) er
 		orginalCondition.SetNotes(d.Get("notes").(string))
 	}
 
-	if d.HasChange("tags") {
-		orginalCondition.SetTags(schemaExtractTags(d))
+	if d.HasChanges("tags", "tags_all") {
+		orginalCondition.SetTags(schemaExtractTags(d, meta))
 	}
 
 	if d.HasChange("expression") {
@@ -258,7 +267,7 @@ func resourceAppgateConditionUpdate(d *schema.ResourceData, meta interface{}) er
 		_, n := d.GetChange("repeat_schedules")
 		repeatSchedules, err := readArrayOfStringsFromConfig(n.(*schema.Set).List())
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		orginalCondition.SetRepeatSchedules(repeatSchedules)
 	}
@@ -267,7 +276,7 @@ func resourceAppgateConditionUpdate(d *schema.ResourceData, meta interface{}) er
 		_, n := d.GetChange("remedy_methods")
 		remedyMethods, err := readRemedyMethodsFromConfig(n.([]interface{}))
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		orginalCondition.SetRemedyMethods(remedyMethods)
 	}
@@ -275,32 +284,32 @@ func resourceAppgateConditionUpdate(d *schema.ResourceData, meta interface{}) er
 	req := api.ConditionsIdPut(ctx, d.Id())
 	_, _, err = req.Condition(*orginalCondition).Execute()
 	if err != nil {
-		return fmt.Errorf("Could not update condition %w", prettyPrintAPIError(err))
+		return apiErrorDiagnostics("Could not update condition", err, resourceAppgateCondition().Schema)
 	}
 
-	return resourceAppgateConditionRead(d, meta)
+	return resourceAppgateConditionRead(ctx, d, meta)
 }
 
-func resourceAppgateConditionDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateConditionDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Delete condition with name: %s", d.Get("name").(string))
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.ConditionsApi
 
 	// Get condition
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	request := api.ConditionsIdGet(ctx, d.Id())
 	condition, _, err := request.Execute()
 	if err != nil {
-		return fmt.Errorf("Failed to delete condition while GET, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to delete condition while GET, %w", err))
 	}
 
 	deleteRequest := api.ConditionsIdDelete(ctx, condition.GetId())
 	_, err = deleteRequest.Execute()
 	if err != nil {
-		return fmt.Errorf("Failed to delete condition, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to delete condition, %w", err))
 	}
 	d.SetId("")
 	return nil
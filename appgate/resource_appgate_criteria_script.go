@@ -1,6 +1,7 @@
 package appgate
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -8,17 +9,19 @@ import (
 
 	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func resourceAppgateCriteriaScript() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceAppgateCriteriaScriptCreate,
-		Read:   resourceAppgateCriteriaScriptRead,
-		Update: resourceAppgateCriteriaScriptUpdate,
-		Delete: resourceAppgateCriteriaScriptDelete,
+		CreateContext: resourceAppgateCriteriaScriptCreate,
+		ReadContext:   resourceAppgateCriteriaScriptRead,
+		UpdateContext: resourceAppgateCriteriaScriptUpdate,
+		DeleteContext: resourceAppgateCriteriaScriptDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: importStateByNameOrTag("criteria script", listCriteriaScriptImportCandidates),
 		},
 
 		Timeouts: &schema.ResourceTimeout{
@@ -28,9 +31,11 @@ func resourceAppgateCriteriaScript() *schema.Resource {
 		},
 
 		SchemaVersion: 1,
+		CustomizeDiff: customdiff.All(tagsCustomizeDiff, etagCustomizeDiff),
 		Schema: map[string]*schema.Schema{
 
 			"criteria_script_id": resourceUUID(),
+			"etag":               etagSchema(),
 
 			"name": {
 				Type:        schema.TypeString,
@@ -47,6 +52,8 @@ func resourceAppgateCriteriaScript() *schema.Resource {
 
 			"tags": tagsSchema(),
 
+			"tags_all": tagsAllSchema(),
+
 			"expression": {
 				Type:        schema.TypeString,
 				Description: "A JavaScript expression that returns boolean.",
@@ -56,47 +63,45 @@ func resourceAppgateCriteriaScript() *schema.Resource {
 	}
 }
 
-func resourceAppgateCriteriaScriptCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateCriteriaScriptCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Creating Criteria script: %s", d.Get("name").(string))
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.CriteriaScriptsApi
 	args := openapi.NewCriteriaScriptWithDefaults()
-	if v, ok := d.GetOk("criteria_script_id"); ok {
-		args.SetId(v.(string))
-	}
+	args.SetId(resourceObjectID(d, "criteria_script_id"))
 	args.SetName(d.Get("name").(string))
 	args.SetNotes(d.Get("notes").(string))
-	args.SetTags(schemaExtractTags(d))
+	args.SetTags(schemaExtractTags(d, meta))
 
 	if v, ok := d.GetOk("expression"); ok {
 		args.SetExpression(v.(string))
 	}
 
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	request := api.CriteriaScriptsPost(ctx)
 	request = request.CriteriaScript(*args)
 	criteraScript, _, err := request.Execute()
 	if err != nil {
-		return fmt.Errorf("Could not create Criteria script %w", prettyPrintAPIError(err))
+		return apiErrorDiagnostics("Could not create Criteria script", err, resourceAppgateCriteriaScript().Schema)
 	}
 
 	d.SetId(criteraScript.GetId())
 	d.Set("criteria_script_id", criteraScript.GetId())
 
-	return resourceAppgateCriteriaScriptRead(d, meta)
+	return resourceAppgateCriteriaScriptRead(ctx, d, meta)
 }
 
-func resourceAppgateCriteriaScriptRead(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateCriteriaScriptRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Reading Criteria script id: %+v", d.Id())
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.CriteriaScriptsApi
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	request := api.CriteriaScriptsIdGet(ctx, d.Id())
 	criteraScript, res, err := request.Execute()
 	if err != nil {
@@ -104,31 +109,35 @@ func resourceAppgateCriteriaScriptRead(d *schema.ResourceData, meta interface{})
 		if res != nil && res.StatusCode == http.StatusNotFound {
 			return nil
 		}
-		return fmt.Errorf("Failed to read Criteria script, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read Criteria script, %w", err))
 	}
 	d.SetId(criteraScript.GetId())
+	d.Set("etag", objectETag(criteraScript))
 	d.Set("criteria_script_id", criteraScript.GetId())
 	d.Set("name", criteraScript.GetName())
 	d.Set("notes", criteraScript.GetNotes())
-	d.Set("tags", criteraScript.GetTags())
+	setTags(d, meta, criteraScript.GetTags())
 	d.Set("expression", criteraScript.GetExpression())
 
 	return nil
 }
 
-func resourceAppgateCriteriaScriptUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateCriteriaScriptUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Updating Criteria script: %s", d.Get("name").(string))
 	log.Printf("[DEBUG] Updating Criteria script id: %+v", d.Id())
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.CriteriaScriptsApi
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	request := api.CriteriaScriptsIdGet(ctx, d.Id())
 	originalCriteriaScript, _, err := request.Execute()
 	if err != nil {
-		return fmt.Errorf("Failed to read Criteria script while updating, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read Criteria script while updating, %w", err))
+	}
+	if diags := checkNotModified(d, meta, "Criteria script", originalCriteriaScript); diags.HasError() {
+		return diags
 	}
 
 	if d.HasChange("name") {
@@ -139,8 +148,8 @@ func resourceAppgateCriteriaScriptUpdate(d *schema.ResourceData, meta interface{
 		originalCriteriaScript.SetNotes(d.Get("notes").(string))
 	}
 
-	if d.HasChange("tags") {
-		originalCriteriaScript.SetTags(schemaExtractTags(d))
+	if d.HasChanges("tags", "tags_all") {
+		originalCriteriaScript.SetTags(schemaExtractTags(d, meta))
 	}
 
 	if d.HasChange("expression") {
@@ -151,21 +160,21 @@ func resourceAppgateCriteriaScriptUpdate(d *schema.ResourceData, meta interface{
 	req = req.CriteriaScript(*originalCriteriaScript)
 	_, _, err = req.Execute()
 	if err != nil {
-		return fmt.Errorf("Could not update Criteria script %w", prettyPrintAPIError(err))
+		return apiErrorDiagnostics("Could not update Criteria script", err, resourceAppgateCriteriaScript().Schema)
 	}
-	return resourceAppgateCriteriaScriptRead(d, meta)
+	return resourceAppgateCriteriaScriptRead(ctx, d, meta)
 }
 
-func resourceAppgateCriteriaScriptDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateCriteriaScriptDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Delete Criteria script: %s", d.Get("name").(string))
 	log.Printf("[DEBUG] Reading Criteria script id: %+v", d.Id())
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.CriteriaScriptsApi
-	if _, err := api.CriteriaScriptsIdDelete(BaseAuthContext(token), d.Id()).Execute(); err != nil {
-		return fmt.Errorf("Could not delete Criteria script %w", prettyPrintAPIError(err))
+	if _, err := api.CriteriaScriptsIdDelete(authContext(ctx, token), d.Id()).Execute(); err != nil {
+		return diag.FromErr(fmt.Errorf("Could not delete Criteria script %w", prettyPrintAPIError(err)))
 	}
 	d.SetId("")
 	return nil
@@ -11,9 +11,9 @@ func TestAccPolicyDeviceBasic(t *testing.T) {
 	resourceName := "appgatesdp_device_policy.test_device_policy"
 	rName := RandStringFromCharSet(10, CharSetAlphaNum)
 	resource.Test(t, resource.TestCase{
-		PreCheck:     func() { testAccPreCheck(t) },
-		Providers:    testAccProviders,
-		CheckDestroy: testAccCheckPolicyDestroy,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckPolicyDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config: testAccCheckPolicyDeviceBasic(rName),
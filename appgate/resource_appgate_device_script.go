@@ -1,6 +1,7 @@
 package appgate
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"log"
@@ -8,17 +9,19 @@ import (
 	"time"
 
 	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func resourceAppgateDeviceScript() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceAppgateDeviceScriptCreate,
-		Read:   resourceAppgateDeviceScriptRead,
-		Update: resourceAppgateDeviceScriptUpdate,
-		Delete: resourceAppgateDeviceScriptDelete,
+		CreateContext: resourceAppgateDeviceScriptCreate,
+		ReadContext:   resourceAppgateDeviceScriptRead,
+		UpdateContext: resourceAppgateDeviceScriptUpdate,
+		DeleteContext: resourceAppgateDeviceScriptDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: importStateByNameOrTag("device script", listDeviceScriptImportCandidates),
 		},
 
 		Timeouts: &schema.ResourceTimeout{
@@ -28,9 +31,11 @@ func resourceAppgateDeviceScript() *schema.Resource {
 		},
 
 		SchemaVersion: 1,
+		CustomizeDiff: customdiff.All(tagsCustomizeDiff, etagCustomizeDiff),
 		Schema: map[string]*schema.Schema{
 
 			"device_script_id": resourceUUID(),
+			"etag":             etagSchema(),
 
 			"name": {
 				Type:        schema.TypeString,
@@ -47,6 +52,8 @@ func resourceAppgateDeviceScript() *schema.Resource {
 
 			"tags": tagsSchema(),
 
+			"tags_all": tagsAllSchema(),
+
 			"filename": {
 				Type:        schema.TypeString,
 				Description: "The name of the file to be downloaded as to the client devices.",
@@ -75,53 +82,51 @@ func resourceAppgateDeviceScript() *schema.Resource {
 	}
 }
 
-func resourceAppgateDeviceScriptCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateDeviceScriptCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Creating Device script: %s", d.Get("name").(string))
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.DeviceClaimScriptsApi
 	args := openapi.NewDeviceScriptWithDefaults()
-	if v, ok := d.GetOk("device_script_id"); ok {
-		args.SetId(v.(string))
-	}
+	args.SetId(resourceObjectID(d, "device_script_id"))
 	args.SetName(d.Get("name").(string))
 	args.SetNotes(d.Get("notes").(string))
 	args.SetFilename(d.Get("filename").(string))
-	args.SetTags(schemaExtractTags(d))
+	args.SetTags(schemaExtractTags(d, meta))
 
 	content, err := getResourceFileContent(d, "file")
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	encoded := base64.StdEncoding.EncodeToString(content)
 	args.SetFile(encoded)
 
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	request := api.DeviceScriptsPost(ctx)
 	request = request.DeviceScript(*args)
 
 	deviceScript, _, err := request.Execute()
 	if err != nil {
-		return fmt.Errorf("Could not create Device script %w", prettyPrintAPIError(err))
+		return apiErrorDiagnostics("Could not create Device script", err, resourceAppgateDeviceScript().Schema)
 	}
 
 	d.SetId(deviceScript.GetId())
 	d.Set("device_script_id", deviceScript.GetId())
 
-	return resourceAppgateDeviceScriptRead(d, meta)
+	return resourceAppgateDeviceScriptRead(ctx, d, meta)
 }
 
-func resourceAppgateDeviceScriptRead(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateDeviceScriptRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Reading Device script id: %+v", d.Id())
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.DeviceClaimScriptsApi
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	request := api.DeviceScriptsIdGet(ctx, d.Id())
 	deviceScript, res, err := request.Execute()
 	if err != nil {
@@ -129,31 +134,35 @@ func resourceAppgateDeviceScriptRead(d *schema.ResourceData, meta interface{}) e
 		if res != nil && res.StatusCode == http.StatusNotFound {
 			return nil
 		}
-		return fmt.Errorf("Failed to read Device script, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read Device script, %w", err))
 	}
 	d.SetId(deviceScript.GetId())
+	d.Set("etag", objectETag(deviceScript))
 	d.Set("device_script_id", deviceScript.GetId())
 	d.Set("name", deviceScript.GetName())
 	d.Set("notes", deviceScript.GetNotes())
-	d.Set("tags", deviceScript.GetTags())
+	setTags(d, meta, deviceScript.GetTags())
 	d.Set("checksum_sha256", deviceScript.GetChecksumSha256())
 
 	return nil
 }
 
-func resourceAppgateDeviceScriptUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateDeviceScriptUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Updating Device script: %s", d.Get("name").(string))
 	log.Printf("[DEBUG] Updating Device script id: %+v", d.Id())
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.DeviceClaimScriptsApi
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	request := api.DeviceScriptsIdGet(ctx, d.Id())
 	originalDeviceScript, _, err := request.Execute()
 	if err != nil {
-		return fmt.Errorf("Failed to read Device script while updating, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read Device script while updating, %w", err))
+	}
+	if diags := checkNotModified(d, meta, "Device script", originalDeviceScript); diags.HasError() {
+		return diags
 	}
 
 	if d.HasChange("name") {
@@ -164,14 +173,14 @@ func resourceAppgateDeviceScriptUpdate(d *schema.ResourceData, meta interface{})
 		originalDeviceScript.SetNotes(d.Get("notes").(string))
 	}
 
-	if d.HasChange("tags") {
-		originalDeviceScript.SetTags(schemaExtractTags(d))
+	if d.HasChanges("tags", "tags_all") {
+		originalDeviceScript.SetTags(schemaExtractTags(d, meta))
 	}
 
 	if d.HasChange("file") || d.HasChange("content") {
 		content, err := getResourceFileContent(d, "file")
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 
 		encoded := base64.StdEncoding.EncodeToString(content)
@@ -182,22 +191,22 @@ func resourceAppgateDeviceScriptUpdate(d *schema.ResourceData, meta interface{})
 	req = req.DeviceScript(*originalDeviceScript)
 	_, _, err = req.Execute()
 	if err != nil {
-		return fmt.Errorf("Could not update Device script %w", prettyPrintAPIError(err))
+		return apiErrorDiagnostics("Could not update Device script", err, resourceAppgateDeviceScript().Schema)
 	}
-	return resourceAppgateDeviceScriptRead(d, meta)
+	return resourceAppgateDeviceScriptRead(ctx, d, meta)
 }
 
-func resourceAppgateDeviceScriptDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateDeviceScriptDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Delete Device script: %s", d.Get("name").(string))
 	log.Printf("[DEBUG] Reading Device script id: %+v", d.Id())
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.DeviceClaimScriptsApi
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	if _, err := api.DeviceScriptsIdDelete(ctx, d.Id()).Execute(); err != nil {
-		return fmt.Errorf("Could not delete Device script %w", prettyPrintAPIError(err))
+		return diag.FromErr(fmt.Errorf("Could not delete Device script %w", prettyPrintAPIError(err)))
 	}
 	d.SetId("")
 	return nil
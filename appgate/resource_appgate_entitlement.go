@@ -13,8 +13,8 @@ import (
 	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
 	"github.com/appgate/terraform-provider-appgatesdp/appgate/hashcode"
 
-	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -24,8 +24,9 @@ func resourceAppgateEntitlement() *schema.Resource {
 		ReadContext:   resourceAppgateEntitlementRuleRead,
 		UpdateContext: resourceAppgateEntitlementRuleUpdate,
 		DeleteContext: resourceAppgateEntitlementRuleDelete,
+		CustomizeDiff: customdiff.All(tagsCustomizeDiff, etagCustomizeDiff, attributeVersionCustomizeDiff("appgatesdp_entitlement")),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: importStateByNameOrTag("entitlement", listEntitlementImportCandidates),
 		},
 
 		Timeouts: &schema.ResourceTimeout{
@@ -37,6 +38,7 @@ func resourceAppgateEntitlement() *schema.Resource {
 		Schema: map[string]*schema.Schema{
 
 			"entitlement_id": resourceUUID(),
+			"etag":           etagSchema(),
 
 			"name": {
 				Type:        schema.TypeString,
@@ -53,6 +55,8 @@ func resourceAppgateEntitlement() *schema.Resource {
 
 			"tags": tagsSchema(),
 
+			"tags_all": tagsAllSchema(),
+
 			"disabled": {
 				Type:     schema.TypeBool,
 				Default:  false,
@@ -284,16 +288,13 @@ func resourceAppgateEntitlementRuleCreate(ctx context.Context, d *schema.Resourc
 		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.EntitlementsApi
-	currentVersion := meta.(*Client).ApplianceVersion
 
 	args := openapi.NewEntitlementWithDefaults()
-	if v, ok := d.GetOk("entitlement_id"); ok {
-		args.SetId(v.(string))
-	}
+	args.SetId(resourceObjectID(d, "entitlement_id"))
 	args.SetName(d.Get("name").(string))
 	args.SetSite(d.Get("site").(string))
 	args.SetNotes(d.Get("notes").(string))
-	args.SetTags(schemaExtractTags(d))
+	args.SetTags(schemaExtractTags(d, meta))
 	args.SetDisabled(d.Get("disabled").(bool))
 
 	if v, ok := d.GetOk("risk_sensitivity"); ok {
@@ -313,7 +314,7 @@ func resourceAppgateEntitlementRuleCreate(ctx context.Context, d *schema.Resourc
 	}
 
 	if v, ok := d.GetOk("actions"); ok {
-		actions, _, err := readEntitlmentActionsFromConfig(v.(*schema.Set).List(), diags, currentVersion)
+		actions, _, err := readEntitlmentActionsFromConfig(v.(*schema.Set).List(), diags)
 		if err != nil {
 			return diag.FromErr(err)
 		}
@@ -335,10 +336,10 @@ func resourceAppgateEntitlementRuleCreate(ctx context.Context, d *schema.Resourc
 		}
 		args.SetAppShortcutScripts(scripts)
 	}
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	ent, _, err := api.EntitlementsPost(ctx).Entitlement(*args).Execute()
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("Could not create entitlement %w", prettyPrintAPIError(err)))
+		return apiErrorDiagnostics("Could not create entitlement", err, resourceAppgateEntitlement().Schema)
 	}
 
 	d.SetId(ent.GetId())
@@ -360,7 +361,7 @@ func resourceAppgateEntitlementRuleRead(ctx context.Context, d *schema.ResourceD
 	}
 	api := meta.(*Client).API.EntitlementsApi
 
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	request := api.EntitlementsIdGet(ctx, d.Id())
 	entitlement, res, err := request.Execute()
 	if err != nil {
@@ -372,6 +373,7 @@ func resourceAppgateEntitlementRuleRead(ctx context.Context, d *schema.ResourceD
 	}
 	d.SetId(entitlement.GetId())
 	d.Set("entitlement_id", entitlement.GetId())
+	d.Set("etag", objectETag(entitlement))
 	d.Set("name", entitlement.GetName())
 	d.Set("disabled", entitlement.GetDisabled())
 	d.Set("notes", entitlement.GetNotes())
@@ -381,7 +383,7 @@ func resourceAppgateEntitlementRuleRead(ctx context.Context, d *schema.ResourceD
 		d.Set("risk_sensitivity", *v)
 	}
 
-	d.Set("tags", entitlement.GetTags())
+	setTags(d, meta, entitlement.GetTags())
 	d.Set("site", entitlement.GetSite())
 	if entitlement.AppShortcuts != nil {
 		if err = d.Set("app_shortcuts", flattenEntitlementAppShortcut(entitlement.GetAppShortcuts())); err != nil {
@@ -472,8 +474,7 @@ func resourceAppgateEntitlementRuleUpdate(ctx context.Context, d *schema.Resourc
 		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.EntitlementsApi
-	currentVersion := meta.(*Client).ApplianceVersion
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	request := api.EntitlementsIdGet(ctx, d.Id())
 	orginalEntitlment, response, err := request.Execute()
 	if err != nil {
@@ -483,6 +484,9 @@ func resourceAppgateEntitlementRuleUpdate(ctx context.Context, d *schema.Resourc
 		}
 		return diag.FromErr(fmt.Errorf("Failed to read Entitlement while updating, %w", err))
 	}
+	if diags := checkNotModified(d, meta, "Entitlement", orginalEntitlment); diags.HasError() {
+		return diags
+	}
 
 	if d.HasChange("name") {
 		orginalEntitlment.SetName(d.Get("name").(string))
@@ -492,8 +496,8 @@ func resourceAppgateEntitlementRuleUpdate(ctx context.Context, d *schema.Resourc
 		orginalEntitlment.SetNotes(d.Get("notes").(string))
 	}
 
-	if d.HasChange("tags") {
-		orginalEntitlment.SetTags(schemaExtractTags(d))
+	if d.HasChanges("tags", "tags_all") {
+		orginalEntitlment.SetTags(schemaExtractTags(d, meta))
 	}
 
 	if d.HasChange("disabled") {
@@ -523,7 +527,7 @@ func resourceAppgateEntitlementRuleUpdate(ctx context.Context, d *schema.Resourc
 
 	if d.HasChange("actions") {
 		_, v := d.GetChange("actions")
-		actions, _, err := readEntitlmentActionsFromConfig(v.(*schema.Set).List(), diags, currentVersion)
+		actions, _, err := readEntitlmentActionsFromConfig(v.(*schema.Set).List(), diags)
 		if err != nil {
 			return diag.FromErr(err)
 		}
@@ -548,12 +552,12 @@ func resourceAppgateEntitlementRuleUpdate(ctx context.Context, d *schema.Resourc
 		orginalEntitlment.SetAppShortcutScripts(scripts)
 	}
 
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	req := api.EntitlementsIdPut(ctx, d.Id())
 	req = req.Entitlement(*orginalEntitlment)
 	_, _, err = req.Execute()
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("Could not update Entitlement %w", prettyPrintAPIError(err)))
+		return apiErrorDiagnostics("Could not update Entitlement", err, resourceAppgateEntitlement().Schema)
 	}
 
 	return resourceAppgateEntitlementRuleRead(ctx, d, meta)
@@ -569,7 +573,7 @@ func resourceAppgateEntitlementRuleDelete(ctx context.Context, d *schema.Resourc
 		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.EntitlementsApi
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	if _, err := api.EntitlementsIdDelete(ctx, d.Id()).Execute(); err != nil {
 		return diag.FromErr(fmt.Errorf("Could not delete Entitlement %w", prettyPrintAPIError(err)))
 	}
@@ -577,7 +581,7 @@ func resourceAppgateEntitlementRuleDelete(ctx context.Context, d *schema.Resourc
 	return diags
 }
 
-func readEntitlmentActionsFromConfig(actions []interface{}, diags diag.Diagnostics, current *version.Version) ([]openapi.EntitlementAllOfActions, diag.Diagnostics, error) {
+func readEntitlmentActionsFromConfig(actions []interface{}, diags diag.Diagnostics) ([]openapi.EntitlementAllOfActions, diag.Diagnostics, error) {
 	result := make([]openapi.EntitlementAllOfActions, 0)
 	for _, action := range actions {
 		if action == nil {
@@ -619,15 +623,12 @@ func readEntitlmentActionsFromConfig(actions []interface{}, diags diag.Diagnosti
 			}
 			a.SetTypes(types)
 		}
-		if current.GreaterThanOrEqual(Appliance61Version) {
-			if v, ok := raw["methods"]; ok {
-				methods, err := readArrayOfStringsFromConfig(v.(*schema.Set).List())
-				if err != nil {
-					return result, diags, fmt.Errorf("Failed to resolve entitlement action hosts: %w", err)
-				}
-				a.SetMethods(methods)
+		if v, ok := raw["methods"]; ok {
+			methods, err := readArrayOfStringsFromConfig(v.(*schema.Set).List())
+			if err != nil {
+				return result, diags, fmt.Errorf("Failed to resolve entitlement action hosts: %w", err)
 			}
-
+			a.SetMethods(methods)
 		}
 		if v, ok := raw["monitor"].([]interface{}); ok && len(v) > 0 {
 			monitor := openapi.NewEntitlementAllOfMonitorWithDefaults()
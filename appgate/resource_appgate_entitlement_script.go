@@ -1,6 +1,7 @@
 package appgate
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -8,17 +9,19 @@ import (
 
 	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func resourceAppgateEntitlementScript() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceAppgateEntitlementScriptCreate,
-		Read:   resourceAppgateEntitlementScriptRead,
-		Update: resourceAppgateEntitlementScriptUpdate,
-		Delete: resourceAppgateEntitlementScriptDelete,
+		CreateContext: resourceAppgateEntitlementScriptCreate,
+		ReadContext:   resourceAppgateEntitlementScriptRead,
+		UpdateContext: resourceAppgateEntitlementScriptUpdate,
+		DeleteContext: resourceAppgateEntitlementScriptDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: importStateByNameOrTag("entitlement script", listEntitlementScriptImportCandidates),
 		},
 
 		Timeouts: &schema.ResourceTimeout{
@@ -28,9 +31,11 @@ func resourceAppgateEntitlementScript() *schema.Resource {
 		},
 
 		SchemaVersion: 1,
+		CustomizeDiff: customdiff.All(tagsCustomizeDiff, etagCustomizeDiff),
 		Schema: map[string]*schema.Schema{
 
 			"entitlement_script_id": resourceUUID(),
+			"etag":                  etagSchema(),
 			"name": {
 				Type:        schema.TypeString,
 				Description: "Name of the object.",
@@ -46,6 +51,8 @@ func resourceAppgateEntitlementScript() *schema.Resource {
 
 			"tags": tagsSchema(),
 
+			"tags_all": tagsAllSchema(),
+
 			"type": {
 				Type:     schema.TypeString,
 				Optional: true,
@@ -60,20 +67,18 @@ func resourceAppgateEntitlementScript() *schema.Resource {
 	}
 }
 
-func resourceAppgateEntitlementScriptCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateEntitlementScriptCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Creating Entitlement script: %s", d.Get("name").(string))
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.EntitlementScriptsApi
 	args := openapi.NewEntitlementScriptWithDefaults()
-	if v, ok := d.GetOk("entitlement_script_id"); ok {
-		args.SetId(v.(string))
-	}
+	args.SetId(resourceObjectID(d, "entitlement_script_id"))
 	args.SetName(d.Get("name").(string))
 	args.SetNotes(d.Get("notes").(string))
-	args.SetTags(schemaExtractTags(d))
+	args.SetTags(schemaExtractTags(d, meta))
 
 	if v, ok := d.GetOk("expression"); ok {
 		args.SetExpression(v.(string))
@@ -81,28 +86,28 @@ func resourceAppgateEntitlementScriptCreate(d *schema.ResourceData, meta interfa
 	if v, ok := d.GetOk("type"); ok {
 		args.SetType(v.(string))
 	}
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	request := api.EntitlementScriptsPost(ctx)
 	request = request.EntitlementScript(*args)
 	EntitlementScript, _, err := request.Execute()
 	if err != nil {
-		return fmt.Errorf("Could not create Entitlement script %w", prettyPrintAPIError(err))
+		return apiErrorDiagnostics("Could not create Entitlement script", err, resourceAppgateEntitlementScript().Schema)
 	}
 
 	d.SetId(EntitlementScript.GetId())
 	d.Set("entitlement_script_id", EntitlementScript.GetId())
 
-	return resourceAppgateEntitlementScriptRead(d, meta)
+	return resourceAppgateEntitlementScriptRead(ctx, d, meta)
 }
 
-func resourceAppgateEntitlementScriptRead(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateEntitlementScriptRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Reading Entitlement script id: %+v", d.Id())
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.EntitlementScriptsApi
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	request := api.EntitlementScriptsIdGet(ctx, d.Id())
 	EntitlementScript, res, err := request.Execute()
 	if err != nil {
@@ -110,32 +115,36 @@ func resourceAppgateEntitlementScriptRead(d *schema.ResourceData, meta interface
 		if res != nil && res.StatusCode == http.StatusNotFound {
 			return nil
 		}
-		return fmt.Errorf("Failed to read Entitlement script, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read Entitlement script, %w", err))
 	}
 	d.SetId(EntitlementScript.GetId())
+	d.Set("etag", objectETag(EntitlementScript))
 	d.Set("entitlement_script_id", EntitlementScript.GetId())
 	d.Set("name", EntitlementScript.GetName())
 	d.Set("notes", EntitlementScript.GetNotes())
-	d.Set("tags", EntitlementScript.GetTags())
+	setTags(d, meta, EntitlementScript.GetTags())
 	d.Set("expression", EntitlementScript.GetExpression())
 	d.Set("type", EntitlementScript.GetType())
 
 	return nil
 }
 
-func resourceAppgateEntitlementScriptUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateEntitlementScriptUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Updating Entitlement script: %s", d.Get("name").(string))
 	log.Printf("[DEBUG] Updating Entitlement script id: %+v", d.Id())
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.EntitlementScriptsApi
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	request := api.EntitlementScriptsIdGet(ctx, d.Id())
 	originalEntitlementScript, _, err := request.Execute()
 	if err != nil {
-		return fmt.Errorf("Failed to read Entitlement script while updating, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read Entitlement script while updating, %w", err))
+	}
+	if diags := checkNotModified(d, meta, "Entitlement script", originalEntitlementScript); diags.HasError() {
+		return diags
 	}
 
 	if d.HasChange("name") {
@@ -146,8 +155,8 @@ func resourceAppgateEntitlementScriptUpdate(d *schema.ResourceData, meta interfa
 		originalEntitlementScript.SetNotes(d.Get("notes").(string))
 	}
 
-	if d.HasChange("tags") {
-		originalEntitlementScript.SetTags(schemaExtractTags(d))
+	if d.HasChanges("tags", "tags_all") {
+		originalEntitlementScript.SetTags(schemaExtractTags(d, meta))
 	}
 
 	if d.HasChange("type") {
@@ -162,21 +171,21 @@ func resourceAppgateEntitlementScriptUpdate(d *schema.ResourceData, meta interfa
 	req = req.EntitlementScript(*originalEntitlementScript)
 	_, _, err = req.Execute()
 	if err != nil {
-		return fmt.Errorf("Could not update Entitlement script %w", prettyPrintAPIError(err))
+		return apiErrorDiagnostics("Could not update Entitlement script", err, resourceAppgateEntitlementScript().Schema)
 	}
-	return resourceAppgateEntitlementScriptRead(d, meta)
+	return resourceAppgateEntitlementScriptRead(ctx, d, meta)
 }
 
-func resourceAppgateEntitlementScriptDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateEntitlementScriptDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Delete Entitlement script: %s", d.Get("name").(string))
 	log.Printf("[DEBUG] Reading Entitlement script id: %+v", d.Id())
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.EntitlementScriptsApi
-	if _, err := api.EntitlementScriptsIdDelete(BaseAuthContext(token), d.Id()).Execute(); err != nil {
-		return fmt.Errorf("Could not delete Entitlement script %w", prettyPrintAPIError(err))
+	if _, err := api.EntitlementScriptsIdDelete(authContext(ctx, token), d.Id()).Execute(); err != nil {
+		return diag.FromErr(fmt.Errorf("Could not delete Entitlement script %w", prettyPrintAPIError(err)))
 	}
 	d.SetId("")
 	return nil
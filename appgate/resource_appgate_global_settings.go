@@ -10,6 +10,7 @@ import (
 	v22 "github.com/appgate/sdp-api-client-go/api/v22/openapi"
 	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -22,8 +23,9 @@ func resourceGlobalSettings() *schema.Resource {
 		ReadContext:   resourceGlobalSettingsRead,
 		UpdateContext: resourceGlobalSettingsUpdate,
 		DeleteContext: resourceGlobalSettingsDelete,
+		CustomizeDiff: customdiff.All(etagCustomizeDiff, attributeVersionCustomizeDiff("appgatesdp_global_settings")),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Timeouts: &schema.ResourceTimeout{
@@ -33,7 +35,8 @@ func resourceGlobalSettings() *schema.Resource {
 		},
 
 		SchemaVersion: 1,
-		Schema: map[string]*schema.Schema{
+		Schema: mergeSchemaMaps(map[string]*schema.Schema{
+			"etag": etagSchema(),
 			"profile_hostname": {
 				Type:        schema.TypeString,
 				Description: "Client Connections, The hostname to use for generating profile URLs.",
@@ -162,7 +165,7 @@ func resourceGlobalSettings() *schema.Resource {
 				Description: "A randomly generated ID during first installation to identify the Collective.",
 				Computed:    true,
 			},
-		},
+		}, writeOnlySchemas("backup_passphrase", "The passphrase to encrypt Appliance Backups when backup API is used.")),
 	}
 }
 
@@ -179,11 +182,10 @@ func resourceGlobalSettingsRead(ctx context.Context, d *schema.ResourceData, met
 
 		return diag.FromErr(err)
 	}
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
-	currentVersion := meta.(*Client).ApplianceVersion
+	ctx = authContext(ctx, token)
 	var settings *openapi.GlobalSettings
 	if meta.(*Client).Config.Version < 23 {
-		ctx = context.WithValue(ctx, v22.ContextAccessToken, token)
+		ctx = authContext(ctx, token)
 		oldApi := meta.(*Client).OldAPI.GlobalSettingsApi
 		request2 := oldApi.GlobalSettingsGet(ctx)
 		settings2, res, err := request2.Execute()
@@ -212,6 +214,7 @@ func resourceGlobalSettingsRead(ctx context.Context, d *schema.ResourceData, met
 		}
 	}
 	d.SetId(settings.GetCollectiveId())
+	d.Set("etag", objectETag(settings))
 	d.Set("claims_token_expiration", settings.GetClaimsTokenExpiration())
 	d.Set("entitlement_token_expiration", settings.GetEntitlementTokenExpiration())
 	d.Set("administration_token_expiration", settings.GetAdministrationTokenExpiration())
@@ -221,6 +224,9 @@ func resourceGlobalSettingsRead(ctx context.Context, d *schema.ResourceData, met
 	d.Set("backup_api_enabled", settings.GetBackupApiEnabled())
 	if val, ok := d.GetOk("backup_passphrase"); ok {
 		d.Set("backup_passphrase", val)
+	} else if _, ok := d.GetOk("backup_passphrase_wo_version"); ok {
+		// the passphrase is set with backup_passphrase_wo, keep it out of the state.
+		d.Set("backup_passphrase", "")
 	} else {
 		d.Set("backup_passphrase", settings.GetBackupPassphrase())
 	}
@@ -232,8 +238,8 @@ func resourceGlobalSettingsRead(ctx context.Context, d *schema.ResourceData, met
 	d.Set("spa_time_window_seconds", settings.GetSpaTimeWindowSeconds())
 	d.Set("spa_mode", settings.GetSpaMode())
 
-	if currentVersion.GreaterThanOrEqual(Appliance62Version) {
-		d.Set("registered_device_expiration_days", settings.GetRegisteredDeviceExpirationDays())
+	if v, ok := settings.GetRegisteredDeviceExpirationDaysOk(); ok {
+		d.Set("registered_device_expiration_days", *v)
 	}
 	return diags
 }
@@ -305,14 +311,13 @@ func resourceGlobalSettingsUpdate(ctx context.Context, d *schema.ResourceData, m
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
-	currentVersion := meta.(*Client).ApplianceVersion
+	ctx = authContext(ctx, token)
 	var originalsettings *openapi.GlobalSettings
 	oldApi := meta.(*Client).OldAPI.GlobalSettingsApi
 	api := meta.(*Client).API.GlobalSettingsApi
 
 	if meta.(*Client).Config.Version < 23 {
-		ctx = context.WithValue(ctx, v22.ContextAccessToken, token)
+		ctx = authContext(ctx, token)
 		request2 := oldApi.GlobalSettingsGet(ctx)
 		settings2, res, err := request2.Execute()
 		if err != nil {
@@ -323,7 +328,7 @@ func resourceGlobalSettingsUpdate(ctx context.Context, d *schema.ResourceData, m
 		}
 		originalsettings = ConvertGlobalSettings(settings2)
 	} else {
-		ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+		ctx = authContext(ctx, token)
 		request := api.GlobalSettingsGet(ctx)
 
 		originalsettings, _, err = request.Execute()
@@ -331,6 +336,9 @@ func resourceGlobalSettingsUpdate(ctx context.Context, d *schema.ResourceData, m
 			return diag.FromErr(fmt.Errorf("Failed to read Global settings while updating, %w", err))
 		}
 	}
+	if diags := checkNotModified(d, meta, "Global settings", originalsettings); diags.HasError() {
+		return diags
+	}
 
 	if d.HasChange("claims_token_expiration") {
 		originalsettings.SetClaimsTokenExpiration(float32(d.Get("claims_token_expiration").(int)))
@@ -356,6 +364,15 @@ func resourceGlobalSettingsUpdate(ctx context.Context, d *schema.ResourceData, m
 	if d.HasChange("backup_passphrase") {
 		originalsettings.SetBackupPassphrase(d.Get("backup_passphrase").(string))
 	}
+	if d.HasChange("backup_passphrase_wo_version") {
+		passphrase, ok, diags := writeOnlyString(d, "backup_passphrase_wo")
+		if diags.HasError() {
+			return diags
+		}
+		if ok {
+			originalsettings.SetBackupPassphrase(passphrase)
+		}
+	}
 	if d.HasChange("geo_ip_updates") {
 		originalsettings.GeoIpSettings.SetUpdates(d.Get("geo_ip_updates").(string))
 	}
@@ -363,11 +380,7 @@ func resourceGlobalSettingsUpdate(ctx context.Context, d *schema.ResourceData, m
 		originalsettings.SetAuditLogPersistenceMode(d.Get("audit_log_persistence_mode").(string))
 	}
 	if d.HasChange("registered_device_expiration_days") {
-		if currentVersion.LessThan(Appliance62Version) {
-			return diag.Errorf("registered_device_expiration_days is not supported on %s", currentVersion.String())
-		} else if currentVersion.GreaterThanOrEqual(Appliance62Version) {
-			originalsettings.SetRegisteredDeviceExpirationDays(float32(d.Get("registered_device_expiration_days").(int)))
-		}
+		originalsettings.SetRegisteredDeviceExpirationDays(float32(d.Get("registered_device_expiration_days").(int)))
 	}
 	if d.HasChange("spa_mode") {
 		originalsettings.SetSpaMode(d.Get("spa_mode").(string))
@@ -379,7 +392,7 @@ func resourceGlobalSettingsUpdate(ctx context.Context, d *schema.ResourceData, m
 		originalsettings.SetCollectiveName(d.Get("collective_name").(string))
 	}
 	log.Printf("[DEBUG] Updating Global settings %+v", originalsettings)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	if meta.(*Client).Config.Version < 23 {
 		oldReq := oldApi.GlobalSettingsPut(ctx)
 		_, err = oldReq.GlobalSettings(*revertGlobalSettings(originalsettings)).Execute()
@@ -403,7 +416,7 @@ func resourceGlobalSettingsDelete(ctx context.Context, d *schema.ResourceData, m
 		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.GlobalSettingsApi
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	if _, err := api.GlobalSettingsDelete(ctx).Execute(); err != nil {
 		return diag.FromErr(fmt.Errorf("Could not reset Global settings %w", prettyPrintAPIError(err)))
 	}
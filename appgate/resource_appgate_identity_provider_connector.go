@@ -7,19 +7,22 @@ import (
 
 	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func resourceAppgateConnectorProvider() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceAppgateConnectorProviderRuleCreate,
-		Read:   resourceAppgateConnectorProviderRuleRead,
-		Update: resourceAppgateConnectorProviderRuleUpdate,
-		Delete: resourceAppgateConnectorProviderRuleDelete,
+		CreateContext: resourceAppgateConnectorProviderRuleCreate,
+		ReadContext:   resourceAppgateConnectorProviderRuleRead,
+		UpdateContext: resourceAppgateConnectorProviderRuleUpdate,
+		DeleteContext: resourceAppgateConnectorProviderRuleDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: importStateByNameOrTag("connector identity provider", listIdentityProviderImportCandidates(identityProviderConnector)),
 		},
 
+		CustomizeDiff: customdiff.All(tagsCustomizeDiff, etagCustomizeDiff),
 		Schema: func() map[string]*schema.Schema {
 			s := mergeSchemaMaps(baseEntitySchema(), identityProviderIPPoolSchema(), identityProviderClaimsSchema())
 			s["name"] = &schema.Schema{
@@ -32,40 +35,41 @@ func resourceAppgateConnectorProvider() *schema.Resource {
 				Optional: true,
 				Default:  identityProviderConnector,
 			}
+			s["etag"] = etagSchema()
 
 			return s
 		}(),
 	}
 }
 
-func resourceAppgateConnectorProviderRuleDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateConnectorProviderRuleDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	// We can't delete the builtin connector identity provider, but we can remove it from the terraform state file.
 	d.SetId("")
 	return nil
 }
 
-func resourceAppgateConnectorProviderRuleCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateConnectorProviderRuleCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	// we aren'ẗ allowed to create new additional local identity providers, but we can update existing
 	// with terraform import.
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.ConnectorIdentityProvidersApi
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	connectorIP, err := getBuiltinConnectorProviderUUID(ctx, *api, token)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId(connectorIP.GetId())
 
-	return resourceAppgateConnectorProviderRuleUpdate(d, meta)
+	return resourceAppgateConnectorProviderRuleUpdate(ctx, d, meta)
 }
 
 func getBuiltinConnectorProviderUUID(ctx context.Context, api openapi.ConnectorIdentityProvidersApiService, token string) (*openapi.ConnectorProvider, error) {
 	var connectorIP *openapi.ConnectorProvider
-	request := api.IdentityProvidersGet(context.WithValue(ctx, openapi.ContextAccessToken, token))
+	request := api.IdentityProvidersGet(authContext(ctx, token))
 	provider, _, err := request.Query(builtinProviderConnector).OrderBy("name").Range_("0-25").Execute()
 	if err != nil {
 		return connectorIP, err
@@ -78,26 +82,27 @@ func getBuiltinConnectorProviderUUID(ctx context.Context, api openapi.ConnectorI
 	return connectorIP, fmt.Errorf("Could not find builtin connector identity provider")
 }
 
-func resourceAppgateConnectorProviderRuleRead(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateConnectorProviderRuleRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Reading connectorIP identity provider")
 
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.ConnectorIdentityProvidersApi
-	connectorIP, err := getBuiltinConnectorProviderUUID(context.Background(), *api, token)
+	connectorIP, err := getBuiltinConnectorProviderUUID(ctx, *api, token)
 	if err != nil {
 		d.SetId("")
-		return fmt.Errorf("Failed to read Connector Identity provider, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read Connector Identity provider, %w", err))
 	}
 	d.SetId(connectorIP.GetId())
+	d.Set("etag", objectETag(connectorIP))
 
 	d.Set("type", identityProviderConnector)
 	// base attributes
 	d.Set("name", connectorIP.Name)
 	d.Set("notes", connectorIP.Notes)
-	d.Set("tags", connectorIP.Tags)
+	setTags(d, meta, connectorIP.Tags)
 
 	// identity provider attributes
 	if v, ok := connectorIP.GetIpPoolV4Ok(); ok {
@@ -109,7 +114,7 @@ func resourceAppgateConnectorProviderRuleRead(d *schema.ResourceData, meta inter
 
 	if v, ok := connectorIP.GetClaimMappingsOk(); ok {
 		if err := d.Set("claim_mappings", flattenIdentityProviderClaimsMappning(v)); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 	// TODO ?? is this need
@@ -120,18 +125,26 @@ func resourceAppgateConnectorProviderRuleRead(d *schema.ResourceData, meta inter
 	return nil
 }
 
-func resourceAppgateConnectorProviderRuleUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateConnectorProviderRuleUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Updating connectorIP identity provider id: %+v", d.Id())
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.ConnectorIdentityProvidersApi
-	ctx := context.WithValue(context.Background(), openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	request := api.IdentityProvidersIdGet(ctx, d.Id())
 	originalConnectorProvider, _, err := request.Execute()
 	if err != nil {
-		return fmt.Errorf("Failed to read Connector Identity provider, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read Connector Identity provider, %w", err))
+	}
+	// the etag is from the list of identity providers in read, compare it with the same object.
+	current, err := getBuiltinConnectorProviderUUID(ctx, *api, token)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if diags := checkNotModified(d, meta, "Connector identity provider", current); diags.HasError() {
+		return diags
 	}
 	// base attributes
 	if d.HasChange("name") {
@@ -142,8 +155,8 @@ func resourceAppgateConnectorProviderRuleUpdate(d *schema.ResourceData, meta int
 		originalConnectorProvider.SetNotes(d.Get("notes").(string))
 	}
 
-	if d.HasChange("tags") {
-		originalConnectorProvider.SetTags(schemaExtractTags(d))
+	if d.HasChanges("tags", "tags_all") {
+		originalConnectorProvider.SetTags(schemaExtractTags(d, meta))
 	}
 
 	// identity provider attributes
@@ -167,7 +180,7 @@ func resourceAppgateConnectorProviderRuleUpdate(d *schema.ResourceData, meta int
 
 	_, _, err = api.IdentityProvidersIdPut(ctx, d.Id()).Body(*originalConnectorProvider).Execute()
 	if err != nil {
-		return fmt.Errorf("Could not update %s provider %w", identityProviderConnector, prettyPrintAPIError(err))
+		return apiErrorDiagnostics(fmt.Sprintf("Could not update %s provider", identityProviderConnector), err, resourceAppgateConnectorProvider().Schema)
 	}
-	return resourceAppgateConnectorProviderRuleRead(d, meta)
+	return resourceAppgateConnectorProviderRuleRead(ctx, d, meta)
 }
@@ -9,17 +9,20 @@ import (
 
 	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func resourceAppgateLdapProvider() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceAppgateLdapProviderRuleCreate,
-		Read:   resourceAppgateLdapProviderRuleRead,
-		Update: resourceAppgateLdapProviderRuleUpdate,
-		Delete: identityProviderDelete,
+		CreateContext: resourceAppgateLdapProviderRuleCreate,
+		ReadContext:   resourceAppgateLdapProviderRuleRead,
+		UpdateContext: resourceAppgateLdapProviderRuleUpdate,
+		DeleteContext: identityProviderDelete,
+		CustomizeDiff: customdiff.All(tagsCustomizeDiff, etagCustomizeDiff, attributeVersionCustomizeDiff("appgatesdp_ldap_identity_provider")),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: importStateByNameOrTag("ldap identity provider", listIdentityProviderImportCandidates(identityProviderLdap)),
 		},
 
 		Timeouts: &schema.ResourceTimeout{
@@ -37,19 +40,18 @@ func resourceAppgateLdapProvider() *schema.Resource {
 	}
 }
 
-func resourceAppgateLdapProviderRuleCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateLdapProviderRuleCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Creating LdapProvider: %s", d.Get("name").(string))
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.LdapIdentityProvidersApi
-	currentVersion := meta.(*Client).ApplianceVersion
 	provider := &openapi.ConfigurableIdentityProvider{}
 	provider.Type = identityProviderLdap
-	provider, err = readProviderFromConfig(d, *provider)
+	provider, err = readProviderFromConfig(d, meta, *provider)
 	if err != nil {
-		return fmt.Errorf("Failed to read and create basic identity provider for %s %w", identityProviderLdap, err)
+		return diag.FromErr(fmt.Errorf("Failed to read and create basic identity provider for %s %w", identityProviderLdap, err))
 	}
 
 	args := openapi.LdapProvider{}
@@ -73,9 +75,6 @@ func resourceAppgateLdapProviderRuleCreate(d *schema.ResourceData, meta interfac
 		args.SetInactivityTimeoutMinutes(*provider.InactivityTimeoutMinutes)
 	}
 	if provider.NetworkInactivityTimeoutEnabled != nil {
-		if currentVersion.LessThan(Appliance61Version) {
-			return ErrNetworkInactivityTimeoutEnabled
-		}
 		args.SetNetworkInactivityTimeoutEnabled(provider.GetNetworkInactivityTimeoutEnabled())
 	}
 	if provider.IpPoolV4 != nil {
@@ -102,7 +101,7 @@ func resourceAppgateLdapProviderRuleCreate(d *schema.ResourceData, meta interfac
 	if v, ok := d.GetOk("hostnames"); ok {
 		hostnames, err := readArrayOfStringsFromConfig(v.([]interface{}))
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		args.SetHostnames(hostnames)
 	}
@@ -140,25 +139,25 @@ func resourceAppgateLdapProviderRuleCreate(d *schema.ResourceData, meta interfac
 		pw := readLdapPasswordWarningFromConfig(v.([]interface{}))
 		args.SetPasswordWarning(pw)
 	}
-	ctx := context.WithValue(context.Background(), openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	request := api.IdentityProvidersPost(ctx)
 	p, _, err := request.Body(args).Execute()
 	if err != nil {
-		return fmt.Errorf("Could not create %s provider %w", identityProviderLdap, prettyPrintAPIError(err))
+		return apiErrorDiagnostics(fmt.Sprintf("Could not create %s provider", identityProviderLdap), err, resourceAppgateLdapProvider().Schema)
 	}
 	d.SetId(p.GetId())
-	return resourceAppgateLdapProviderRuleRead(d, meta)
+	return resourceAppgateLdapProviderRuleRead(ctx, d, meta)
 }
 
-func resourceAppgateLdapProviderRuleRead(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateLdapProviderRuleRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Reading ldap identity provider id: %+v", d.Id())
 
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.LdapIdentityProvidersApi
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	request := api.IdentityProvidersIdGet(ctx, d.Id())
 	ldap, res, err := request.Execute()
 	if err != nil {
@@ -166,13 +165,14 @@ func resourceAppgateLdapProviderRuleRead(d *schema.ResourceData, meta interface{
 		if res != nil && res.StatusCode == http.StatusNotFound {
 			return nil
 		}
-		return fmt.Errorf("Failed to read LDAP Identity provider, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read LDAP Identity provider, %w", err))
 	}
+	d.Set("etag", objectETag(ldap))
 	d.Set("type", identityProviderLdap)
 	// base attributes
 	d.Set("name", ldap.Name)
 	d.Set("notes", ldap.Notes)
-	d.Set("tags", ldap.Tags)
+	setTags(d, meta, ldap.Tags)
 
 	// identity provider attributes
 	if v, ok := ldap.GetDeviceLimitPerUserOk(); ok {
@@ -180,7 +180,7 @@ func resourceAppgateLdapProviderRuleRead(d *schema.ResourceData, meta interface{
 	}
 	if v, ok := ldap.GetOnBoarding2FAOk(); ok {
 		if err := d.Set("on_boarding_two_factor", flattenIdentityProviderOnboarding2fa(*v)); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
@@ -198,12 +198,12 @@ func resourceAppgateLdapProviderRuleRead(d *schema.ResourceData, meta interface{
 	d.Set("block_local_dns_requests", ldap.GetBlockLocalDnsRequests())
 	if v, ok := ldap.GetClaimMappingsOk(); ok {
 		if err := d.Set("claim_mappings", flattenIdentityProviderClaimsMappning(v)); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 	if v, ok := ldap.GetOnDemandClaimMappingsOk(); ok {
 		if err := d.Set("on_demand_claim_mappings", flattenIdentityProviderOnDemandClaimsMappning(v)); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
@@ -225,12 +225,12 @@ func resourceAppgateLdapProviderRuleRead(d *schema.ResourceData, meta interface{
 	d.Set("membership_filter", ldap.GetMembershipFilter())
 	if v, ok := ldap.GetMembershipBaseDnOk(); ok {
 		if err := d.Set("membership_base_dn", &v); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 	if v, ok := ldap.GetPasswordWarningOk(); ok {
 		if err := d.Set("password_warning", flattenLdapPasswordWarning(*v)); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 	return nil
@@ -267,18 +267,21 @@ func readLdapPasswordWarningFromConfig(input []interface{}) openapi.LdapProvider
 	return pw
 }
 
-func resourceAppgateLdapProviderRuleUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateLdapProviderRuleUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Updating ldap identity provider id: %+v", d.Id())
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.LdapIdentityProvidersApi
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	request := api.IdentityProvidersIdGet(ctx, d.Id())
 	originalLdapProvider, _, err := request.Execute()
 	if err != nil {
-		return fmt.Errorf("Failed to read LDAP Identity provider, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read LDAP Identity provider, %w", err))
+	}
+	if diags := checkNotModified(d, meta, "LDAP identity provider", originalLdapProvider); diags.HasError() {
+		return diags
 	}
 	// base attributes
 	if d.HasChange("name") {
@@ -289,8 +292,8 @@ func resourceAppgateLdapProviderRuleUpdate(d *schema.ResourceData, meta interfac
 		originalLdapProvider.SetNotes(d.Get("notes").(string))
 	}
 
-	if d.HasChange("tags") {
-		originalLdapProvider.SetTags(schemaExtractTags(d))
+	if d.HasChanges("tags", "tags_all") {
+		originalLdapProvider.SetTags(schemaExtractTags(d, meta))
 	}
 
 	// identity provider attributes
@@ -304,7 +307,7 @@ func resourceAppgateLdapProviderRuleUpdate(d *schema.ResourceData, meta interfac
 		_, v := d.GetChange("on_boarding_two_factor")
 		onboarding, err := readOnBoardingTwoFactorFromConfig(v.([]interface{}))
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		originalLdapProvider.SetOnBoarding2FA(onboarding)
 	}
@@ -325,7 +328,7 @@ func resourceAppgateLdapProviderRuleUpdate(d *schema.ResourceData, meta interfac
 		_, v := d.GetChange("dns_servers")
 		servers, err := readArrayOfStringsFromConfig(v.([]interface{}))
 		if err != nil {
-			return fmt.Errorf("Failed to read dns servers %w", err)
+			return diag.FromErr(fmt.Errorf("Failed to read dns servers %w", err))
 		}
 		originalLdapProvider.SetDnsServers(servers)
 	}
@@ -333,7 +336,7 @@ func resourceAppgateLdapProviderRuleUpdate(d *schema.ResourceData, meta interfac
 		_, v := d.GetChange("dns_search_domains")
 		servers, err := readArrayOfStringsFromConfig(v.([]interface{}))
 		if err != nil {
-			return fmt.Errorf("Failed to read dns search domains %w", err)
+			return diag.FromErr(fmt.Errorf("Failed to read dns search domains %w", err))
 		}
 		originalLdapProvider.SetDnsSearchDomains(servers)
 	}
@@ -356,7 +359,7 @@ func resourceAppgateLdapProviderRuleUpdate(d *schema.ResourceData, meta interfac
 		_, v := d.GetChange("hostnames")
 		hostnames, err := readArrayOfStringsFromConfig(v.([]interface{}))
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		originalLdapProvider.SetHostnames(hostnames)
 	}
@@ -399,7 +402,7 @@ func resourceAppgateLdapProviderRuleUpdate(d *schema.ResourceData, meta interfac
 	req = req.Body(*originalLdapProvider)
 	_, _, err = req.Execute()
 	if err != nil {
-		return fmt.Errorf("Could not update %s provider %w", identityProviderLdap, prettyPrintAPIError(err))
+		return apiErrorDiagnostics(fmt.Sprintf("Could not update %s provider", identityProviderLdap), err, resourceAppgateLdapProvider().Schema)
 	}
-	return resourceAppgateLdapProviderRuleRead(d, meta)
+	return resourceAppgateLdapProviderRuleRead(ctx, d, meta)
 }
@@ -1,23 +1,27 @@
 package appgate
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 
 	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func resourceAppgateLdapCertificateProvider() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceAppgateLdapCertificateProviderRuleCreate,
-		Read:   resourceAppgateLdapCertificateProviderRuleRead,
-		Update: resourceAppgateLdapCertificateProviderRuleUpdate,
-		Delete: identityProviderDelete,
+		CreateContext: resourceAppgateLdapCertificateProviderRuleCreate,
+		ReadContext:   resourceAppgateLdapCertificateProviderRuleRead,
+		UpdateContext: resourceAppgateLdapCertificateProviderRuleUpdate,
+		DeleteContext: identityProviderDelete,
+		CustomizeDiff: customdiff.All(tagsCustomizeDiff, etagCustomizeDiff, attributeVersionCustomizeDiff("appgatesdp_ldap_certificate_identity_provider")),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: importStateByNameOrTag("ldap certificate identity provider", listIdentityProviderImportCandidates(identityProviderLdapCertificate)),
 		},
 		Schema: func() map[string]*schema.Schema {
 			s := ldapProviderSchema()
@@ -57,20 +61,19 @@ func resourceAppgateLdapCertificateProvider() *schema.Resource {
 	}
 }
 
-func resourceAppgateLdapCertificateProviderRuleCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateLdapCertificateProviderRuleCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Creating LdapCertificateProvider: %s", d.Get("name").(string))
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.LdapCertificateIdentityProvidersApi
-	ctx := BaseAuthContext(token)
-	currentVersion := meta.(*Client).ApplianceVersion
+	ctx = authContext(ctx, token)
 	provider := &openapi.ConfigurableIdentityProvider{}
 	provider.Type = identityProviderLdapCertificate
-	provider, err = readProviderFromConfig(d, *provider)
+	provider, err = readProviderFromConfig(d, meta, *provider)
 	if err != nil {
-		return fmt.Errorf("Failed to read and create basic identity provider for %s %w", identityProviderLdapCertificate, err)
+		return diag.FromErr(fmt.Errorf("Failed to read and create basic identity provider for %s %w", identityProviderLdapCertificate, err))
 	}
 
 	args := openapi.LdapCertificateProvider{}
@@ -94,9 +97,6 @@ func resourceAppgateLdapCertificateProviderRuleCreate(d *schema.ResourceData, me
 		args.SetInactivityTimeoutMinutes(*provider.InactivityTimeoutMinutes)
 	}
 	if provider.NetworkInactivityTimeoutEnabled != nil {
-		if currentVersion.LessThan(Appliance61Version) {
-			return ErrNetworkInactivityTimeoutEnabled
-		}
 		args.SetNetworkInactivityTimeoutEnabled(provider.GetNetworkInactivityTimeoutEnabled())
 	}
 	if provider.IpPoolV4 != nil {
@@ -126,7 +126,7 @@ func resourceAppgateLdapCertificateProviderRuleCreate(d *schema.ResourceData, me
 	if v, ok := d.GetOk("hostnames"); ok {
 		hostnames, err := readArrayOfStringsFromConfig(v.([]interface{}))
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		args.SetHostnames(hostnames)
 	}
@@ -168,7 +168,7 @@ func resourceAppgateLdapCertificateProviderRuleCreate(d *schema.ResourceData, me
 	if v, ok := d.GetOk("ca_certificates"); ok {
 		certificates, err := readArrayOfStringsFromConfig(v.([]interface{}))
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		args.SetCaCertificates(certificates)
 	}
@@ -185,21 +185,21 @@ func resourceAppgateLdapCertificateProviderRuleCreate(d *schema.ResourceData, me
 	request := api.IdentityProvidersPost(ctx)
 	p, _, err := request.Body(args).Execute()
 	if err != nil {
-		return fmt.Errorf("Could not create %s provider %w", identityProviderLdapCertificate, prettyPrintAPIError(err))
+		return apiErrorDiagnostics(fmt.Sprintf("Could not create %s provider", identityProviderLdapCertificate), err, resourceAppgateLdapCertificateProvider().Schema)
 	}
 	d.SetId(p.GetId())
-	return resourceAppgateLdapCertificateProviderRuleRead(d, meta)
+	return resourceAppgateLdapCertificateProviderRuleRead(ctx, d, meta)
 }
 
-func resourceAppgateLdapCertificateProviderRuleRead(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateLdapCertificateProviderRuleRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Reading ldap identity provider id: %+v", d.Id())
 
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.LdapCertificateIdentityProvidersApi
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	request := api.IdentityProvidersIdGet(ctx, d.Id())
 	ldap, res, err := request.Execute()
 	if err != nil {
@@ -207,13 +207,14 @@ func resourceAppgateLdapCertificateProviderRuleRead(d *schema.ResourceData, meta
 		if res != nil && res.StatusCode == http.StatusNotFound {
 			return nil
 		}
-		return fmt.Errorf("Failed to read LDAP Identity provider, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read LDAP Identity provider, %w", err))
 	}
+	d.Set("etag", objectETag(ldap))
 	d.Set("type", identityProviderLdapCertificate)
 	// base attributes
 	d.Set("name", ldap.GetName())
 	d.Set("notes", ldap.GetNotes())
-	d.Set("tags", ldap.GetTags())
+	setTags(d, meta, ldap.GetTags())
 
 	// identity provider attributes
 	d.Set("admin_provider", ldap.GetAdminProvider())
@@ -222,7 +223,7 @@ func resourceAppgateLdapCertificateProviderRuleRead(d *schema.ResourceData, meta
 	}
 	if v, ok := ldap.GetOnBoarding2FAOk(); ok {
 		if err := d.Set("on_boarding_two_factor", flattenIdentityProviderOnboarding2fa(*v)); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
@@ -241,12 +242,12 @@ func resourceAppgateLdapCertificateProviderRuleRead(d *schema.ResourceData, meta
 	d.Set("block_local_dns_requests", ldap.GetBlockLocalDnsRequests())
 	if v, ok := ldap.GetClaimMappingsOk(); ok {
 		if err := d.Set("claim_mappings", flattenIdentityProviderClaimsMappning(v)); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 	if v, ok := ldap.GetOnDemandClaimMappingsOk(); ok {
 		if err := d.Set("on_demand_claim_mappings", flattenIdentityProviderOnDemandClaimsMappning(v)); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
@@ -268,12 +269,12 @@ func resourceAppgateLdapCertificateProviderRuleRead(d *schema.ResourceData, meta
 	d.Set("membership_filter", ldap.GetMembershipFilter())
 	if v, ok := ldap.GetMembershipBaseDnOk(); ok {
 		if err := d.Set("membership_base_dn", &v); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 	if v, ok := ldap.GetPasswordWarningOk(); ok {
 		if err := d.Set("password_warning", flattenLdapPasswordWarning(*v)); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
@@ -284,18 +285,21 @@ func resourceAppgateLdapCertificateProviderRuleRead(d *schema.ResourceData, meta
 	return nil
 }
 
-func resourceAppgateLdapCertificateProviderRuleUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateLdapCertificateProviderRuleUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Updating ldap identity provider id: %+v", d.Id())
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.LdapCertificateIdentityProvidersApi
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	request := api.IdentityProvidersIdGet(ctx, d.Id())
 	originalLdapCertificateProvider, _, err := request.Execute()
 	if err != nil {
-		return fmt.Errorf("Failed to read LDAP Identity provider, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read LDAP Identity provider, %w", err))
+	}
+	if diags := checkNotModified(d, meta, "LDAP certificate identity provider", originalLdapCertificateProvider); diags.HasError() {
+		return diags
 	}
 	// base attributes
 	if d.HasChange("name") {
@@ -306,8 +310,8 @@ func resourceAppgateLdapCertificateProviderRuleUpdate(d *schema.ResourceData, me
 		originalLdapCertificateProvider.SetNotes(d.Get("notes").(string))
 	}
 
-	if d.HasChange("tags") {
-		originalLdapCertificateProvider.SetTags(schemaExtractTags(d))
+	if d.HasChanges("tags", "tags_all") {
+		originalLdapCertificateProvider.SetTags(schemaExtractTags(d, meta))
 	}
 
 	// identity provider attributes
@@ -321,7 +325,7 @@ func resourceAppgateLdapCertificateProviderRuleUpdate(d *schema.ResourceData, me
 		_, v := d.GetChange("on_boarding_two_factor")
 		onboarding, err := readOnBoardingTwoFactorFromConfig(v.([]interface{}))
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		originalLdapCertificateProvider.SetOnBoarding2FA(onboarding)
 	}
@@ -342,7 +346,7 @@ func resourceAppgateLdapCertificateProviderRuleUpdate(d *schema.ResourceData, me
 		_, v := d.GetChange("user_scripts")
 		us, err := readArrayOfStringsFromConfig(v.([]interface{}))
 		if err != nil {
-			return fmt.Errorf("Failed to read user_scripts %w", err)
+			return diag.FromErr(fmt.Errorf("Failed to read user_scripts %w", err))
 		}
 		originalLdapCertificateProvider.SetUserScripts(us)
 	}
@@ -350,7 +354,7 @@ func resourceAppgateLdapCertificateProviderRuleUpdate(d *schema.ResourceData, me
 		_, v := d.GetChange("dns_servers")
 		servers, err := readArrayOfStringsFromConfig(v.([]interface{}))
 		if err != nil {
-			return fmt.Errorf("Failed to read dns servers %w", err)
+			return diag.FromErr(fmt.Errorf("Failed to read dns servers %w", err))
 		}
 		originalLdapCertificateProvider.SetDnsServers(servers)
 	}
@@ -358,7 +362,7 @@ func resourceAppgateLdapCertificateProviderRuleUpdate(d *schema.ResourceData, me
 		_, v := d.GetChange("dns_search_domains")
 		servers, err := readArrayOfStringsFromConfig(v.([]interface{}))
 		if err != nil {
-			return fmt.Errorf("Failed to read dns search domains %w", err)
+			return diag.FromErr(fmt.Errorf("Failed to read dns search domains %w", err))
 		}
 		originalLdapCertificateProvider.SetDnsSearchDomains(servers)
 	}
@@ -381,7 +385,7 @@ func resourceAppgateLdapCertificateProviderRuleUpdate(d *schema.ResourceData, me
 		_, v := d.GetChange("hostnames")
 		hostnames, err := readArrayOfStringsFromConfig(v.([]interface{}))
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		originalLdapCertificateProvider.SetHostnames(hostnames)
 	}
@@ -426,7 +430,7 @@ func resourceAppgateLdapCertificateProviderRuleUpdate(d *schema.ResourceData, me
 		_, v := d.GetChange("ca_certificates")
 		certificates, err := readArrayOfStringsFromConfig(v.([]interface{}))
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		originalLdapCertificateProvider.SetCaCertificates(certificates)
 	}
@@ -444,7 +448,7 @@ func resourceAppgateLdapCertificateProviderRuleUpdate(d *schema.ResourceData, me
 	req = req.Body(*originalLdapCertificateProvider)
 	_, _, err = req.Execute()
 	if err != nil {
-		return fmt.Errorf("Could not update %s provider %w", identityProviderLdapCertificate, prettyPrintAPIError(err))
+		return apiErrorDiagnostics(fmt.Sprintf("Could not update %s provider", identityProviderLdapCertificate), err, resourceAppgateLdapCertificateProvider().Schema)
 	}
-	return resourceAppgateLdapCertificateProviderRuleRead(d, meta)
+	return resourceAppgateLdapCertificateProviderRuleRead(ctx, d, meta)
 }
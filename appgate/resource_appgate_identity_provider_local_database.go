@@ -7,17 +7,20 @@ import (
 
 	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func resourceAppgateLocalDatabaseProvider() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceAppgateLocalDatabaseProviderRuleCreate,
-		Read:   resourceAppgateLocalDatabaseProviderRuleRead,
-		Update: resourceAppgateLocalDatabaseProviderRuleUpdate,
-		Delete: resourceAppgateLocalDatabaseProviderRuleDelete,
+		CreateContext: resourceAppgateLocalDatabaseProviderRuleCreate,
+		ReadContext:   resourceAppgateLocalDatabaseProviderRuleRead,
+		UpdateContext: resourceAppgateLocalDatabaseProviderRuleUpdate,
+		DeleteContext: resourceAppgateLocalDatabaseProviderRuleDelete,
+		CustomizeDiff: customdiff.All(tagsCustomizeDiff, etagCustomizeDiff, attributeVersionCustomizeDiff("appgatesdp_local_database_identity_provider")),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: importStateByNameOrTag("local database identity provider", listIdentityProviderImportCandidates(identityProviderLocalDatabase)),
 		},
 
 		Schema: func() map[string]*schema.Schema {
@@ -43,34 +46,34 @@ func resourceAppgateLocalDatabaseProvider() *schema.Resource {
 	}
 }
 
-func resourceAppgateLocalDatabaseProviderRuleDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateLocalDatabaseProviderRuleDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	// We can't delete the builtin local database identity provider, but we can remove it from the terraform state file.
 	d.SetId("")
 	return nil
 }
 
-func resourceAppgateLocalDatabaseProviderRuleCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateLocalDatabaseProviderRuleCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	// we aren'ẗ allowed to create new additional local identity providers, but we can update existing
 	// with terraform import.
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.LocalDatabaseIdentityProvidersApi
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	localDatabase, err := getBuiltinLocalDatabaseProviderUUID(ctx, *api, token)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId(localDatabase.GetId())
 
-	return resourceAppgateLocalDatabaseProviderRuleUpdate(d, meta)
+	return resourceAppgateLocalDatabaseProviderRuleUpdate(ctx, d, meta)
 }
 
 func getBuiltinLocalDatabaseProviderUUID(ctx context.Context, api openapi.LocalDatabaseIdentityProvidersApiService, token string) (*openapi.LocalDatabaseProvider, error) {
 	var localDatabase *openapi.LocalDatabaseProvider
-	request := api.IdentityProvidersGet(context.WithValue(ctx, openapi.ContextAccessToken, token))
+	request := api.IdentityProvidersGet(authContext(ctx, token))
 
 	provider, _, err := request.Query(builtinProviderLocal).OrderBy("name").Range_("0-25").Execute()
 	if err != nil {
@@ -84,33 +87,34 @@ func getBuiltinLocalDatabaseProviderUUID(ctx context.Context, api openapi.LocalD
 	return localDatabase, fmt.Errorf("Could not find builtin local database identity provider")
 }
 
-func resourceAppgateLocalDatabaseProviderRuleRead(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateLocalDatabaseProviderRuleRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Reading localDatabase identity provider")
 
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.LocalDatabaseIdentityProvidersApi
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	localDatabase, err := getBuiltinLocalDatabaseProviderUUID(ctx, *api, token)
 	if err != nil {
 		d.SetId("")
-		return fmt.Errorf("Failed to read LocalDatabase Identity provider, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read LocalDatabase Identity provider, %w", err))
 	}
 	d.SetId(localDatabase.GetId())
+	d.Set("etag", objectETag(localDatabase))
 
 	d.Set("type", identityProviderLocalDatabase)
 	// base attributes
 	d.Set("name", localDatabase.Name)
 	d.Set("notes", localDatabase.Notes)
-	d.Set("tags", localDatabase.Tags)
+	setTags(d, meta, localDatabase.Tags)
 
 	// identity provider attributes
 	d.Set("admin_provider", localDatabase.GetAdminProvider())
 	if v, ok := localDatabase.GetOnBoarding2FAOk(); ok {
 		if err := d.Set("on_boarding_two_factor", flattenIdentityProviderOnboarding2fa(*v)); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
@@ -129,7 +133,7 @@ func resourceAppgateLocalDatabaseProviderRuleRead(d *schema.ResourceData, meta i
 	d.Set("block_local_dns_requests", localDatabase.GetBlockLocalDnsRequests())
 	if v, ok := localDatabase.GetClaimMappingsOk(); ok {
 		if err := d.Set("claim_mappings", flattenIdentityProviderClaimsMappning(v)); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 	if v, ok := localDatabase.GetOnDemandClaimMappingsOk(); ok {
@@ -142,18 +146,26 @@ func resourceAppgateLocalDatabaseProviderRuleRead(d *schema.ResourceData, meta i
 	return nil
 }
 
-func resourceAppgateLocalDatabaseProviderRuleUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateLocalDatabaseProviderRuleUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Updating localDatabase identity provider id: %+v", d.Id())
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.LocalDatabaseIdentityProvidersApi
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	request := api.IdentityProvidersIdGet(ctx, d.Id())
 	originalLocalDatabaseProvider, _, err := request.Execute()
 	if err != nil {
-		return fmt.Errorf("Failed to read LocalDatabase Identity provider, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read LocalDatabase Identity provider, %w", err))
+	}
+	// the etag is from the list of identity providers in read, compare it with the same object.
+	current, err := getBuiltinLocalDatabaseProviderUUID(ctx, *api, token)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if diags := checkNotModified(d, meta, "Local database identity provider", current); diags.HasError() {
+		return diags
 	}
 	// base attributes
 	if d.HasChange("name") {
@@ -164,8 +176,8 @@ func resourceAppgateLocalDatabaseProviderRuleUpdate(d *schema.ResourceData, meta
 		originalLocalDatabaseProvider.SetNotes(d.Get("notes").(string))
 	}
 
-	if d.HasChange("tags") {
-		originalLocalDatabaseProvider.SetTags(schemaExtractTags(d))
+	if d.HasChanges("tags", "tags_all") {
+		originalLocalDatabaseProvider.SetTags(schemaExtractTags(d, meta))
 	}
 
 	// identity provider attributes
@@ -177,7 +189,7 @@ func resourceAppgateLocalDatabaseProviderRuleUpdate(d *schema.ResourceData, meta
 		_, v := d.GetChange("on_boarding_two_factor")
 		onboarding, err := readOnBoardingTwoFactorFromConfig(v.([]interface{}))
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		originalLocalDatabaseProvider.SetOnBoarding2FA(onboarding)
 	}
@@ -198,7 +210,7 @@ func resourceAppgateLocalDatabaseProviderRuleUpdate(d *schema.ResourceData, meta
 		_, v := d.GetChange("user_scripts")
 		us, err := readArrayOfStringsFromConfig(v.([]interface{}))
 		if err != nil {
-			return fmt.Errorf("Failed to read user_scripts %w", err)
+			return diag.FromErr(fmt.Errorf("Failed to read user_scripts %w", err))
 		}
 		originalLocalDatabaseProvider.SetUserScripts(us)
 	}
@@ -206,7 +218,7 @@ func resourceAppgateLocalDatabaseProviderRuleUpdate(d *schema.ResourceData, meta
 		_, v := d.GetChange("dns_servers")
 		servers, err := readArrayOfStringsFromConfig(v.([]interface{}))
 		if err != nil {
-			return fmt.Errorf("Failed to read dns servers %w", err)
+			return diag.FromErr(fmt.Errorf("Failed to read dns servers %w", err))
 		}
 		originalLocalDatabaseProvider.SetDnsServers(servers)
 	}
@@ -214,7 +226,7 @@ func resourceAppgateLocalDatabaseProviderRuleUpdate(d *schema.ResourceData, meta
 		_, v := d.GetChange("dns_search_domains")
 		servers, err := readArrayOfStringsFromConfig(v.([]interface{}))
 		if err != nil {
-			return fmt.Errorf("Failed to read dns search domains %w", err)
+			return diag.FromErr(fmt.Errorf("Failed to read dns search domains %w", err))
 		}
 		originalLocalDatabaseProvider.SetDnsSearchDomains(servers)
 	}
@@ -244,7 +256,7 @@ func resourceAppgateLocalDatabaseProviderRuleUpdate(d *schema.ResourceData, meta
 	req = req.Body(*originalLocalDatabaseProvider)
 	_, _, err = req.Execute()
 	if err != nil {
-		return fmt.Errorf("Could not update %s provider %w", identityProviderLocalDatabase, prettyPrintAPIError(err))
+		return apiErrorDiagnostics(fmt.Sprintf("Could not update %s provider", identityProviderLocalDatabase), err, resourceAppgateLocalDatabaseProvider().Schema)
 	}
-	return resourceAppgateLocalDatabaseProviderRuleRead(d, meta)
+	return resourceAppgateLocalDatabaseProviderRuleRead(ctx, d, meta)
 }
@@ -1,23 +1,27 @@
 package appgate
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
 
 	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func resourceAppgateOidcProvider() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceAppgateOidcProviderRuleCreate,
-		Read:   resourceAppgateOidcProviderRuleRead,
-		Update: resourceAppgateOidcProviderRuleUpdate,
-		Delete: identityProviderDelete,
+		CreateContext: resourceAppgateOidcProviderRuleCreate,
+		ReadContext:   resourceAppgateOidcProviderRuleRead,
+		UpdateContext: resourceAppgateOidcProviderRuleUpdate,
+		DeleteContext: identityProviderDelete,
+		CustomizeDiff: customdiff.All(tagsCustomizeDiff, etagCustomizeDiff, attributeVersionCustomizeDiff("appgatesdp_oidc_identity_provider")),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: importStateByNameOrTag("oidc identity provider", listIdentityProviderImportCandidates(identityProviderOidc)),
 		},
 
 		Timeouts: &schema.ResourceTimeout{
@@ -76,20 +80,19 @@ func resourceAppgateOidcProvider() *schema.Resource {
 	}
 }
 
-func resourceAppgateOidcProviderRuleCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateOidcProviderRuleCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Creating OidcProvider: %s", d.Get("name").(string))
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.OidcIdentityProvidersApi
-	ctx := BaseAuthContext(token)
-	currentVersion := meta.(*Client).ApplianceVersion
+	ctx = authContext(ctx, token)
 	provider := &openapi.ConfigurableIdentityProvider{}
 	provider.Type = identityProviderOidc
-	provider, err = readProviderFromConfig(d, *provider)
+	provider, err = readProviderFromConfig(d, meta, *provider)
 	if err != nil {
-		return fmt.Errorf("Failed to read and create basic identity provider for %s %w", identityProviderOidc, err)
+		return diag.FromErr(fmt.Errorf("Failed to read and create basic identity provider for %s %w", identityProviderOidc, err))
 	}
 	args := openapi.OidcProvider{}
 	// base
@@ -113,9 +116,6 @@ func resourceAppgateOidcProviderRuleCreate(d *schema.ResourceData, meta interfac
 		args.SetInactivityTimeoutMinutes(*provider.InactivityTimeoutMinutes)
 	}
 	if provider.NetworkInactivityTimeoutEnabled != nil {
-		if currentVersion.LessThan(Appliance61Version) {
-			return ErrNetworkInactivityTimeoutEnabled
-		}
 		args.SetNetworkInactivityTimeoutEnabled(provider.GetNetworkInactivityTimeoutEnabled())
 	}
 	if provider.IpPoolV4 != nil {
@@ -173,32 +173,33 @@ func resourceAppgateOidcProviderRuleCreate(d *schema.ResourceData, meta interfac
 	request := api.IdentityProvidersPost(ctx)
 	p, _, err := request.Body(args).Execute()
 	if err != nil {
-		return fmt.Errorf("Could not create %s provider %w", identityProviderOidc, prettyPrintAPIError(err))
+		return apiErrorDiagnostics(fmt.Sprintf("Could not create %s provider", identityProviderOidc), err, resourceAppgateOidcProvider().Schema)
 	}
 	d.SetId(p.GetId())
-	return resourceAppgateOidcProviderRuleRead(d, meta)
+	return resourceAppgateOidcProviderRuleRead(ctx, d, meta)
 }
 
-func resourceAppgateOidcProviderRuleRead(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateOidcProviderRuleRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Reading oidc identity provider id: %+v", d.Id())
 
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.OidcIdentityProvidersApi
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	request := api.IdentityProvidersIdGet(ctx, d.Id())
 	oidc, _, err := request.Execute()
 	if err != nil {
 		d.SetId("")
-		return fmt.Errorf("Failed to read LDAP Identity provider, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read LDAP Identity provider, %w", err))
 	}
+	d.Set("etag", objectETag(oidc))
 	d.Set("type", identityProviderOidc)
 	// base attributes
 	d.Set("name", oidc.Name)
 	d.Set("notes", oidc.Notes)
-	d.Set("tags", oidc.Tags)
+	setTags(d, meta, oidc.Tags)
 
 	// identity provider attributes
 
@@ -208,7 +209,7 @@ func resourceAppgateOidcProviderRuleRead(d *schema.ResourceData, meta interface{
 	}
 	if v, ok := oidc.GetOnBoarding2FAOk(); ok {
 		if err := d.Set("on_boarding_two_factor", flattenIdentityProviderOnboarding2fa(*v)); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
@@ -227,13 +228,13 @@ func resourceAppgateOidcProviderRuleRead(d *schema.ResourceData, meta interface{
 	d.Set("block_local_dns_requests", oidc.GetBlockLocalDnsRequests())
 	if v, ok := oidc.GetClaimMappingsOk(); ok {
 		if err := d.Set("claim_mappings", flattenIdentityProviderClaimsMappning(v)); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
 	if v, ok := oidc.GetOnDemandClaimMappingsOk(); ok {
 		if err := d.Set("on_demand_claim_mappings", flattenIdentityProviderOnDemandClaimsMappning(v)); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
@@ -266,18 +267,21 @@ func resourceAppgateOidcProviderRuleRead(d *schema.ResourceData, meta interface{
 	return nil
 }
 
-func resourceAppgateOidcProviderRuleUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateOidcProviderRuleUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Updating oidc identity provider id: %+v", d.Id())
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.OidcIdentityProvidersApi
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	request := api.IdentityProvidersIdGet(ctx, d.Id())
 	originalOidcProvider, _, err := request.Execute()
 	if err != nil {
-		return fmt.Errorf("Failed to read LDAP Identity provider, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read LDAP Identity provider, %w", err))
+	}
+	if diags := checkNotModified(d, meta, "OIDC identity provider", originalOidcProvider); diags.HasError() {
+		return diags
 	}
 	// base attributes
 	if d.HasChange("name") {
@@ -288,8 +292,8 @@ func resourceAppgateOidcProviderRuleUpdate(d *schema.ResourceData, meta interfac
 		originalOidcProvider.SetNotes(d.Get("notes").(string))
 	}
 
-	if d.HasChange("tags") {
-		originalOidcProvider.SetTags(schemaExtractTags(d))
+	if d.HasChanges("tags", "tags_all") {
+		originalOidcProvider.SetTags(schemaExtractTags(d, meta))
 	}
 
 	// identity provider attributes
@@ -303,7 +307,7 @@ func resourceAppgateOidcProviderRuleUpdate(d *schema.ResourceData, meta interfac
 		_, v := d.GetChange("on_boarding_two_factor")
 		onboarding, err := readOnBoardingTwoFactorFromConfig(v.([]interface{}))
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		originalOidcProvider.SetOnBoarding2FA(onboarding)
 	}
@@ -324,7 +328,7 @@ func resourceAppgateOidcProviderRuleUpdate(d *schema.ResourceData, meta interfac
 		_, v := d.GetChange("user_scripts")
 		us, err := readArrayOfStringsFromConfig(v.([]interface{}))
 		if err != nil {
-			return fmt.Errorf("Failed to read user_scripts %w", err)
+			return diag.FromErr(fmt.Errorf("Failed to read user_scripts %w", err))
 		}
 		originalOidcProvider.SetUserScripts(us)
 	}
@@ -332,7 +336,7 @@ func resourceAppgateOidcProviderRuleUpdate(d *schema.ResourceData, meta interfac
 		_, v := d.GetChange("dns_servers")
 		servers, err := readArrayOfStringsFromConfig(v.([]interface{}))
 		if err != nil {
-			return fmt.Errorf("Failed to read dns servers %w", err)
+			return diag.FromErr(fmt.Errorf("Failed to read dns servers %w", err))
 		}
 		originalOidcProvider.SetDnsServers(servers)
 	}
@@ -340,7 +344,7 @@ func resourceAppgateOidcProviderRuleUpdate(d *schema.ResourceData, meta interfac
 		_, v := d.GetChange("dns_search_domains")
 		servers, err := readArrayOfStringsFromConfig(v.([]interface{}))
 		if err != nil {
-			return fmt.Errorf("Failed to read dns search domains %w", err)
+			return diag.FromErr(fmt.Errorf("Failed to read dns search domains %w", err))
 		}
 		originalOidcProvider.SetDnsSearchDomains(servers)
 	}
@@ -382,9 +386,9 @@ func resourceAppgateOidcProviderRuleUpdate(d *schema.ResourceData, meta interfac
 	req = req.Body(*originalOidcProvider)
 	_, _, err = req.Execute()
 	if err != nil {
-		return fmt.Errorf("Could not update %s provider %w", identityProviderRadius, prettyPrintAPIError(err))
+		return apiErrorDiagnostics(fmt.Sprintf("Could not update %s provider", identityProviderRadius), err, resourceAppgateOidcProvider().Schema)
 	}
-	return resourceAppgateOidcProviderRuleRead(d, meta)
+	return resourceAppgateOidcProviderRuleRead(ctx, d, meta)
 }
 
 func readOidcProviderGoogleFromConfig(input []interface{}) []openapi.OidcProviderAllOfGoogle {
@@ -1,23 +1,27 @@
 package appgate
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
 
 	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func resourceAppgateRadiusProvider() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceAppgateRadiusProviderRuleCreate,
-		Read:   resourceAppgateRadiusProviderRuleRead,
-		Update: resourceAppgateRadiusProviderRuleUpdate,
-		Delete: identityProviderDelete,
+		CreateContext: resourceAppgateRadiusProviderRuleCreate,
+		ReadContext:   resourceAppgateRadiusProviderRuleRead,
+		UpdateContext: resourceAppgateRadiusProviderRuleUpdate,
+		DeleteContext: identityProviderDelete,
+		CustomizeDiff: customdiff.All(tagsCustomizeDiff, etagCustomizeDiff, attributeVersionCustomizeDiff("appgatesdp_radius_identity_provider")),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: importStateByNameOrTag("radius identity provider", listIdentityProviderImportCandidates(identityProviderRadius)),
 		},
 
 		Timeouts: &schema.ResourceTimeout{
@@ -40,9 +44,10 @@ func resourceAppgateRadiusProvider() *schema.Resource {
 				Required: true,
 			}
 			s["shared_secret"] = &schema.Schema{
-				Type:      schema.TypeString,
-				Required:  true,
-				Sensitive: true,
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				ExactlyOneOf: []string{"shared_secret", "shared_secret_wo"},
 			}
 			s["authentication_protocol"] = &schema.Schema{
 				Type:     schema.TypeString,
@@ -61,25 +66,24 @@ func resourceAppgateRadiusProvider() *schema.Resource {
 				},
 			}
 
-			return s
+			return mergeSchemaMaps(s, writeOnlySchemas("shared_secret", "RADIUS shared secret to authenticate to the server."))
 		}(),
 	}
 }
 
-func resourceAppgateRadiusProviderRuleCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateRadiusProviderRuleCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Creating RadiusProvider: %s", d.Get("name").(string))
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.RadiusIdentityProvidersApi
-	ctx := BaseAuthContext(token)
-	currentVersion := meta.(*Client).ApplianceVersion
+	ctx = authContext(ctx, token)
 	provider := &openapi.ConfigurableIdentityProvider{}
 	provider.Type = identityProviderRadius
-	provider, err = readProviderFromConfig(d, *provider)
+	provider, err = readProviderFromConfig(d, meta, *provider)
 	if err != nil {
-		return fmt.Errorf("Failed to read and create basic identity provider for %s %w", identityProviderRadius, err)
+		return diag.FromErr(fmt.Errorf("Failed to read and create basic identity provider for %s %w", identityProviderRadius, err))
 	}
 	args := openapi.RadiusProvider{}
 	// base
@@ -103,9 +107,6 @@ func resourceAppgateRadiusProviderRuleCreate(d *schema.ResourceData, meta interf
 		args.SetInactivityTimeoutMinutes(*provider.InactivityTimeoutMinutes)
 	}
 	if provider.NetworkInactivityTimeoutEnabled != nil {
-		if currentVersion.LessThan(Appliance61Version) {
-			return ErrNetworkInactivityTimeoutEnabled
-		}
 		args.SetNetworkInactivityTimeoutEnabled(provider.GetNetworkInactivityTimeoutEnabled())
 	}
 	if provider.IpPoolV4 != nil {
@@ -136,7 +137,7 @@ func resourceAppgateRadiusProviderRuleCreate(d *schema.ResourceData, meta interf
 	if v, ok := d.GetOk("hostnames"); ok {
 		hostnames, err := readArrayOfStringsFromConfig(v.([]interface{}))
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		args.SetHostnames(hostnames)
 	}
@@ -146,6 +147,13 @@ func resourceAppgateRadiusProviderRuleCreate(d *schema.ResourceData, meta interf
 	if v, ok := d.GetOk("shared_secret"); ok {
 		args.SetSharedSecret(v.(string))
 	}
+	sharedSecret, ok, diags := writeOnlyString(d, "shared_secret_wo")
+	if diags.HasError() {
+		return diags
+	}
+	if ok {
+		args.SetSharedSecret(sharedSecret)
+	}
 	if v, ok := d.GetOk("authentication_protocol"); ok {
 		args.SetAuthenticationProtocol(v.(string))
 	}
@@ -153,32 +161,33 @@ func resourceAppgateRadiusProviderRuleCreate(d *schema.ResourceData, meta interf
 	request := api.IdentityProvidersPost(ctx)
 	p, _, err := request.Body(args).Execute()
 	if err != nil {
-		return fmt.Errorf("Could not create %s provider %w", identityProviderRadius, prettyPrintAPIError(err))
+		return apiErrorDiagnostics(fmt.Sprintf("Could not create %s provider", identityProviderRadius), err, resourceAppgateRadiusProvider().Schema)
 	}
 	d.SetId(p.GetId())
-	return resourceAppgateRadiusProviderRuleRead(d, meta)
+	return resourceAppgateRadiusProviderRuleRead(ctx, d, meta)
 }
 
-func resourceAppgateRadiusProviderRuleRead(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateRadiusProviderRuleRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Reading radius identity provider id: %+v", d.Id())
 
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.RadiusIdentityProvidersApi
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	request := api.IdentityProvidersIdGet(ctx, d.Id())
 	radius, _, err := request.Execute()
 	if err != nil {
 		d.SetId("")
-		return fmt.Errorf("Failed to read LDAP Identity provider, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read LDAP Identity provider, %w", err))
 	}
+	d.Set("etag", objectETag(radius))
 	d.Set("type", identityProviderRadius)
 	// base attributes
 	d.Set("name", radius.Name)
 	d.Set("notes", radius.Notes)
-	d.Set("tags", radius.Tags)
+	setTags(d, meta, radius.Tags)
 
 	// identity provider attributes
 
@@ -188,7 +197,7 @@ func resourceAppgateRadiusProviderRuleRead(d *schema.ResourceData, meta interfac
 	}
 	if v, ok := radius.GetOnBoarding2FAOk(); ok {
 		if err := d.Set("on_boarding_two_factor", flattenIdentityProviderOnboarding2fa(*v)); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
@@ -207,13 +216,13 @@ func resourceAppgateRadiusProviderRuleRead(d *schema.ResourceData, meta interfac
 	d.Set("block_local_dns_requests", radius.GetBlockLocalDnsRequests())
 	if v, ok := radius.GetClaimMappingsOk(); ok {
 		if err := d.Set("claim_mappings", flattenIdentityProviderClaimsMappning(v)); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
 	if v, ok := radius.GetOnDemandClaimMappingsOk(); ok {
 		if err := d.Set("on_demand_claim_mappings", flattenIdentityProviderOnDemandClaimsMappning(v)); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
@@ -233,18 +242,21 @@ func resourceAppgateRadiusProviderRuleRead(d *schema.ResourceData, meta interfac
 	return nil
 }
 
-func resourceAppgateRadiusProviderRuleUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateRadiusProviderRuleUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Updating radius identity provider id: %+v", d.Id())
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.RadiusIdentityProvidersApi
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	request := api.IdentityProvidersIdGet(ctx, d.Id())
 	originalRadiusProvider, _, err := request.Execute()
 	if err != nil {
-		return fmt.Errorf("Failed to read LDAP Identity provider, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read LDAP Identity provider, %w", err))
+	}
+	if diags := checkNotModified(d, meta, "RADIUS identity provider", originalRadiusProvider); diags.HasError() {
+		return diags
 	}
 	// base attributes
 	if d.HasChange("name") {
@@ -255,8 +267,8 @@ func resourceAppgateRadiusProviderRuleUpdate(d *schema.ResourceData, meta interf
 		originalRadiusProvider.SetNotes(d.Get("notes").(string))
 	}
 
-	if d.HasChange("tags") {
-		originalRadiusProvider.SetTags(schemaExtractTags(d))
+	if d.HasChanges("tags", "tags_all") {
+		originalRadiusProvider.SetTags(schemaExtractTags(d, meta))
 	}
 
 	// identity provider attributes
@@ -270,7 +282,7 @@ func resourceAppgateRadiusProviderRuleUpdate(d *schema.ResourceData, meta interf
 		_, v := d.GetChange("on_boarding_two_factor")
 		onboarding, err := readOnBoardingTwoFactorFromConfig(v.([]interface{}))
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		originalRadiusProvider.SetOnBoarding2FA(onboarding)
 	}
@@ -291,7 +303,7 @@ func resourceAppgateRadiusProviderRuleUpdate(d *schema.ResourceData, meta interf
 		_, v := d.GetChange("user_scripts")
 		us, err := readArrayOfStringsFromConfig(v.([]interface{}))
 		if err != nil {
-			return fmt.Errorf("Failed to read user_scripts %w", err)
+			return diag.FromErr(fmt.Errorf("Failed to read user_scripts %w", err))
 		}
 		originalRadiusProvider.SetUserScripts(us)
 	}
@@ -299,7 +311,7 @@ func resourceAppgateRadiusProviderRuleUpdate(d *schema.ResourceData, meta interf
 		_, v := d.GetChange("dns_servers")
 		servers, err := readArrayOfStringsFromConfig(v.([]interface{}))
 		if err != nil {
-			return fmt.Errorf("Failed to read dns servers %w", err)
+			return diag.FromErr(fmt.Errorf("Failed to read dns servers %w", err))
 		}
 		originalRadiusProvider.SetDnsServers(servers)
 	}
@@ -307,7 +319,7 @@ func resourceAppgateRadiusProviderRuleUpdate(d *schema.ResourceData, meta interf
 		_, v := d.GetChange("dns_search_domains")
 		servers, err := readArrayOfStringsFromConfig(v.([]interface{}))
 		if err != nil {
-			return fmt.Errorf("Failed to read dns search domains %w", err)
+			return diag.FromErr(fmt.Errorf("Failed to read dns search domains %w", err))
 		}
 		originalRadiusProvider.SetDnsSearchDomains(servers)
 	}
@@ -330,7 +342,7 @@ func resourceAppgateRadiusProviderRuleUpdate(d *schema.ResourceData, meta interf
 		_, v := d.GetChange("hostnames")
 		servers, err := readArrayOfStringsFromConfig(v.([]interface{}))
 		if err != nil {
-			return fmt.Errorf("Failed to read hostnames %w", err)
+			return diag.FromErr(fmt.Errorf("Failed to read hostnames %w", err))
 		}
 		originalRadiusProvider.SetHostnames(servers)
 	}
@@ -339,13 +351,25 @@ func resourceAppgateRadiusProviderRuleUpdate(d *schema.ResourceData, meta interf
 		originalRadiusProvider.SetPort(int32(v.(int)))
 	}
 
-	originalRadiusProvider.SetSharedSecret(d.Get("shared_secret").(string))
+	// the controller never returns the shared secret, so shared_secret is sent on each update.
+	if v, ok := d.GetOk("shared_secret"); ok {
+		originalRadiusProvider.SetSharedSecret(v.(string))
+	}
+	if d.HasChange("shared_secret_wo_version") {
+		sharedSecret, ok, diags := writeOnlyString(d, "shared_secret_wo")
+		if diags.HasError() {
+			return diags
+		}
+		if ok {
+			originalRadiusProvider.SetSharedSecret(sharedSecret)
+		}
+	}
 
 	req := api.IdentityProvidersIdPut(ctx, d.Id())
 	req = req.Body(*originalRadiusProvider)
 	_, _, err = req.Execute()
 	if err != nil {
-		return fmt.Errorf("Could not update %s provider %w", identityProviderRadius, prettyPrintAPIError(err))
+		return apiErrorDiagnostics(fmt.Sprintf("Could not update %s provider", identityProviderRadius), err, resourceAppgateRadiusProvider().Schema)
 	}
-	return resourceAppgateRadiusProviderRuleRead(d, meta)
+	return resourceAppgateRadiusProviderRuleRead(ctx, d, meta)
 }
@@ -1,23 +1,27 @@
 package appgate
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
 
 	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func resourceAppgateSamlProvider() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceAppgateSamlProviderRuleCreate,
-		Read:   resourceAppgateSamlProviderRuleRead,
-		Update: resourceAppgateSamlProviderRuleUpdate,
-		Delete: identityProviderDelete,
+		CreateContext: resourceAppgateSamlProviderRuleCreate,
+		ReadContext:   resourceAppgateSamlProviderRuleRead,
+		UpdateContext: resourceAppgateSamlProviderRuleUpdate,
+		DeleteContext: identityProviderDelete,
+		CustomizeDiff: customdiff.All(tagsCustomizeDiff, etagCustomizeDiff, attributeVersionCustomizeDiff("appgatesdp_saml_identity_provider")),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: importStateByNameOrTag("saml identity provider", listIdentityProviderImportCandidates(identityProviderSaml)),
 		},
 
 		Timeouts: &schema.ResourceTimeout{
@@ -59,20 +63,19 @@ func resourceAppgateSamlProvider() *schema.Resource {
 	}
 }
 
-func resourceAppgateSamlProviderRuleCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateSamlProviderRuleCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Creating SamlProvider: %s", d.Get("name").(string))
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.SamlIdentityProvidersApi
-	ctx := BaseAuthContext(token)
-	currentVersion := meta.(*Client).ApplianceVersion
+	ctx = authContext(ctx, token)
 	provider := &openapi.ConfigurableIdentityProvider{}
 	provider.Type = identityProviderSaml
-	provider, err = readProviderFromConfig(d, *provider)
+	provider, err = readProviderFromConfig(d, meta, *provider)
 	if err != nil {
-		return fmt.Errorf("Failed to read and create basic identity provider for %s %w", identityProviderSaml, err)
+		return diag.FromErr(fmt.Errorf("Failed to read and create basic identity provider for %s %w", identityProviderSaml, err))
 	}
 
 	args := openapi.SamlProvider{}
@@ -95,9 +98,6 @@ func resourceAppgateSamlProviderRuleCreate(d *schema.ResourceData, meta interfac
 		args.SetInactivityTimeoutMinutes(*provider.InactivityTimeoutMinutes)
 	}
 	if provider.NetworkInactivityTimeoutEnabled != nil {
-		if currentVersion.LessThan(Appliance61Version) {
-			return ErrNetworkInactivityTimeoutEnabled
-		}
 		args.SetNetworkInactivityTimeoutEnabled(provider.GetNetworkInactivityTimeoutEnabled())
 	}
 	if provider.IpPoolV4 != nil {
@@ -146,32 +146,33 @@ func resourceAppgateSamlProviderRuleCreate(d *schema.ResourceData, meta interfac
 	request := api.IdentityProvidersPost(ctx)
 	p, _, err := request.Body(args).Execute()
 	if err != nil {
-		return fmt.Errorf("Could not create %s provider %w", identityProviderSaml, prettyPrintAPIError(err))
+		return apiErrorDiagnostics(fmt.Sprintf("Could not create %s provider", identityProviderSaml), err, resourceAppgateSamlProvider().Schema)
 	}
 	d.SetId(p.GetId())
-	return resourceAppgateSamlProviderRuleRead(d, meta)
+	return resourceAppgateSamlProviderRuleRead(ctx, d, meta)
 }
 
-func resourceAppgateSamlProviderRuleRead(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateSamlProviderRuleRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Reading saml identity provider id: %+v", d.Id())
 
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.SamlIdentityProvidersApi
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	request := api.IdentityProvidersIdGet(ctx, d.Id())
 	saml, _, err := request.Execute()
 	if err != nil {
 		d.SetId("")
-		return fmt.Errorf("Failed to read Saml Identity provider, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read Saml Identity provider, %w", err))
 	}
+	d.Set("etag", objectETag(saml))
 	d.Set("type", identityProviderSaml)
 	// base attributes
 	d.Set("name", saml.GetName())
 	d.Set("notes", saml.GetNotes())
-	d.Set("tags", saml.GetTags())
+	setTags(d, meta, saml.GetTags())
 
 	// identity provider attributes
 	d.Set("admin_provider", saml.GetAdminProvider())
@@ -180,7 +181,7 @@ func resourceAppgateSamlProviderRuleRead(d *schema.ResourceData, meta interface{
 	}
 	if v, ok := saml.GetOnBoarding2FAOk(); ok {
 		if err := d.Set("on_boarding_two_factor", flattenIdentityProviderOnboarding2fa(*v)); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
@@ -199,7 +200,7 @@ func resourceAppgateSamlProviderRuleRead(d *schema.ResourceData, meta interface{
 	d.Set("block_local_dns_requests", saml.GetBlockLocalDnsRequests())
 	if v, ok := saml.GetClaimMappingsOk(); ok {
 		if err := d.Set("claim_mappings", flattenIdentityProviderClaimsMappning(v)); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 	if v, ok := saml.GetOnDemandClaimMappingsOk(); ok {
@@ -216,18 +217,21 @@ func resourceAppgateSamlProviderRuleRead(d *schema.ResourceData, meta interface{
 	return nil
 }
 
-func resourceAppgateSamlProviderRuleUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateSamlProviderRuleUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Updating saml identity provider id: %+v", d.Id())
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.SamlIdentityProvidersApi
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	request := api.IdentityProvidersIdGet(ctx, d.Id())
 	originalSamlProvider, _, err := request.Execute()
 	if err != nil {
-		return fmt.Errorf("Failed to read Saml Identity provider, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read Saml Identity provider, %w", err))
+	}
+	if diags := checkNotModified(d, meta, "SAML identity provider", originalSamlProvider); diags.HasError() {
+		return diags
 	}
 	// base attributes
 	if d.HasChange("name") {
@@ -238,8 +242,8 @@ func resourceAppgateSamlProviderRuleUpdate(d *schema.ResourceData, meta interfac
 		originalSamlProvider.SetNotes(d.Get("notes").(string))
 	}
 
-	if d.HasChange("tags") {
-		originalSamlProvider.SetTags(schemaExtractTags(d))
+	if d.HasChanges("tags", "tags_all") {
+		originalSamlProvider.SetTags(schemaExtractTags(d, meta))
 	}
 
 	// identity provider attributes
@@ -253,7 +257,7 @@ func resourceAppgateSamlProviderRuleUpdate(d *schema.ResourceData, meta interfac
 		_, v := d.GetChange("on_boarding_two_factor")
 		onboarding, err := readOnBoardingTwoFactorFromConfig(v.([]interface{}))
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		originalSamlProvider.SetOnBoarding2FA(onboarding)
 	}
@@ -274,7 +278,7 @@ func resourceAppgateSamlProviderRuleUpdate(d *schema.ResourceData, meta interfac
 		_, v := d.GetChange("user_scripts")
 		scripts, err := readArrayOfStringsFromConfig(v.([]interface{}))
 		if err != nil {
-			return fmt.Errorf("Failed to read user_scripts %w", err)
+			return diag.FromErr(fmt.Errorf("Failed to read user_scripts %w", err))
 		}
 		originalSamlProvider.SetUserScripts(scripts)
 	}
@@ -282,7 +286,7 @@ func resourceAppgateSamlProviderRuleUpdate(d *schema.ResourceData, meta interfac
 		_, v := d.GetChange("dns_servers")
 		servers, err := readArrayOfStringsFromConfig(v.([]interface{}))
 		if err != nil {
-			return fmt.Errorf("Failed to read dns servers %w", err)
+			return diag.FromErr(fmt.Errorf("Failed to read dns servers %w", err))
 		}
 		originalSamlProvider.SetDnsServers(servers)
 	}
@@ -290,7 +294,7 @@ func resourceAppgateSamlProviderRuleUpdate(d *schema.ResourceData, meta interfac
 		_, v := d.GetChange("dns_search_domains")
 		servers, err := readArrayOfStringsFromConfig(v.([]interface{}))
 		if err != nil {
-			return fmt.Errorf("Failed to read dns search domains %w", err)
+			return diag.FromErr(fmt.Errorf("Failed to read dns search domains %w", err))
 		}
 		originalSamlProvider.SetDnsSearchDomains(servers)
 	}
@@ -332,7 +336,7 @@ func resourceAppgateSamlProviderRuleUpdate(d *schema.ResourceData, meta interfac
 	req = req.Body(*originalSamlProvider)
 	_, _, err = req.Execute()
 	if err != nil {
-		return fmt.Errorf("Could not update %s provider %w", identityProviderSaml, prettyPrintAPIError(err))
+		return apiErrorDiagnostics(fmt.Sprintf("Could not update %s provider", identityProviderSaml), err, resourceAppgateSamlProvider().Schema)
 	}
-	return resourceAppgateSamlProviderRuleRead(d, meta)
+	return resourceAppgateSamlProviderRuleRead(ctx, d, meta)
 }
@@ -9,17 +9,20 @@ import (
 
 	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func resourceAppgateIPPool() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceAppgateIPPoolCreate,
-		Read:   resourceAppgateIPPoolRead,
-		Update: resourceAppgateIPPoolUpdate,
-		Delete: resourceAppgateIPPoolDelete,
+		CreateContext: resourceAppgateIPPoolCreate,
+		ReadContext:   resourceAppgateIPPoolRead,
+		UpdateContext: resourceAppgateIPPoolUpdate,
+		DeleteContext: resourceAppgateIPPoolDelete,
+		CustomizeDiff: customdiff.All(tagsCustomizeDiff, etagCustomizeDiff, attributeVersionCustomizeDiff("appgatesdp_ip_pool")),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: importStateByNameOrTag("ip pool", listIpPoolImportCandidates),
 		},
 
 		Timeouts: &schema.ResourceTimeout{
@@ -32,6 +35,7 @@ func resourceAppgateIPPool() *schema.Resource {
 		Schema: map[string]*schema.Schema{
 
 			"ip_pool_id": resourceUUID(),
+			"etag":       etagSchema(),
 
 			"name": {
 				Type:        schema.TypeString,
@@ -48,6 +52,8 @@ func resourceAppgateIPPool() *schema.Resource {
 
 			"tags": tagsSchema(),
 
+			"tags_all": tagsAllSchema(),
+
 			"ip_version6": {
 				Type:     schema.TypeBool,
 				Optional: true,
@@ -64,18 +70,15 @@ func resourceAppgateIPPool() *schema.Resource {
 	}
 }
 
-func resourceAppgateIPPoolCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateIPPoolCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Creating Ip pool: %s", d.Get("name").(string))
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.IPPoolsApi
-	currentVersion := meta.(*Client).ApplianceVersion
 	args := openapi.IpPool{}
-	if v, ok := d.GetOk("ip_pool_id"); ok {
-		args.SetId(v.(string))
-	}
+	args.SetId(resourceObjectID(d, "ip_pool_id"))
 	args.SetName(d.Get("name").(string))
 	args.SetNotes(d.Get("notes").(string))
 
@@ -89,34 +92,32 @@ func resourceAppgateIPPoolCreate(d *schema.ResourceData, meta interface{}) error
 	if v, ok := d.GetOk("ranges"); ok {
 		ranges, err := readIPPoolRangesFromConfig(v.([]interface{}))
 		if err != nil {
-			return fmt.Errorf("Failed to read ip pool ranges %w", err)
+			return diag.FromErr(fmt.Errorf("Failed to read ip pool ranges %w", err))
 		}
 		args.SetRanges(ranges)
 	}
 
-	if currentVersion.GreaterThanOrEqual(Appliance61Version) {
-		if v, ok := d.GetOk("excluded_ranges"); ok {
-			excludedRanges, err := readIPPoolRangesFromConfig(v.([]interface{}))
-			if err != nil {
-				return fmt.Errorf("Failed to read ip pool excluded ranges %w", err)
-			}
-			args.SetExcludedRanges(excludedRanges)
+	if v, ok := d.GetOk("excluded_ranges"); ok {
+		excludedRanges, err := readIPPoolRangesFromConfig(v.([]interface{}))
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("Failed to read ip pool excluded ranges %w", err))
 		}
+		args.SetExcludedRanges(excludedRanges)
 	}
 
-	args.SetTags(schemaExtractTags(d))
+	args.SetTags(schemaExtractTags(d, meta))
 
-	request := api.IpPoolsPost(BaseAuthContext(token))
+	request := api.IpPoolsPost(authContext(ctx, token))
 	request = request.IpPool(args)
 	IPPool, _, err := request.Execute()
 	if err != nil {
-		return fmt.Errorf("Could not create Ip pool %w", prettyPrintAPIError(err))
+		return apiErrorDiagnostics("Could not create Ip pool", err, resourceAppgateIPPool().Schema)
 	}
 
 	d.SetId(IPPool.GetId())
 	d.Set("ip_pool_id", IPPool.GetId())
 
-	return resourceAppgateIPPoolRead(d, meta)
+	return resourceAppgateIPPoolRead(ctx, d, meta)
 }
 
 func readIPPoolRangesFromConfig(ranges []interface{}) ([]openapi.IpPoolRangeInner, error) {
@@ -139,14 +140,14 @@ func readIPPoolRangesFromConfig(ranges []interface{}) ([]openapi.IpPoolRangeInne
 	return result, nil
 }
 
-func resourceAppgateIPPoolRead(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateIPPoolRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Reading Ip pool id: %+v", d.Id())
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.IPPoolsApi
-	ctx := context.WithValue(context.Background(), openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	request := api.IpPoolsIdGet(ctx, d.Id())
 	IPPool, res, err := request.Execute()
 	if err != nil {
@@ -154,23 +155,24 @@ func resourceAppgateIPPoolRead(d *schema.ResourceData, meta interface{}) error {
 		if res != nil && res.StatusCode == http.StatusNotFound {
 			return nil
 		}
-		return fmt.Errorf("Failed to read Ip pool, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read Ip pool, %w", err))
 	}
+	d.Set("etag", objectETag(IPPool))
 	d.SetId(IPPool.GetId())
 	d.Set("ip_pool_id", IPPool.GetId())
 	d.Set("name", IPPool.GetName())
 	d.Set("notes", IPPool.GetNotes())
-	d.Set("tags", IPPool.GetTags())
+	setTags(d, meta, IPPool.GetTags())
 	d.Set("ip_version6", IPPool.IpVersion6)
 	d.Set("lease_time_days", IPPool.LeaseTimeDays)
 	if ranges, ok := IPPool.GetRangesOk(); ok {
 		if err = d.Set("ranges", flattenIPPoolRanges(ranges)); err != nil {
-			return fmt.Errorf("Failed to read ip pool ranges %w", err)
+			return diag.FromErr(fmt.Errorf("Failed to read ip pool ranges %w", err))
 		}
 	}
 	if ranges, ok := IPPool.GetExcludedRangesOk(); ok {
 		if err = d.Set("excluded_ranges", flattenIPPoolRanges(ranges)); err != nil {
-			return fmt.Errorf("Failed to read ip pool excluded ranges %w", err)
+			return diag.FromErr(fmt.Errorf("Failed to read ip pool excluded ranges %w", err))
 		}
 	}
 
@@ -194,18 +196,21 @@ func flattenIPPoolRanges(in []openapi.IpPoolRangeInner) []map[string]interface{}
 	return out
 }
 
-func resourceAppgateIPPoolUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateIPPoolUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Updating Ip pool: %s", d.Get("name").(string))
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.IPPoolsApi
-	ctx := context.WithValue(context.Background(), openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	request := api.IpPoolsIdGet(ctx, d.Id())
 	originalIPPool, _, err := request.Execute()
 	if err != nil {
-		return fmt.Errorf("Failed to read Ip pool while updating, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read Ip pool while updating, %w", err))
+	}
+	if diags := checkNotModified(d, meta, "Ip pool", originalIPPool); diags.HasError() {
+		return diags
 	}
 
 	if d.HasChange("name") {
@@ -216,8 +221,8 @@ func resourceAppgateIPPoolUpdate(d *schema.ResourceData, meta interface{}) error
 		originalIPPool.SetNotes(d.Get("notes").(string))
 	}
 
-	if d.HasChange("tags") {
-		originalIPPool.SetTags(schemaExtractTags(d))
+	if d.HasChanges("tags", "tags_all") {
+		originalIPPool.SetTags(schemaExtractTags(d, meta))
 	}
 
 	if d.HasChange("ip_version6") {
@@ -232,7 +237,7 @@ func resourceAppgateIPPoolUpdate(d *schema.ResourceData, meta interface{}) error
 		_, n := d.GetChange("ranges")
 		ranges, err := readIPPoolRangesFromConfig(n.([]interface{}))
 		if err != nil {
-			return fmt.Errorf("Failed to read ip pool ranges %w", err)
+			return diag.FromErr(fmt.Errorf("Failed to read ip pool ranges %w", err))
 		}
 		originalIPPool.SetRanges(ranges)
 	}
@@ -241,7 +246,7 @@ func resourceAppgateIPPoolUpdate(d *schema.ResourceData, meta interface{}) error
 		_, n := d.GetChange("excluded_ranges")
 		ranges, err := readIPPoolRangesFromConfig(n.([]interface{}))
 		if err != nil {
-			return fmt.Errorf("Failed to read ip pool excluded ranges %w", err)
+			return diag.FromErr(fmt.Errorf("Failed to read ip pool excluded ranges %w", err))
 		}
 		originalIPPool.SetExcludedRanges(ranges)
 	}
@@ -249,21 +254,21 @@ func resourceAppgateIPPoolUpdate(d *schema.ResourceData, meta interface{}) error
 	req := api.IpPoolsIdPut(ctx, d.Id())
 	_, _, err = req.IpPool(*originalIPPool).Execute()
 	if err != nil {
-		return fmt.Errorf("Could not update Ip pool %w", prettyPrintAPIError(err))
+		return apiErrorDiagnostics("Could not update Ip pool", err, resourceAppgateIPPool().Schema)
 	}
 
-	return resourceAppgateIPPoolRead(d, meta)
+	return resourceAppgateIPPoolRead(ctx, d, meta)
 }
 
-func resourceAppgateIPPoolDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateIPPoolDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Delete Ip pool: %s", d.Get("name").(string))
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.IPPoolsApi
-	if _, err := api.IpPoolsIdDelete(BaseAuthContext(token), d.Id()).Execute(); err != nil {
-		return fmt.Errorf("Could not delete Ip pool %w", prettyPrintAPIError(err))
+	if _, err := api.IpPoolsIdDelete(authContext(ctx, token), d.Id()).Execute(); err != nil {
+		return diag.FromErr(fmt.Errorf("Could not delete Ip pool %w", prettyPrintAPIError(err)))
 	}
 	d.SetId("")
 	return nil
@@ -109,7 +109,7 @@ func resourceAppgateLicenseCreate(ctx context.Context, d *schema.ResourceData, m
 	}
 	args.SetLicense(d.Get("license").(string))
 
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	license, _, err := api.LicensePost(ctx).LicenseImport(args).Execute()
 	if err != nil {
 		return AppendFromErr(diags, fmt.Errorf("Could not create license %w", prettyPrintAPIError(err)))
@@ -126,7 +126,7 @@ func resourceAppgateLicenseRead(ctx context.Context, d *schema.ResourceData, met
 	token, err := meta.(*Client).GetToken()
 	diags = AppendFromErr(diags, err)
 	api := meta.(*Client).API.LicenseApi
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	licenses, _, err := api.LicenseGet(ctx).Execute()
 	if err != nil {
 		d.SetId("")
@@ -182,7 +182,7 @@ func resourceAppgateLicenseDelete(ctx context.Context, d *schema.ResourceData, m
 	}
 	api := meta.(*Client).API.LicenseApi
 
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	if _, err := api.LicenseDelete(ctx).Execute(); err != nil {
 		return diag.FromErr(fmt.Errorf("Could not delete license %w", prettyPrintAPIError(err)))
 	}
@@ -10,6 +10,7 @@ import (
 	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -24,7 +25,7 @@ func resourceAppgateLocalUser() *schema.Resource {
 		UpdateContext: resourceAppgateLocalUserUpdate,
 		DeleteContext: resourceAppgateLocalUserDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Timeouts: &schema.ResourceTimeout{
@@ -34,9 +35,11 @@ func resourceAppgateLocalUser() *schema.Resource {
 		},
 
 		SchemaVersion: 1,
+		CustomizeDiff: customdiff.All(tagsCustomizeDiff, etagCustomizeDiff),
 		Schema: func() map[string]*schema.Schema {
 			return mergeSchemaMaps(baseEntitySchema(), map[string]*schema.Schema{
 				"local_user_id": resourceUUID(),
+				"etag":          etagSchema(),
 				"first_name": {
 					Type:     schema.TypeString,
 					Required: true,
@@ -68,7 +71,7 @@ func resourceAppgateLocalUser() *schema.Resource {
 					Computed: true,
 					Optional: true,
 				},
-			})
+			}, writeOnlySchemas("password", "Password for the user."))
 		}(),
 	}
 }
@@ -81,13 +84,11 @@ func resourceAppgateLocalUserCreate(ctx context.Context, d *schema.ResourceData,
 	}
 	api := meta.(*Client).API.LocalUsersApi
 	args := openapi.LocalUsersGetRequest{}
-	if v, ok := d.GetOk("local_user_id"); ok {
-		args.SetId(v.(string))
-	}
+	args.SetId(resourceObjectID(d, "local_user_id"))
 	args.SetName(d.Get("name").(string))
 	args.SetNotes(d.Get("notes").(string))
 
-	args.SetTags(schemaExtractTags(d))
+	args.SetTags(schemaExtractTags(d, meta))
 
 	if v, ok := d.GetOk("first_name"); ok {
 		args.SetFirstName(v.(string))
@@ -99,6 +100,13 @@ func resourceAppgateLocalUserCreate(ctx context.Context, d *schema.ResourceData,
 	if v, ok := d.GetOk("password"); ok {
 		args.SetPassword(v.(string))
 	}
+	password, ok, diags := writeOnlyString(d, "password_wo")
+	if diags.HasError() {
+		return diags
+	}
+	if ok {
+		args.SetPassword(password)
+	}
 	if v, ok := d.GetOk("email"); ok {
 		args.SetEmail(v.(string))
 	}
@@ -116,7 +124,7 @@ func resourceAppgateLocalUserCreate(ctx context.Context, d *schema.ResourceData,
 		args.SetLockStart(t)
 	}
 
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	localUser, _, err := api.LocalUsersPost(ctx).LocalUsersGetRequest(args).Execute()
 	if err != nil {
 		return diag.FromErr(prettyPrintAPIError(err))
@@ -139,7 +147,7 @@ func resourceAppgateLocalUserRead(ctx context.Context, d *schema.ResourceData, m
 		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.LocalUsersApi
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	localUser, response, err := api.LocalUsersIdGet(ctx, d.Id()).Execute()
 	if err != nil {
 		d.SetId("")
@@ -149,10 +157,11 @@ func resourceAppgateLocalUserRead(ctx context.Context, d *schema.ResourceData, m
 		return diag.FromErr(prettyPrintAPIError(err))
 	}
 	d.SetId(localUser.GetId())
+	d.Set("etag", objectETag(localUser))
 	d.Set("local_user_id", localUser.GetId())
 	d.Set("name", localUser.GetName())
 	d.Set("notes", localUser.GetNotes())
-	d.Set("tags", localUser.GetTags())
+	setTags(d, meta, localUser.GetTags())
 	d.Set("first_name", localUser.GetFirstName())
 	d.Set("last_name", localUser.GetLastName())
 	d.Set("email", localUser.GetEmail())
@@ -173,11 +182,14 @@ func resourceAppgateLocalUserUpdate(ctx context.Context, d *schema.ResourceData,
 		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.LocalUsersApi
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	user, _, err := api.LocalUsersIdGet(ctx, d.Id()).Execute()
 	if err != nil {
 		return diag.FromErr(prettyPrintAPIError(err))
 	}
+	if diags := checkNotModified(d, meta, "Local user", user); diags.HasError() {
+		return diags
+	}
 
 	if d.HasChange("name") {
 		user.SetName(d.Get("name").(string))
@@ -185,8 +197,8 @@ func resourceAppgateLocalUserUpdate(ctx context.Context, d *schema.ResourceData,
 	if d.HasChange("notes") {
 		user.SetNotes(d.Get("notes").(string))
 	}
-	if d.HasChange("tags") {
-		user.SetTags(schemaExtractTags(d))
+	if d.HasChanges("tags", "tags_all") {
+		user.SetTags(schemaExtractTags(d, meta))
 	}
 	if d.HasChange("first_name") {
 		user.SetFirstName(d.Get("first_name").(string))
@@ -201,6 +213,15 @@ func resourceAppgateLocalUserUpdate(ctx context.Context, d *schema.ResourceData,
 	if d.HasChange("phone") {
 		user.SetPhone(d.Get("phone").(string))
 	}
+	if d.HasChange("password_wo_version") {
+		password, ok, diags := writeOnlyString(d, "password_wo")
+		if diags.HasError() {
+			return diags
+		}
+		if ok {
+			user.SetPassword(password)
+		}
+	}
 	if d.HasChange("failed_login_attempts") {
 		user.SetFailedLoginAttempts(float32(d.Get("failed_login_attempts").(int)))
 	}
@@ -215,10 +236,10 @@ func resourceAppgateLocalUserUpdate(ctx context.Context, d *schema.ResourceData,
 		}
 	}
 
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	_, _, err = api.LocalUsersIdPut(ctx, d.Id()).LocalUser(*user).Execute()
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("could not update Local user %w", prettyPrintAPIError(err)))
+		return apiErrorDiagnostics("could not update Local user", err, resourceAppgateLocalUser().Schema)
 	}
 	return resourceAppgateLocalUserRead(ctx, d, meta)
 }
@@ -230,7 +251,7 @@ func resourceAppgateLocalUserDelete(ctx context.Context, d *schema.ResourceData,
 		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.LocalUsersApi
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	if _, err := api.LocalUsersIdDelete(ctx, d.Id()).Execute(); err != nil {
 		return diag.FromErr(fmt.Errorf("could not delete Local user %w", prettyPrintAPIError(err)))
 	}
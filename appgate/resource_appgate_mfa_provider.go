@@ -1,6 +1,7 @@
 package appgate
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -8,17 +9,19 @@ import (
 
 	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func resourceAppgateMfaProvider() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceAppgateMfaProviderCreate,
-		Read:   resourceAppgateMfaProviderRead,
-		Update: resourceAppgateMfaProviderUpdate,
-		Delete: resourceAppgateMfaProviderDelete,
+		CreateContext: resourceAppgateMfaProviderCreate,
+		ReadContext:   resourceAppgateMfaProviderRead,
+		UpdateContext: resourceAppgateMfaProviderUpdate,
+		DeleteContext: resourceAppgateMfaProviderDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Timeouts: &schema.ResourceTimeout{
@@ -28,9 +31,11 @@ func resourceAppgateMfaProvider() *schema.Resource {
 		},
 
 		SchemaVersion: 1,
-		Schema: map[string]*schema.Schema{
+		CustomizeDiff: customdiff.All(tagsCustomizeDiff, etagCustomizeDiff),
+		Schema: mergeSchemaMaps(map[string]*schema.Schema{
 
 			"mfa_provider_id": resourceUUID(),
+			"etag":            etagSchema(),
 			"name": {
 				Type:        schema.TypeString,
 				Description: "Name of the object.",
@@ -46,6 +51,8 @@ func resourceAppgateMfaProvider() *schema.Resource {
 
 			"tags": tagsSchema(),
 
+			"tags_all": tagsAllSchema(),
+
 			"type": {
 				Type:     schema.TypeString,
 				Required: true,
@@ -137,31 +144,29 @@ func resourceAppgateMfaProvider() *schema.Resource {
 				Sensitive: true,
 				Computed:  true,
 			},
-		},
+		}, writeOnlySchemas("shared_secret", "RADIUS shared secret to authenticate to the server.")),
 	}
 }
 
-func resourceAppgateMfaProviderCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateMfaProviderCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Creating MFA provider: %s", d.Get("name").(string))
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.MFAProvidersApi
 	args := openapi.NewMfaProviderWithDefaults()
-	if v, ok := d.GetOk("mfa_provider_id"); ok {
-		args.SetId(v.(string))
-	}
+	args.SetId(resourceObjectID(d, "mfa_provider_id"))
 	args.SetName(d.Get("name").(string))
 	args.SetNotes(d.Get("notes").(string))
-	args.SetTags(schemaExtractTags(d))
+	args.SetTags(schemaExtractTags(d, meta))
 	if v, ok := d.GetOk("type"); ok {
 		args.SetType(v.(string))
 	}
 	if v, ok := d.GetOk("hostnames"); ok {
 		hostnames, err := readArrayOfStringsFromConfig(v.(*schema.Set).List())
 		if err != nil {
-			return fmt.Errorf("Could not read hostnames %w", err)
+			return diag.FromErr(fmt.Errorf("Could not read hostnames %w", err))
 		}
 		args.SetHostnames(hostnames)
 	}
@@ -171,6 +176,13 @@ func resourceAppgateMfaProviderCreate(d *schema.ResourceData, meta interface{})
 	if v, ok := d.GetOk("shared_secret"); ok {
 		args.SetSharedSecret(v.(string))
 	}
+	sharedSecret, ok, diags := writeOnlyString(d, "shared_secret_wo")
+	if diags.HasError() {
+		return diags
+	}
+	if ok {
+		args.SetSharedSecret(sharedSecret)
+	}
 	if v, ok := d.GetOk("input_type"); ok {
 		args.SetInputType(v.(string))
 	}
@@ -190,28 +202,28 @@ func resourceAppgateMfaProviderCreate(d *schema.ResourceData, meta interface{})
 		args.SetChallengeSharedSecret(v.(string))
 	}
 
-	request := api.MfaProvidersPost(BaseAuthContext(token))
+	request := api.MfaProvidersPost(authContext(ctx, token))
 	request = request.MfaProvider(*args)
 
 	mfaProvider, _, err := request.Execute()
 	if err != nil {
-		return fmt.Errorf("Could not create MFA provider %w", prettyPrintAPIError(err))
+		return apiErrorDiagnostics("Could not create MFA provider", err, resourceAppgateMfaProvider().Schema)
 	}
 
 	d.SetId(mfaProvider.GetId())
 	d.Set("mfa_provider_id", mfaProvider.GetId())
 
-	return resourceAppgateMfaProviderRead(d, meta)
+	return resourceAppgateMfaProviderRead(ctx, d, meta)
 }
 
-func resourceAppgateMfaProviderRead(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateMfaProviderRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Reading MFA provider id: %+v", d.Id())
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.MFAProvidersApi
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	request := api.MfaProvidersIdGet(ctx, d.Id())
 	mfaProvider, res, err := request.Execute()
 	if err != nil {
@@ -219,13 +231,14 @@ func resourceAppgateMfaProviderRead(d *schema.ResourceData, meta interface{}) er
 		if res != nil && res.StatusCode == http.StatusNotFound {
 			return nil
 		}
-		return fmt.Errorf("Failed to read MFA provider, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read MFA provider, %w", err))
 	}
 	d.SetId(mfaProvider.GetId())
+	d.Set("etag", objectETag(mfaProvider))
 	d.Set("mfa_provider_id", mfaProvider.GetId())
 	d.Set("name", mfaProvider.GetName())
 	d.Set("notes", mfaProvider.GetNotes())
-	d.Set("tags", mfaProvider.GetTags())
+	setTags(d, meta, mfaProvider.GetTags())
 	d.Set("hostnames", mfaProvider.GetHostnames())
 	d.Set("port", mfaProvider.GetPort())
 	d.Set("input_type", mfaProvider.GetInputType())
@@ -238,18 +251,21 @@ func resourceAppgateMfaProviderRead(d *schema.ResourceData, meta interface{}) er
 	return nil
 }
 
-func resourceAppgateMfaProviderUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateMfaProviderUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Updating MFA provider: %s", d.Get("name").(string))
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.MFAProvidersApi
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	request := api.MfaProvidersIdGet(ctx, d.Id())
 	originalMfaProvider, _, err := request.Execute()
 	if err != nil {
-		return fmt.Errorf("Failed to read MFA provider while updating, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read MFA provider while updating, %w", err))
+	}
+	if diags := checkNotModified(d, meta, "MFA provider", originalMfaProvider); diags.HasError() {
+		return diags
 	}
 
 	if d.HasChange("name") {
@@ -259,8 +275,8 @@ func resourceAppgateMfaProviderUpdate(d *schema.ResourceData, meta interface{})
 	if d.HasChange("notes") {
 		originalMfaProvider.SetNotes(d.Get("notes").(string))
 	}
-	if d.HasChange("tags") {
-		originalMfaProvider.SetTags(schemaExtractTags(d))
+	if d.HasChanges("tags", "tags_all") {
+		originalMfaProvider.SetTags(schemaExtractTags(d, meta))
 	}
 	if d.HasChange("type") {
 		originalMfaProvider.SetType(d.Get("notes").(string))
@@ -269,7 +285,7 @@ func resourceAppgateMfaProviderUpdate(d *schema.ResourceData, meta interface{})
 		_, v := d.GetChange("hostnames")
 		hostnames, err := readArrayOfStringsFromConfig(v.(*schema.Set).List())
 		if err != nil {
-			return fmt.Errorf("Failed to read hostnames %w", err)
+			return diag.FromErr(fmt.Errorf("Failed to read hostnames %w", err))
 		}
 		originalMfaProvider.SetHostnames(hostnames)
 	}
@@ -279,6 +295,15 @@ func resourceAppgateMfaProviderUpdate(d *schema.ResourceData, meta interface{})
 	if d.HasChange("shared_secret") {
 		originalMfaProvider.SetSharedSecret(d.Get("shared_secret").(string))
 	}
+	if d.HasChange("shared_secret_wo_version") {
+		sharedSecret, ok, diags := writeOnlyString(d, "shared_secret_wo")
+		if diags.HasError() {
+			return diags
+		}
+		if ok {
+			originalMfaProvider.SetSharedSecret(sharedSecret)
+		}
+	}
 	if d.HasChange("input_type") {
 		originalMfaProvider.SetInputType(d.Get("input_type").(string))
 	}
@@ -301,20 +326,20 @@ func resourceAppgateMfaProviderUpdate(d *schema.ResourceData, meta interface{})
 	req = req.MfaProvider(*originalMfaProvider)
 	_, _, err = req.Execute()
 	if err != nil {
-		return fmt.Errorf("Could not update MFA provider %w", prettyPrintAPIError(err))
+		return apiErrorDiagnostics("Could not update MFA provider", err, resourceAppgateMfaProvider().Schema)
 	}
-	return resourceAppgateMfaProviderRead(d, meta)
+	return resourceAppgateMfaProviderRead(ctx, d, meta)
 }
 
-func resourceAppgateMfaProviderDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateMfaProviderDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Delete MFA provider: %s", d.Get("name").(string))
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.MFAProvidersApi
-	if _, err := api.MfaProvidersIdDelete(BaseAuthContext(token), d.Id()).Execute(); err != nil {
-		return fmt.Errorf("Could not delete MFA provider %w", prettyPrintAPIError(err))
+	if _, err := api.MfaProvidersIdDelete(authContext(ctx, token), d.Id()).Execute(); err != nil {
+		return diag.FromErr(fmt.Errorf("Could not delete MFA provider %w", prettyPrintAPIError(err)))
 	}
 	d.SetId("")
 	return nil
@@ -1,23 +1,27 @@
 package appgate
 
 import (
+	"context"
 	"fmt"
 	"log"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func resourceAdminMfaSettings() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceAdminMfaSettingsCreate,
-		Read:   resourceAdminMfaSettingsRead,
-		Update: resourceAdminMfaSettingsUpdate,
-		Delete: resourceAdminMfaSettingsDelete,
+		CreateContext: resourceAdminMfaSettingsCreate,
+		ReadContext:   resourceAdminMfaSettingsRead,
+		UpdateContext: resourceAdminMfaSettingsUpdate,
+		DeleteContext: resourceAdminMfaSettingsDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 		SchemaVersion: 1,
+		CustomizeDiff: etagCustomizeDiff,
 		Schema: map[string]*schema.Schema{
+			"etag": etagSchema(),
 			"provider_id": {
 				Type:        schema.TypeString,
 				Description: "The MFA provider ID to use during Multi-Factor Authentication. If null, Admin MFA is disabled.",
@@ -33,46 +37,50 @@ func resourceAdminMfaSettings() *schema.Resource {
 	}
 }
 
-func resourceAdminMfaSettingsCreate(d *schema.ResourceData, meta interface{}) error {
-	return resourceAdminMfaSettingsUpdate(d, meta)
+func resourceAdminMfaSettingsCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return resourceAdminMfaSettingsUpdate(ctx, d, meta)
 }
 
-func resourceAdminMfaSettingsRead(d *schema.ResourceData, meta interface{}) error {
+func resourceAdminMfaSettingsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Reading MFA admin settings id: %+v", d.Id())
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.MFAForAdminsApi
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	request := api.AdminMfaSettingsGet(ctx)
 	settings, _, err := request.Execute()
 	if err != nil {
 		d.SetId("")
-		return fmt.Errorf("Failed to read MFA admin settings, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read MFA admin settings, %w", err))
 	}
 	d.SetId("admin_mfa_settings")
+	d.Set("etag", objectETag(settings))
 	if v, o := settings.GetProviderIdOk(); o {
 		d.Set("provider_id", v)
 	}
 	if err := d.Set("exempted_users", settings.GetExemptedUsers()); err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	return nil
 }
 
-func resourceAdminMfaSettingsUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceAdminMfaSettingsUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Updating MFA admin settings")
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.MFAForAdminsApi
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	request := api.AdminMfaSettingsGet(ctx)
 	originalsettings, _, err := request.Execute()
 	if err != nil {
-		return fmt.Errorf("Failed to read MFA admin settings while updating, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read MFA admin settings while updating, %w", err))
+	}
+	if diags := checkNotModified(d, meta, "Admin MFA settings", originalsettings); diags.HasError() {
+		return diags
 	}
 	d.SetId("admin_mfa_settings")
 
@@ -83,7 +91,7 @@ func resourceAdminMfaSettingsUpdate(d *schema.ResourceData, meta interface{}) er
 		_, v := d.GetChange("exempted_users")
 		exemptedUsers, err := readArrayOfStringsFromConfig(v.([]interface{}))
 		if err != nil {
-			return fmt.Errorf("Failed to read exempted_users %w", err)
+			return diag.FromErr(fmt.Errorf("Failed to read exempted_users %w", err))
 		}
 		originalsettings.SetExemptedUsers(exemptedUsers)
 	}
@@ -92,22 +100,22 @@ func resourceAdminMfaSettingsUpdate(d *schema.ResourceData, meta interface{}) er
 	req := api.AdminMfaSettingsPut(ctx)
 	_, err = req.AdminMfaSettings(*originalsettings).Execute()
 	if err != nil {
-		return fmt.Errorf("Could not update MFA admin settings %w", prettyPrintAPIError(err))
+		return diag.FromErr(fmt.Errorf("Could not update MFA admin settings %w", prettyPrintAPIError(err)))
 	}
 
-	return resourceAdminMfaSettingsRead(d, meta)
+	return resourceAdminMfaSettingsRead(ctx, d, meta)
 }
 
-func resourceAdminMfaSettingsDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceAdminMfaSettingsDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Delete/Resetting MFA admin settings")
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.MFAForAdminsApi
 
-	if _, err := api.AdminMfaSettingsDelete(BaseAuthContext(token)).Execute(); err != nil {
-		return fmt.Errorf("Could reset MFA admin settings %w", prettyPrintAPIError(err))
+	if _, err := api.AdminMfaSettingsDelete(authContext(ctx, token)).Execute(); err != nil {
+		return diag.FromErr(fmt.Errorf("Could reset MFA admin settings %w", prettyPrintAPIError(err)))
 	}
 	d.SetId("")
 	return nil
@@ -1,19 +1,32 @@
 package appgate
 
 import (
-	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
-
-	"github.com/hashicorp/go-version"
+	"regexp"
 
 	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
-	"github.com/appgate/terraform-provider-appgatesdp/appgate/hashcode"
-
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/google/uuid"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 )
 
 const (
@@ -22,192 +35,240 @@ const (
 	emptyPolicyExpression = "//Generated by criteria builder, Operator: and\nvar result = false;\nreturn result;"
 )
 
-func basePolicySchema() map[string]*schema.Schema {
-	return mergeSchemaMaps(baseEntitySchema(), baseTagsSchema(), map[string]*schema.Schema{
-		"policy_id": resourceUUID(),
+const (
+	PolicyTypeAccess string = "Access"
+	PolicyTypeDevice string = "Device"
+	PolicyTypeDns    string = "Dns"
+	PolicyTypeAdmin  string = "Admin"
+	PolicyTypeMixed  string = "Mixed"
+	PolicyTypeStop   string = "Stop"
+)
 
-		"name": {
-			Type:        schema.TypeString,
-			Description: "Name of the object.",
-			Required:    true,
-		},
-		"notes": {
-			Type:        schema.TypeString,
-			Description: "Name of the object.",
-			Default:     DefaultDescription,
-			Optional:    true,
-		},
-		"disabled": {
-			Type:     schema.TypeBool,
-			Default:  false,
-			Optional: true,
-		},
-		"expression": {
-			Type:     schema.TypeString,
-			Required: true,
-		},
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
 
-		"type": {
-			Type:        schema.TypeString,
-			Optional:    true,
-			Computed:    true,
-			Description: "Type of the Policy. It is informational and not enforced.",
-		},
-	})
+// policySchemaGroup is a group of attributes and blocks that is shared by several policy resources.
+type policySchemaGroup struct {
+	Attributes map[string]schema.Attribute
+	Blocks     map[string]schema.Block
 }
 
-func basePolicyEntitlementAttributes() map[string]*schema.Schema {
-	return map[string]*schema.Schema{
-		"entitlements": {
-			Type:     schema.TypeSet,
-			Optional: true,
-			Set:      schema.HashString,
-			Elem:     &schema.Schema{Type: schema.TypeString},
-		},
+// policySchema returns the schema of a policy resource from the base attributes and groups.
+func policySchema(version int64, base policySchemaGroup, groups ...policySchemaGroup) schema.Schema {
+	s := schema.Schema{
+		Version:    version,
+		Attributes: map[string]schema.Attribute{},
+		Blocks:     map[string]schema.Block{},
+	}
+	for _, g := range append([]policySchemaGroup{base}, groups...) {
+		for k, v := range g.Attributes {
+			s.Attributes[k] = v
+		}
+		for k, v := range g.Blocks {
+			s.Blocks[k] = v
+		}
+	}
+	return s
+}
 
-		"entitlement_links": {
-			Type:     schema.TypeSet,
-			Optional: true,
-			Set:      schema.HashString,
-			Elem:     &schema.Schema{Type: schema.TypeString},
+func basePolicySchema() policySchemaGroup {
+	return policySchemaGroup{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:      true,
+				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"policy_id": schema.StringAttribute{
+				Description: "ID of the object.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplaceIfConfigured(),
+					stringplanmodifier.UseStateForUnknown(),
+				},
+				Validators: []validator.String{stringvalidator.RegexMatches(uuidPattern, "must be a UUID")},
+			},
+			"name": schema.StringAttribute{
+				Description: "Name of the object.",
+				Required:    true,
+			},
+			"notes": schema.StringAttribute{
+				Description: "Notes for the object. Used for documentation purposes.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(DefaultDescription),
+			},
+			"disabled": schema.BoolAttribute{
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(false),
+			},
+			"expression": schema.StringAttribute{
+				Required: true,
+			},
+			"type": schema.StringAttribute{
+				Description:   "Type of the Policy. It is informational and not enforced.",
+				Optional:      true,
+				Computed:      true,
+				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"tags": schema.SetAttribute{
+				Description: "Array of tags.",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"tags_all": schema.SetAttribute{
+				Description: tagsAllSchema().Description,
+				ElementType: types.StringType,
+				Computed:    true,
+			},
 		},
 	}
 }
-func basePolicyRingfenceAttributes() map[string]*schema.Schema {
-	return map[string]*schema.Schema{
-		"ringfence_rules": {
-			Type:     schema.TypeSet,
-			Optional: true,
-			Set:      schema.HashString,
-			Elem:     &schema.Schema{Type: schema.TypeString},
+
+// typedPolicySchema is basePolicySchema for the resources of one policy type, the type is set
+// by the resource and the expression is optional.
+func typedPolicySchema() policySchemaGroup {
+	s := basePolicySchema()
+	s.Attributes["expression"] = schema.StringAttribute{
+		Optional: true,
+		Computed: true,
+		Default:  stringdefault.StaticString(emptyPolicyExpression),
+	}
+	// Type is computed in Create
+	s.Attributes["type"] = schema.StringAttribute{
+		Description:   "Type of the Policy. It is informational and not enforced.",
+		Computed:      true,
+		PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+	}
+	return s
+}
+
+func policyStringSet() schema.SetAttribute {
+	return schema.SetAttribute{
+		ElementType: types.StringType,
+		Optional:    true,
+	}
+}
+
+// optionalComputedBool is a nested bool that keeps the value from the controller if it is not configured.
+func optionalComputedBool() schema.BoolAttribute {
+	return schema.BoolAttribute{
+		Optional:      true,
+		Computed:      true,
+		PlanModifiers: []planmodifier.Bool{boolplanmodifier.UseStateForUnknown()},
+	}
+}
+
+// optionalComputedString is a nested string that keeps the value from the controller if it is not configured.
+func optionalComputedString() schema.StringAttribute {
+	return schema.StringAttribute{
+		Optional:      true,
+		Computed:      true,
+		PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+	}
+}
+
+func basePolicyEntitlementAttributes() policySchemaGroup {
+	return policySchemaGroup{
+		Attributes: map[string]schema.Attribute{
+			"entitlements":      policyStringSet(),
+			"entitlement_links": policyStringSet(),
 		},
+	}
+}
 
-		"ringfence_rule_links": {
-			Type:     schema.TypeSet,
-			Optional: true,
-			Set:      schema.HashString,
-			Elem:     &schema.Schema{Type: schema.TypeString},
+func basePolicyRingfenceAttributes() policySchemaGroup {
+	return policySchemaGroup{
+		Attributes: map[string]schema.Attribute{
+			"ringfence_rules":      policyStringSet(),
+			"ringfence_rule_links": policyStringSet(),
 		},
 	}
 }
-func basePolicyAdminAttributes() map[string]*schema.Schema {
-	return map[string]*schema.Schema{
-		"administrative_roles": {
-			Type:     schema.TypeSet,
-			Optional: true,
-			Set:      schema.HashString,
-			Elem:     &schema.Schema{Type: schema.TypeString},
+
+func basePolicyAdminAttributes() policySchemaGroup {
+	return policySchemaGroup{
+		Attributes: map[string]schema.Attribute{
+			"administrative_roles": policyStringSet(),
 		},
 	}
 }
-func basePolicyDeviceAttributes() map[string]*schema.Schema {
-	return map[string]*schema.Schema{
-		"proxy_auto_config": {
-			Type:             schema.TypeList,
-			MaxItems:         1,
-			Optional:         true,
-			Description:      "Client configures PAC URL on the client OS.",
-			DiffSuppressFunc: suppressMissingOptionalConfigurationBlock,
-			Elem: &schema.Resource{
-				Schema: map[string]*schema.Schema{
-					"enabled": {
-						Type:     schema.TypeBool,
-						Optional: true,
-						Computed: true,
-					},
-					"url": {
-						Type:     schema.TypeString,
-						Optional: true,
-					},
-					"persist": {
-						Type:     schema.TypeBool,
-						Optional: true,
-						Computed: true,
-					},
-				},
+
+func basePolicyDeviceAttributes() policySchemaGroup {
+	return policySchemaGroup{
+		Attributes: map[string]schema.Attribute{
+			"tamper_proofing": optionalComputedBool(),
+			// v18 attribute
+			"custom_client_help_url": schema.StringAttribute{
+				Optional: true,
 			},
 		},
-
-		"trusted_network_check": {
-			Type:             schema.TypeList,
-			MaxItems:         1,
-			Optional:         true,
-			Description:      "Client suspends operations when it's in a trusted network.",
-			DiffSuppressFunc: suppressMissingOptionalConfigurationBlock,
-			Elem: &schema.Resource{
-				Schema: map[string]*schema.Schema{
-					"enabled": {
-						Type:     schema.TypeBool,
-						Optional: true,
-						Computed: true,
+		Blocks: map[string]schema.Block{
+			"proxy_auto_config": schema.ListNestedBlock{
+				Description: "Client configures PAC URL on the client OS.",
+				Validators:  []validator.List{listvalidator.SizeAtMost(1)},
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"enabled": optionalComputedBool(),
+						"url": schema.StringAttribute{
+							Optional: true,
+						},
+						"persist": optionalComputedBool(),
 					},
-					"dns_suffix": {
-						Type:     schema.TypeString,
-						Optional: true,
+				},
+			},
+			"trusted_network_check": schema.ListNestedBlock{
+				Description: "Client suspends operations when it's in a trusted network.",
+				Validators:  []validator.List{listvalidator.SizeAtMost(1)},
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"enabled": optionalComputedBool(),
+						"dns_suffix": schema.StringAttribute{
+							Optional: true,
+						},
 					},
 				},
 			},
 		},
-
-		"tamper_proofing": {
-			Type:     schema.TypeBool,
-			Computed: true,
-			Optional: true,
-		},
-
-		// v18 attribute
-		"custom_client_help_url": {
-			Type:     schema.TypeString,
-			Optional: true,
-		},
 	}
 }
 
 // basePolicyDeploymentSiteAttributes is used in DNS and Access policies
-func basePolicyDeploymentSiteAttributes() map[string]*schema.Schema {
-	return map[string]*schema.Schema{
-		"override_site": {
-			Type:     schema.TypeString,
-			Optional: true,
-		},
-		"override_site_claim": {
-			Type:        schema.TypeString,
-			Description: "The path of a claim that contains the UUID of an override site. It should be defined as 'claims.xxx.xxx' or 'claims.xxx.xxx.xxx'1.",
-			Optional:    true,
-		},
-		"override_nearest_site": {
-			Type:        schema.TypeBool,
-			Description: "Overrides the Entitlements Site according to location of the client and Sites where this feature is enabled.",
-			Optional:    true,
-		},
-		"apply_fallback_site": {
-			Type:        schema.TypeBool,
-			Description: "The Entitlements in this Policy will be available in the fallback Sites if the corresponding Sites are configured accordingly.",
-			Optional:    true,
+func basePolicyDeploymentSiteAttributes() policySchemaGroup {
+	return policySchemaGroup{
+		Attributes: map[string]schema.Attribute{
+			"override_site": schema.StringAttribute{
+				Optional: true,
+			},
+			"override_site_claim": schema.StringAttribute{
+				Description: "The path of a claim that contains the UUID of an override site. It should be defined as 'claims.xxx.xxx' or 'claims.xxx.xxx.xxx'1.",
+				Optional:    true,
+			},
+			"override_nearest_site": schema.BoolAttribute{
+				Description: "Overrides the Entitlements Site according to location of the client and Sites where this feature is enabled.",
+				Optional:    true,
+			},
+			"apply_fallback_site": schema.BoolAttribute{
+				Description: "The Entitlements in this Policy will be available in the fallback Sites if the corresponding Sites are configured accordingly.",
+				Optional:    true,
+			},
 		},
 	}
 }
 
 // basePolicyDNSAttributes requires basePolicyEntitlementAttributes
-func basePolicyDNSAttributes() map[string]*schema.Schema {
-	return map[string]*schema.Schema{
-		"dns_settings": {
-			Type:        schema.TypeSet,
-			Optional:    true,
-			Description: "List of domain names with DNS server IPs that the Client should be using.",
-			Set:         resourcePolicyDnsSettingsHash,
-			Elem: &schema.Resource{
-				Schema: map[string]*schema.Schema{
-					"domain": {
-						Type:     schema.TypeString,
-						Optional: true,
-						Computed: true,
-					},
-					"servers": {
-						Type:     schema.TypeSet,
-						Required: true,
-						Set:      schema.HashString,
-						Elem:     &schema.Schema{Type: schema.TypeString},
+func basePolicyDNSAttributes() policySchemaGroup {
+	return policySchemaGroup{
+		Blocks: map[string]schema.Block{
+			"dns_settings": schema.SetNestedBlock{
+				Description: "List of domain names with DNS server IPs that the Client should be using.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"domain": optionalComputedString(),
+						"servers": schema.SetAttribute{
+							ElementType: types.StringType,
+							Required:    true,
+						},
 					},
 				},
 			},
@@ -216,112 +277,75 @@ func basePolicyDNSAttributes() map[string]*schema.Schema {
 }
 
 // basePolicyClientAttributes is used by policy type device
-func basePolicyClientAttributes() map[string]*schema.Schema {
-	return map[string]*schema.Schema{
-		"client_settings": {
-			Type:             schema.TypeList,
-			MaxItems:         1,
-			Optional:         true,
-			Description:      "Settings that admins can apply to the Client.",
-			DiffSuppressFunc: suppressMissingOptionalConfigurationBlock,
-			Elem: &schema.Resource{
-				Schema: map[string]*schema.Schema{
-					"enabled": {
-						Type:     schema.TypeBool,
-						Optional: true,
-						Computed: true,
-					},
-					"entitlements_list": {
-						Type:     schema.TypeString,
-						Optional: true,
-						Computed: true,
-					},
-					"attention_level": {
-						Type:     schema.TypeString,
-						Optional: true,
-						Computed: true,
-					},
-					"auto_start": {
-						Type:     schema.TypeString,
-						Optional: true,
-						Computed: true,
-					},
-					"add_remove_profiles": {
-						Type:     schema.TypeString,
-						Optional: true,
-						Computed: true,
-					},
-					"keep_me_signed_in": {
-						Type:     schema.TypeString,
-						Optional: true,
-						Computed: true,
-					},
-					"saml_auto_sign_in": {
-						Type:     schema.TypeString,
-						Optional: true,
-						Computed: true,
-					},
-					"quit": {
-						Type:     schema.TypeString,
-						Optional: true,
-						Computed: true,
-					},
-					"sign_out": {
-						Type:     schema.TypeString,
-						Optional: true,
-						Computed: true,
-					},
-					"suspend": {
-						Type:     schema.TypeString,
-						Optional: true,
-						Computed: true,
-					},
-					"new_user_onboarding": {
-						Type:     schema.TypeString,
-						Optional: true,
-						Computed: true,
+func basePolicyClientAttributes() policySchemaGroup {
+	return policySchemaGroup{
+		Blocks: map[string]schema.Block{
+			"client_settings": schema.ListNestedBlock{
+				Description: "Settings that admins can apply to the Client.",
+				Validators:  []validator.List{listvalidator.SizeAtMost(1)},
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"enabled":             optionalComputedBool(),
+						"entitlements_list":   optionalComputedString(),
+						"attention_level":     optionalComputedString(),
+						"auto_start":          optionalComputedString(),
+						"add_remove_profiles": optionalComputedString(),
+						"keep_me_signed_in":   optionalComputedString(),
+						"saml_auto_sign_in":   optionalComputedString(),
+						"quit":                optionalComputedString(),
+						"sign_out":            optionalComputedString(),
+						"suspend":             optionalComputedString(),
+						"new_user_onboarding": optionalComputedString(),
 					},
 				},
 			},
-		},
-		"client_profile_settings": {
-			Type:     schema.TypeList,
-			MaxItems: 1,
-			Optional: true,
-			Computed: true,
-			Elem: &schema.Resource{
-				Schema: map[string]*schema.Schema{
-					"enabled": {
-						Type:     schema.TypeBool,
-						Optional: true,
-						Computed: true,
-					},
-					"profiles": {
-						Type:     schema.TypeSet,
-						Required: true,
-						Elem:     &schema.Schema{Type: schema.TypeString},
-					},
-					"force": {
-						Type:     schema.TypeBool,
-						Optional: true,
+			"client_profile_settings": schema.ListNestedBlock{
+				Validators: []validator.List{listvalidator.SizeAtMost(1)},
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"enabled": optionalComputedBool(),
+						"profiles": schema.SetAttribute{
+							ElementType: types.StringType,
+							Required:    true,
+						},
+						"force": schema.BoolAttribute{
+							Optional: true,
+						},
 					},
 				},
 			},
 		},
 	}
 }
-func resourceAppgatePolicy() *schema.Resource {
-	return &schema.Resource{
-		CreateContext: resourceAppgatePolicyCreate,
-		ReadContext:   resourceAppgatePolicyRead,
-		UpdateContext: resourceAppgatePolicyUpdate,
-		DeleteContext: resourceAppgatePolicyDelete,
-		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
-		},
 
-		SchemaVersion: 1,
-		Schema: mergeSchemaMaps(
+// policyResource implements appgatesdp_policy and the resources for one policy type, for example
+// appgatesdp_access_policy. The resources share the model, the schema only has the attributes
+// of the policy type.
+type policyResource struct {
+	typeName string
+	// kind is used in import and error messages.
+	kind string
+	// policyType is set on new policies, empty for appgatesdp_policy where it is configured.
+	policyType string
+	// upgradeFrom are the schema versions of the SDK resource, upgraded by UpgradeState.
+	upgradeFrom []int64
+	schema      schema.Schema
+	client      *Client
+}
+
+var (
+	_ resource.ResourceWithConfigure    = &policyResource{}
+	_ resource.ResourceWithImportState  = &policyResource{}
+	_ resource.ResourceWithModifyPlan   = &policyResource{}
+	_ resource.ResourceWithUpgradeState = &policyResource{}
+)
+
+func newPolicyResource() resource.Resource {
+	return &policyResource{
+		typeName:    "appgatesdp_policy",
+		kind:        "policy",
+		upgradeFrom: []int64{0, 1},
+		schema: policySchema(2,
 			basePolicySchema(),
 			basePolicyEntitlementAttributes(),
 			basePolicyRingfenceAttributes(),
@@ -334,704 +358,813 @@ func resourceAppgatePolicy() *schema.Resource {
 	}
 }
 
-func resourcePolicyDnsSettingsHash(v interface{}) int {
-	var buf bytes.Buffer
-	if v == nil {
-		return hashcode.String(buf.String())
-	}
-	raw := v.(map[string]interface{})
-	// modifying raw actually modifies the values passed to the provider.
-	// Use a copy to avoid that.
-	copy := make((map[string]interface{}))
-	for key, value := range raw {
-		copy[key] = value
-	}
-
-	buf.WriteString(fmt.Sprintf("%s-", copy["domain"].(string)))
-	if v, ok := copy["servers"]; ok {
-		buf.WriteString(fmt.Sprintf("%v-", v.(*schema.Set).List()))
-	}
-
-	return hashcode.String(buf.String())
+func (r *policyResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = r.typeName
 }
 
-type ContextPolicyType string
-
-const (
-	PolicyTypeCtx ContextPolicyType = "policyType"
-)
-const (
-	PolicyTypeAccess string = "Access"
-	PolicyTypeDevice string = "Device"
-	PolicyTypeDns    string = "Dns"
-	PolicyTypeAdmin  string = "Admin"
-	PolicyTypeMixed  string = "Mixed"
-	PolicyTypeStop   string = "Stop"
-)
+func (r *policyResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = r.schema
+}
 
-func resourceAppgatePolicyCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	log.Printf("[DEBUG] Creating Policy with name: %s", d.Get("name").(string))
-	var diags diag.Diagnostics
-	token, err := meta.(*Client).GetToken()
+func (r *policyResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	c, err := frameworkClient(req.ProviderData)
 	if err != nil {
-		return diag.FromErr(err)
+		resp.Diagnostics.AddError("Unexpected provider data", err.Error())
+		return
 	}
-	api := meta.(*Client).API.PoliciesApi
-	currentVersion := meta.(*Client).ApplianceVersion
-	args := openapi.Policy{}
+	r.client = c
+}
 
-	if v, ok := d.GetOk("policy_id"); ok {
-		args.SetId(v.(string))
-	}
+// policyModel is the state of all policy resources, only the attributes in the schema
+// of the resource are read and written.
+type policyModel struct {
+	ID                    types.String
+	PolicyID              types.String
+	Name                  types.String
+	Notes                 types.String
+	Disabled              types.Bool
+	Expression            types.String
+	Type                  types.String
+	Tags                  types.Set
+	TagsAll               types.Set
+	Entitlements          types.Set
+	EntitlementLinks      types.Set
+	RingfenceRules        types.Set
+	RingfenceRuleLinks    types.Set
+	AdministrativeRoles   types.Set
+	ProxyAutoConfig       types.List
+	TrustedNetworkCheck   types.List
+	TamperProofing        types.Bool
+	CustomClientHelpURL   types.String
+	OverrideSite          types.String
+	OverrideSiteClaim     types.String
+	OverrideNearestSite   types.Bool
+	ApplyFallbackSite     types.Bool
+	DNSSettings           types.Set
+	ClientSettings        types.List
+	ClientProfileSettings types.List
+}
 
-	// if the provisioner has expliclitly set the type, use it.
-	if v, ok := d.GetOk("type"); ok {
-		args.SetType(v.(string))
-	}
-	// if PolicyTypeCtx is set, it comes from one of these resources
-	// - resource "appgatesdp_access_policy"
-	// - resource "appgatesdp_admin_policy"
-	// - resource "appgatesdp_device_policy"
-	// - resource "appgatesdp_dns_policy"
-	if v, ok := ctx.Value(PolicyTypeCtx).(string); ok {
-		args.Type = openapi.PtrString(v)
+func (m *policyModel) fields() map[string]any {
+	return map[string]any{
+		"id":                      &m.ID,
+		"policy_id":               &m.PolicyID,
+		"name":                    &m.Name,
+		"notes":                   &m.Notes,
+		"disabled":                &m.Disabled,
+		"expression":              &m.Expression,
+		"type":                    &m.Type,
+		"tags":                    &m.Tags,
+		"tags_all":                &m.TagsAll,
+		"entitlements":            &m.Entitlements,
+		"entitlement_links":       &m.EntitlementLinks,
+		"ringfence_rules":         &m.RingfenceRules,
+		"ringfence_rule_links":    &m.RingfenceRuleLinks,
+		"administrative_roles":    &m.AdministrativeRoles,
+		"proxy_auto_config":       &m.ProxyAutoConfig,
+		"trusted_network_check":   &m.TrustedNetworkCheck,
+		"tamper_proofing":         &m.TamperProofing,
+		"custom_client_help_url":  &m.CustomClientHelpURL,
+		"override_site":           &m.OverrideSite,
+		"override_site_claim":     &m.OverrideSiteClaim,
+		"override_nearest_site":   &m.OverrideNearestSite,
+		"apply_fallback_site":     &m.ApplyFallbackSite,
+		"dns_settings":            &m.DNSSettings,
+		"client_settings":         &m.ClientSettings,
+		"client_profile_settings": &m.ClientProfileSettings,
 	}
+}
 
-	args.SetName(d.Get("name").(string))
+type policyProxyAutoConfigModel struct {
+	Enabled types.Bool   `tfsdk:"enabled"`
+	URL     types.String `tfsdk:"url"`
+	Persist types.Bool   `tfsdk:"persist"`
+}
 
-	if c, ok := d.GetOk("notes"); ok {
-		args.SetNotes(c.(string))
-	}
+type policyTrustedNetworkCheckModel struct {
+	Enabled   types.Bool   `tfsdk:"enabled"`
+	DNSSuffix types.String `tfsdk:"dns_suffix"`
+}
 
-	args.SetTags(schemaExtractTags(d))
+type policyDNSSettingsModel struct {
+	Domain  types.String `tfsdk:"domain"`
+	Servers types.Set    `tfsdk:"servers"`
+}
 
-	if c, ok := d.GetOk("disabled"); ok {
-		args.SetDisabled(c.(bool))
-	}
+type policyClientSettingsModel struct {
+	Enabled           types.Bool   `tfsdk:"enabled"`
+	EntitlementsList  types.String `tfsdk:"entitlements_list"`
+	AttentionLevel    types.String `tfsdk:"attention_level"`
+	AutoStart         types.String `tfsdk:"auto_start"`
+	AddRemoveProfiles types.String `tfsdk:"add_remove_profiles"`
+	KeepMeSignedIn    types.String `tfsdk:"keep_me_signed_in"`
+	SamlAutoSignIn    types.String `tfsdk:"saml_auto_sign_in"`
+	Quit              types.String `tfsdk:"quit"`
+	SignOut           types.String `tfsdk:"sign_out"`
+	Suspend           types.String `tfsdk:"suspend"`
+	NewUserOnboarding types.String `tfsdk:"new_user_onboarding"`
+}
 
-	if c, ok := d.GetOk("expression"); ok {
-		args.SetExpression(c.(string))
-	}
+type policyClientProfileSettingsModel struct {
+	Enabled  types.Bool `tfsdk:"enabled"`
+	Profiles types.Set  `tfsdk:"profiles"`
+	Force    types.Bool `tfsdk:"force"`
+}
 
-	if v, ok := d.GetOk("client_settings"); ok {
-		settings, err := readPolicyClientSettingsFromConfig(v.([]interface{}))
-		if err != nil {
-			return diag.FromErr(err)
-		}
-		args.SetClientSettings(settings)
-	}
-	if currentVersion.GreaterThanOrEqual(Appliance61Version) {
-		if v, ok := d.GetOk("client_profile_settings"); ok {
-			settings, err := readPolicyClientProfileSettingsFromConfig(currentVersion, v.([]interface{}))
-			if err != nil {
-				return diag.FromErr(err)
-			}
-			args.SetClientProfileSettings(settings)
-		}
-		if v, ok := d.GetOk("custom_client_help_url"); ok {
-			args.SetCustomClientHelpUrl(v.(string))
-		}
-	}
-	if v, ok := d.GetOk("type"); ok {
-		args.SetType(v.(string))
-	}
-	if args.GetType() == "Dns" {
-		args.SetTamperProofing(false)
-	}
-	if v, ok := d.GetOk("override_site_claim"); ok {
-		args.SetOverrideSiteClaim(v.(string))
-	}
-	if v, ok := d.GetOk("override_nearest_site"); ok {
-		args.SetOverrideNearestSite(v.(bool))
-	}
-	if v, ok := d.GetOk("apply_fallback_site"); ok {
-		args.SetApplyFallbackSite(v.(bool))
-	}
-	if v, ok := d.GetOk("dns_settings"); ok {
-		if args.GetType() != "Dns" {
-			return diag.Errorf("appgatesdp_policy.dns_settings is only allowed on policy Type 'Dns', got %q", args.GetType())
-		}
-		servers, err := readPolicyDnsSettingsFromConfig(v.(*schema.Set).List())
-		if err != nil {
-			return diag.FromErr(err)
-		}
-		args.SetDnsSettings(servers)
-	}
+var (
+	policyProxyAutoConfigType = types.ObjectType{AttrTypes: map[string]attr.Type{
+		"enabled": types.BoolType,
+		"url":     types.StringType,
+		"persist": types.BoolType,
+	}}
+	policyTrustedNetworkCheckType = types.ObjectType{AttrTypes: map[string]attr.Type{
+		"enabled":    types.BoolType,
+		"dns_suffix": types.StringType,
+	}}
+	policyDNSSettingsType = types.ObjectType{AttrTypes: map[string]attr.Type{
+		"domain":  types.StringType,
+		"servers": types.SetType{ElemType: types.StringType},
+	}}
+	policyClientSettingsType = types.ObjectType{AttrTypes: map[string]attr.Type{
+		"enabled":             types.BoolType,
+		"entitlements_list":   types.StringType,
+		"attention_level":     types.StringType,
+		"auto_start":          types.StringType,
+		"add_remove_profiles": types.StringType,
+		"keep_me_signed_in":   types.StringType,
+		"saml_auto_sign_in":   types.StringType,
+		"quit":                types.StringType,
+		"sign_out":            types.StringType,
+		"suspend":             types.StringType,
+		"new_user_onboarding": types.StringType,
+	}}
+	policyClientProfileSettingsType = types.ObjectType{AttrTypes: map[string]attr.Type{
+		"enabled":  types.BoolType,
+		"profiles": types.SetType{ElemType: types.StringType},
+		"force":    types.BoolType,
+	}}
+)
 
-	if c, ok := d.GetOk("entitlements"); ok {
-		entitlements, err := readArrayOfStringsFromConfig(c.(*schema.Set).List())
-		if err != nil {
-			return diag.FromErr(err)
-		}
-		args.SetEntitlements(entitlements)
-	}
+type attributeGetter interface {
+	GetAttribute(ctx context.Context, p path.Path, target interface{}) diag.Diagnostics
+}
 
-	if c, ok := d.GetOk("entitlement_links"); ok {
-		entitlementLinks, err := readArrayOfStringsFromConfig(c.(*schema.Set).List())
-		if err != nil {
-			return diag.FromErr(err)
-		}
-		args.SetEntitlementLinks(entitlementLinks)
-	}
+func (r *policyResource) has(name string) bool {
+	_, attribute := r.schema.Attributes[name]
+	_, block := r.schema.Blocks[name]
+	return attribute || block
+}
 
-	if c, ok := d.GetOk("ringfence_rules"); ok {
-		ringfenceRules, err := readArrayOfStringsFromConfig(c.(*schema.Set).List())
-		if err != nil {
-			return diag.FromErr(err)
+// get reads the attributes in the schema of the resource from a plan, state or configuration.
+func (r *policyResource) get(ctx context.Context, src attributeGetter) (policyModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	m := policyModel{}
+	for name, field := range m.fields() {
+		if r.has(name) {
+			diags.Append(src.GetAttribute(ctx, path.Root(name), field)...)
 		}
-		args.SetRingfenceRules(ringfenceRules)
 	}
+	return m, diags
+}
 
-	if c, ok := d.GetOk("ringfence_rule_links"); ok {
-		ringfenceRuleLinks, err := readArrayOfStringsFromConfig(c.(*schema.Set).List())
-		if err != nil {
-			return diag.FromErr(err)
+// set writes the attributes in the schema of the resource to the state.
+func (r *policyResource) set(ctx context.Context, dst *tfsdk.State, m policyModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for name, field := range m.fields() {
+		if r.has(name) {
+			diags.Append(dst.SetAttribute(ctx, path.Root(name), field)...)
 		}
-		args.SetRingfenceRuleLinks(ringfenceRuleLinks)
-	}
-	if v, ok := d.GetOkExists("tamper_proofing"); ok {
-		args.SetTamperProofing(v.(bool))
 	}
+	return diags
+}
 
-	if c, ok := d.GetOk("override_site"); ok {
-		args.SetOverrideSite(c.(string))
-	}
-	if v, ok := d.GetOk("proxy_auto_config"); ok {
-		args.SetProxyAutoConfig(readProxyAutoConfigFromConfig(v.([]interface{})))
+// firstBlock returns the object in a block with at most one element, nil if it is empty or unknown.
+func firstBlock[T any](ctx context.Context, block types.List, diags *diag.Diagnostics) *T {
+	if block.IsNull() || block.IsUnknown() || len(block.Elements()) == 0 {
+		return nil
 	}
-
-	if v, ok := d.GetOk("trusted_network_check"); ok {
-		args.SetTrustedNetworkCheck(readTrustedNetworkCheckFromConfig(v.([]interface{})))
-	}
-
-	if c, ok := d.GetOk("administrative_roles"); ok {
-		administrativeRoles, err := readArrayOfStringsFromConfig(c.(*schema.Set).List())
-		if err != nil {
-			return diag.FromErr(err)
-		}
-		args.SetAdministrativeRoles(administrativeRoles)
+	var items []T
+	diags.Append(block.ElementsAs(ctx, &items, false)...)
+	if len(items) == 0 {
+		return nil
 	}
+	return &items[0]
+}
 
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
-	request := api.PoliciesPost(ctx)
-	request = request.Policy(args)
-	policy, _, err := request.Execute()
-	if err != nil {
-		return diag.Errorf("Could not create policy %s", prettyPrintAPIError(err))
+// refreshBlock returns a block with the object from the controller. A block that is empty in the prior
+// state is not managed by terraform and stays empty, there is nothing to compare it with in the configuration.
+func refreshBlock[T any](ctx context.Context, objectType types.ObjectType, prior types.List, remote *T, diags *diag.Diagnostics) types.List {
+	if remote == nil || (!prior.IsNull() && !prior.IsUnknown() && len(prior.Elements()) == 0) {
+		return types.ListValueMust(objectType, []attr.Value{})
 	}
-
-	d.SetId(policy.GetId())
-
-	return append(diags, resourceAppgatePolicyRead(ctx, d, meta)...)
+	v, d := types.ListValueFrom(ctx, objectType, []T{*remote})
+	diags.Append(d...)
+	return v
 }
 
-func readTrustedNetworkCheckFromConfig(trustedNetworks []interface{}) openapi.PolicyAllOfTrustedNetworkCheck {
-	result := openapi.PolicyAllOfTrustedNetworkCheck{}
-	for _, r := range trustedNetworks {
-		if r == nil {
-			continue
-		}
-		raw := r.(map[string]interface{})
-		if v, ok := raw["enabled"]; ok {
-			result.SetEnabled(v.(bool))
+// refresh returns the state of the policy from the controller, prior is the state or plan
+// used to keep optional attributes that are not configured null.
+func (r *policyResource) refresh(ctx context.Context, prior policyModel, policy *openapi.Policy) (policyModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	m := policyModel{
+		ID:                  types.StringValue(policy.GetId()),
+		PolicyID:            types.StringValue(policy.GetId()),
+		Name:                types.StringValue(policy.GetName()),
+		Notes:               types.StringValue(policy.GetNotes()),
+		Disabled:            types.BoolValue(policy.GetDisabled()),
+		Expression:          types.StringValue(policy.GetExpression()),
+		Type:                types.StringValue(policy.GetType()),
+		Entitlements:        setFromStrings(policy.GetEntitlements(), prior.Entitlements),
+		EntitlementLinks:    setFromStrings(policy.GetEntitlementLinks(), prior.EntitlementLinks),
+		RingfenceRules:      setFromStrings(policy.GetRingfenceRules(), prior.RingfenceRules),
+		RingfenceRuleLinks:  setFromStrings(policy.GetRingfenceRuleLinks(), prior.RingfenceRuleLinks),
+		AdministrativeRoles: setFromStrings(policy.GetAdministrativeRoles(), prior.AdministrativeRoles),
+		TamperProofing:      types.BoolValue(policy.GetTamperProofing()),
+		CustomClientHelpURL: optionalString(policy.GetCustomClientHelpUrl(), prior.CustomClientHelpURL),
+		OverrideSite:        optionalString(policy.GetOverrideSite(), prior.OverrideSite),
+		OverrideSiteClaim:   optionalString(policy.GetOverrideSiteClaim(), prior.OverrideSiteClaim),
+		OverrideNearestSite: optionalBool(policy.GetOverrideNearestSite(), prior.OverrideNearestSite),
+		ApplyFallbackSite:   optionalBool(policy.GetApplyFallbackSite(), prior.ApplyFallbackSite),
+	}
+	m.Tags, m.TagsAll = frameworkTags(ctx, r.client, prior.Tags, prior.TagsAll, policy.GetTags())
+
+	var pac *policyProxyAutoConfigModel
+	if v, ok := policy.GetProxyAutoConfigOk(); ok {
+		p := policyProxyAutoConfigModel{}
+		if prior := firstBlock[policyProxyAutoConfigModel](ctx, prior.ProxyAutoConfig, &diags); prior != nil {
+			p = *prior
 		}
-		if v, ok := raw["dns_suffix"]; ok {
-			result.SetDnsSuffix(v.(string))
+		pac = &policyProxyAutoConfigModel{
+			Enabled: types.BoolValue(v.GetEnabled()),
+			URL:     optionalString(v.GetUrl(), p.URL),
+			Persist: types.BoolValue(v.GetPersist()),
 		}
 	}
-	return result
-}
+	m.ProxyAutoConfig = refreshBlock(ctx, policyProxyAutoConfigType, prior.ProxyAutoConfig, pac, &diags)
 
-func readPolicyClientProfileSettingsFromConfig(version *version.Version, settings []interface{}) (openapi.PolicyAllOfClientProfileSettings, error) {
-	result := openapi.PolicyAllOfClientProfileSettings{}
-	for _, r := range settings {
-		if r == nil {
-			continue
-		}
-		raw := r.(map[string]interface{})
-		if v, ok := raw["enabled"]; ok {
-			result.SetEnabled(v.(bool))
+	var trustedNetworkCheck *policyTrustedNetworkCheckModel
+	if v, ok := policy.GetTrustedNetworkCheckOk(); ok {
+		p := policyTrustedNetworkCheckModel{}
+		if prior := firstBlock[policyTrustedNetworkCheckModel](ctx, prior.TrustedNetworkCheck, &diags); prior != nil {
+			p = *prior
 		}
-		if v, ok := raw["profiles"]; ok {
-			profiles, err := readArrayOfStringsFromConfig(v.(*schema.Set).List())
-			if err != nil {
-				return result, err
-			}
-			result.SetProfiles(profiles)
+		trustedNetworkCheck = &policyTrustedNetworkCheckModel{
+			Enabled:   types.BoolValue(v.GetEnabled()),
+			DNSSuffix: optionalString(v.GetDnsSuffix(), p.DNSSuffix),
 		}
-		if version.GreaterThanOrEqual(Appliance62Version) {
-			if v, ok := raw["force"]; ok {
-				result.SetForce(v.(bool))
-			}
+	}
+	m.TrustedNetworkCheck = refreshBlock(ctx, policyTrustedNetworkCheckType, prior.TrustedNetworkCheck, trustedNetworkCheck, &diags)
+
+	var clientSettings *policyClientSettingsModel
+	if v, ok := policy.GetClientSettingsOk(); ok {
+		clientSettings = &policyClientSettingsModel{
+			Enabled:           types.BoolValue(v.GetEnabled()),
+			EntitlementsList:  types.StringValue(v.GetEntitlementsList()),
+			AttentionLevel:    types.StringValue(v.GetAttentionLevel()),
+			AutoStart:         types.StringValue(v.GetAutoStart()),
+			AddRemoveProfiles: types.StringValue(v.GetAddRemoveProfiles()),
+			KeepMeSignedIn:    types.StringValue(v.GetKeepMeSignedIn()),
+			SamlAutoSignIn:    types.StringValue(v.GetSamlAutoSignIn()),
+			Quit:              types.StringValue(v.GetQuit()),
+			SignOut:           types.StringValue(v.GetSignOut()),
+			Suspend:           types.StringValue(v.GetSuspend()),
+			NewUserOnboarding: types.StringValue(v.GetNewUserOnboarding()),
 		}
 	}
-	return result, nil
-}
+	m.ClientSettings = refreshBlock(ctx, policyClientSettingsType, prior.ClientSettings, clientSettings, &diags)
 
-func readPolicyClientSettingsFromConfig(settings []interface{}) (openapi.PolicyAllOfClientSettings, error) {
-	result := openapi.PolicyAllOfClientSettings{}
-	for _, r := range settings {
-		if r == nil {
-			continue
-		}
-		raw := r.(map[string]interface{})
-		if v, ok := raw["enabled"]; ok {
-			result.SetEnabled(v.(bool))
-		}
-		if v, ok := raw["entitlements_list"].(string); ok && len(v) > 0 {
-			result.SetEntitlementsList(v)
+	var clientProfileSettings *policyClientProfileSettingsModel
+	if v, ok := policy.GetClientProfileSettingsOk(); ok {
+		p := policyClientProfileSettingsModel{}
+		if prior := firstBlock[policyClientProfileSettingsModel](ctx, prior.ClientProfileSettings, &diags); prior != nil {
+			p = *prior
 		}
-		if v, ok := raw["attention_level"].(string); ok && len(v) > 0 {
-			result.SetAttentionLevel(v)
-		}
-		if v, ok := raw["auto_start"].(string); ok && len(v) > 0 {
-			result.SetAutoStart(v)
-		}
-		if v, ok := raw["add_remove_profiles"].(string); ok && len(v) > 0 {
-			result.SetAddRemoveProfiles(v)
-		}
-		if v, ok := raw["keep_me_signed_in"].(string); ok && len(v) > 0 {
-			result.SetKeepMeSignedIn(v)
-		}
-		if v, ok := raw["saml_auto_sign_in"].(string); ok && len(v) > 0 {
-			result.SetSamlAutoSignIn(v)
-		}
-		if v, ok := raw["quit"].(string); ok && len(v) > 0 {
-			result.SetQuit(v)
-		}
-		if v, ok := raw["sign_out"].(string); ok && len(v) > 0 {
-			result.SetSignOut(v)
-		}
-		if v, ok := raw["suspend"].(string); ok && len(v) > 0 {
-			result.SetSuspend(v)
-		}
-		if v, ok := raw["new_user_onboarding"].(string); ok && len(v) > 0 {
-			result.SetNewUserOnboarding(v)
+		clientProfileSettings = &policyClientProfileSettingsModel{
+			Enabled:  types.BoolValue(v.GetEnabled()),
+			Profiles: stringSet(v.GetProfiles()),
+			Force:    optionalBool(v.GetForce(), p.Force),
 		}
 	}
-	return result, nil
-}
+	m.ClientProfileSettings = refreshBlock(ctx, policyClientProfileSettingsType, prior.ClientProfileSettings, clientProfileSettings, &diags)
 
-func readPolicyDnsSettingsFromConfig(dnsSettings []interface{}) ([]openapi.PolicyAllOfDnsSettings, error) {
-	list := make([]openapi.PolicyAllOfDnsSettings, 0, 0)
-	for _, r := range dnsSettings {
-		if r == nil {
-			continue
-		}
-		result := openapi.PolicyAllOfDnsSettings{}
-		raw := r.(map[string]interface{})
-		if v, ok := raw["domain"].(string); ok && len(v) > 0 {
-			result.SetDomain(v)
-		}
-		if v, ok := raw["servers"]; ok {
-			servers, err := readArrayOfStringsFromConfig(v.(*schema.Set).List())
-			if err != nil {
-				return list, fmt.Errorf("Failed to resolve dns_settings.servers: %w", err)
-			}
-			if len(servers) > 0 {
-				result.SetServers(servers)
-			}
-		}
-		list = append(list, result)
+	dnsSettings := make([]policyDNSSettingsModel, 0, len(policy.GetDnsSettings()))
+	for _, v := range policy.GetDnsSettings() {
+		dnsSettings = append(dnsSettings, policyDNSSettingsModel{
+			Domain:  types.StringValue(v.GetDomain()),
+			Servers: stringSet(v.GetServers()),
+		})
 	}
-	log.Printf("[DEBUG] readPolicyDnsSettingsFromConfig Result %+v", list)
-	return list, nil
+	var d diag.Diagnostics
+	m.DNSSettings, d = types.SetValueFrom(ctx, policyDNSSettingsType, dnsSettings)
+	diags.Append(d...)
+
+	return m, diags
 }
 
-func readProxyAutoConfigFromConfig(proxyAutoConfigs []interface{}) openapi.PolicyAllOfProxyAutoConfig {
-	pac := openapi.PolicyAllOfProxyAutoConfig{}
-	for _, r := range proxyAutoConfigs {
-		if r == nil {
-			continue
-		}
-		raw := r.(map[string]interface{})
-		if v, ok := raw["enabled"]; ok {
-			pac.SetEnabled(v.(bool))
-		}
-		if v, ok := raw["url"]; ok {
-			pac.SetUrl(v.(string))
-		}
-		if v, ok := raw["persist"]; ok {
-			pac.SetPersist(v.(bool))
-		}
+// changed returns true if the planned value should be sent to the controller, prior is nil on create
+// where only values that are configured are sent.
+func changed(plan, prior attr.Value) bool {
+	if plan.IsNull() || plan.IsUnknown() {
+		return prior != nil && !plan.IsUnknown() && !prior.IsNull()
 	}
-	return pac
+	return prior == nil || !plan.Equal(prior)
 }
 
-func resourceAppgatePolicyRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	log.Printf("[DEBUG] Reading Policy with name: %s", d.Get("name").(string))
+// expand sets the planned attributes on args, all configured attributes on create when prior is nil,
+// otherwise only the attributes that changed. An empty block is not managed by terraform and
+// leaves the object on the controller unchanged.
+func (r *policyResource) expand(ctx context.Context, plan policyModel, prior *policyModel, args *openapi.Policy) diag.Diagnostics {
 	var diags diag.Diagnostics
-	token, err := meta.(*Client).GetToken()
-	if err != nil {
-		return diag.FromErr(err)
+	p := policyModel{}
+	if prior != nil {
+		p = *prior
 	}
-	api := meta.(*Client).API.PoliciesApi
-	currentVersion := meta.(*Client).ApplianceVersion
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
-	request := api.PoliciesIdGet(ctx, d.Id())
-	policy, response, err := request.Execute()
-	if err != nil {
-		d.SetId("")
-		if response != nil && response.StatusCode == http.StatusNotFound {
+	priorValue := func(v attr.Value) attr.Value {
+		if prior == nil {
 			return nil
 		}
-		return diag.Errorf("Failed to read policy, %s", err)
+		return v
 	}
-	d.Set("policy_id", policy.GetId())
-	d.Set("name", policy.GetName())
-	d.Set("notes", policy.GetNotes())
-	d.Set("disabled", policy.GetDisabled())
-	d.Set("expression", policy.GetExpression())
-	d.Set("tags", policy.GetTags())
 
-	if v := d.Get("entitlements"); v != nil {
-		d.Set("entitlements", policy.GetEntitlements())
+	if changed(plan.Name, priorValue(p.Name)) {
+		args.SetName(plan.Name.ValueString())
 	}
-	if v := d.Get("entitlement_links"); v != nil {
-		d.Set("entitlement_links", policy.GetEntitlementLinks())
+	if changed(plan.Notes, priorValue(p.Notes)) {
+		args.SetNotes(plan.Notes.ValueString())
 	}
-	if v := d.Get("ringfence_rule_links"); v != nil {
-		d.Set("ringfence_rule_links", policy.GetRingfenceRuleLinks())
+	if prior == nil || !plan.TagsAll.Equal(p.TagsAll) || !plan.Tags.Equal(p.Tags) {
+		args.SetTags(mergeTags(stringsFromSet(ctx, plan.Tags), defaultTags(r.client)))
 	}
-	if v := d.Get("ringfence_rules"); v != nil {
-		d.Set("ringfence_rules", policy.GetRingfenceRules())
+	if changed(plan.Disabled, priorValue(p.Disabled)) {
+		args.SetDisabled(plan.Disabled.ValueBool())
 	}
-	if v := d.Get("tamper_proofing"); v != nil {
-		d.Set("tamper_proofing", policy.GetTamperProofing())
+	if changed(plan.Expression, priorValue(p.Expression)) {
+		args.SetExpression(plan.Expression.ValueString())
 	}
-	if v := d.Get("administrative_roles"); v != nil {
-		d.Set("administrative_roles", policy.GetAdministrativeRoles())
+	// the type of new policies is set in Create
+	if prior != nil && len(r.policyType) == 0 && isKnown(plan.Type) && !plan.Type.Equal(p.Type) {
+		args.SetType(plan.Type.ValueString())
 	}
 
-	if v, o := policy.GetProxyAutoConfigOk(); o != false {
-		if ok := d.Get("proxy_auto_config"); ok != nil {
-			pac, err := flattenProxyAutoConfig(*v)
-			if err != nil {
-				return diag.FromErr(err)
-			}
-			d.Set("proxy_auto_config", pac)
+	sets := []struct {
+		plan, prior types.Set
+		set         func([]string)
+	}{
+		{plan.Entitlements, p.Entitlements, args.SetEntitlements},
+		{plan.EntitlementLinks, p.EntitlementLinks, args.SetEntitlementLinks},
+		{plan.RingfenceRules, p.RingfenceRules, args.SetRingfenceRules},
+		{plan.RingfenceRuleLinks, p.RingfenceRuleLinks, args.SetRingfenceRuleLinks},
+		{plan.AdministrativeRoles, p.AdministrativeRoles, args.SetAdministrativeRoles},
+	}
+	for _, s := range sets {
+		if s.plan.IsUnknown() {
+			continue
+		}
+		if v := stringsFromSet(ctx, s.plan); (prior == nil && len(v) > 0) || (prior != nil && !s.plan.Equal(s.prior)) {
+			s.set(v)
 		}
 	}
-	if v, o := policy.GetTrustedNetworkCheckOk(); o != false {
-		if ok := d.Get("trusted_network_check"); ok != nil {
-			t, err := flattenTrustedNetworkCheck(*v)
-			if err != nil {
-				return diag.FromErr(err)
-			}
-			d.Set("trusted_network_check", t)
+
+	if isKnown(plan.TamperProofing) && changed(plan.TamperProofing, priorValue(p.TamperProofing)) {
+		args.SetTamperProofing(plan.TamperProofing.ValueBool())
+	}
+	if changed(plan.CustomClientHelpURL, priorValue(p.CustomClientHelpURL)) && (prior != nil || len(plan.CustomClientHelpURL.ValueString()) > 0) {
+		args.SetCustomClientHelpUrl(plan.CustomClientHelpURL.ValueString())
+	}
+	if changed(plan.OverrideSite, priorValue(p.OverrideSite)) {
+		if v := plan.OverrideSite.ValueString(); len(v) > 0 {
+			args.SetOverrideSite(v)
+		} else {
+			args.OverrideSite = nil
 		}
 	}
-	if ok := d.Get("client_settings"); ok != nil {
-		clientSettings, err := flattenPolicyClientSettings(policy.GetClientSettings())
-		if err != nil {
-			return diag.FromErr(err)
+	if changed(plan.OverrideSiteClaim, priorValue(p.OverrideSiteClaim)) {
+		if v := plan.OverrideSiteClaim.ValueString(); len(v) > 0 {
+			args.SetOverrideSiteClaim(v)
+		} else {
+			args.OverrideSiteClaim = nil
 		}
-		d.Set("client_settings", clientSettings)
+	}
+	if changed(plan.OverrideNearestSite, priorValue(p.OverrideNearestSite)) {
+		args.SetOverrideNearestSite(plan.OverrideNearestSite.ValueBool())
+	}
+	if changed(plan.ApplyFallbackSite, priorValue(p.ApplyFallbackSite)) {
+		args.SetApplyFallbackSite(plan.ApplyFallbackSite.ValueBool())
 	}
 
-	d.Set("type", policy.GetType())
-	if v := d.Get("override_site_claim"); v != nil {
-		d.Set("override_site_claim", policy.GetOverrideSiteClaim())
+	if v := firstBlock[policyProxyAutoConfigModel](ctx, plan.ProxyAutoConfig, &diags); v != nil && changed(plan.ProxyAutoConfig, priorValue(p.ProxyAutoConfig)) {
+		pac := openapi.PolicyAllOfProxyAutoConfig{}
+		if isKnown(v.Enabled) {
+			pac.SetEnabled(v.Enabled.ValueBool())
+		}
+		if isKnown(v.URL) {
+			pac.SetUrl(v.URL.ValueString())
+		}
+		if isKnown(v.Persist) {
+			pac.SetPersist(v.Persist.ValueBool())
+		}
+		args.SetProxyAutoConfig(pac)
 	}
-	if v := d.Get("dns_settings"); v != nil {
-		dnsSettings, err := flattenPolicyDnsSettings(policy.GetDnsSettings())
-		if err != nil {
-			return diag.FromErr(err)
+	if v := firstBlock[policyTrustedNetworkCheckModel](ctx, plan.TrustedNetworkCheck, &diags); v != nil && changed(plan.TrustedNetworkCheck, priorValue(p.TrustedNetworkCheck)) {
+		trustedNetworkCheck := openapi.PolicyAllOfTrustedNetworkCheck{}
+		if isKnown(v.Enabled) {
+			trustedNetworkCheck.SetEnabled(v.Enabled.ValueBool())
 		}
-		d.Set("dns_settings", dnsSettings)
+		if isKnown(v.DNSSuffix) {
+			trustedNetworkCheck.SetDnsSuffix(v.DNSSuffix.ValueString())
+		}
+		args.SetTrustedNetworkCheck(trustedNetworkCheck)
 	}
-	if v := d.Get("client_profile_settings"); v != nil && currentVersion.GreaterThanOrEqual(Appliance61Version) {
-		clientProfileSettings, err := flattenPolicyClientProfileSettings(policy.GetClientProfileSettings())
-		if err != nil {
-			return diag.FromErr(err)
+	if v := firstBlock[policyClientSettingsModel](ctx, plan.ClientSettings, &diags); v != nil && changed(plan.ClientSettings, priorValue(p.ClientSettings)) {
+		settings := openapi.PolicyAllOfClientSettings{}
+		if isKnown(v.Enabled) {
+			settings.SetEnabled(v.Enabled.ValueBool())
 		}
-
-		d.Set("client_profile_settings", clientProfileSettings)
-		d.Set("custom_client_help_url", policy.GetCustomClientHelpUrl())
+		values := []struct {
+			value types.String
+			set   func(string)
+		}{
+			{v.EntitlementsList, settings.SetEntitlementsList},
+			{v.AttentionLevel, settings.SetAttentionLevel},
+			{v.AutoStart, settings.SetAutoStart},
+			{v.AddRemoveProfiles, settings.SetAddRemoveProfiles},
+			{v.KeepMeSignedIn, settings.SetKeepMeSignedIn},
+			{v.SamlAutoSignIn, settings.SetSamlAutoSignIn},
+			{v.Quit, settings.SetQuit},
+			{v.SignOut, settings.SetSignOut},
+			{v.Suspend, settings.SetSuspend},
+			{v.NewUserOnboarding, settings.SetNewUserOnboarding},
+		}
+		for _, s := range values {
+			if isKnown(s.value) && len(s.value.ValueString()) > 0 {
+				s.set(s.value.ValueString())
+			}
+		}
+		args.SetClientSettings(settings)
 	}
-
-	if currentVersion.GreaterThanOrEqual(Appliance62Version) {
-		if v := d.Get("override_nearest_site"); v != nil {
-			d.Set("override_nearest_site", v.(bool))
+	if v := firstBlock[policyClientProfileSettingsModel](ctx, plan.ClientProfileSettings, &diags); v != nil && changed(plan.ClientProfileSettings, priorValue(p.ClientProfileSettings)) {
+		settings := openapi.PolicyAllOfClientProfileSettings{}
+		if isKnown(v.Enabled) {
+			settings.SetEnabled(v.Enabled.ValueBool())
 		}
-		if v := d.Get("apply_fallback_site"); v != nil {
-			d.Set("apply_fallback_site", v.(bool))
+		settings.SetProfiles(stringsFromSet(ctx, v.Profiles))
+		if isKnown(v.Force) {
+			settings.SetForce(v.Force.ValueBool())
 		}
+		args.SetClientProfileSettings(settings)
 	}
 
+	if isKnown(plan.DNSSettings) && ((prior == nil && len(plan.DNSSettings.Elements()) > 0) || (prior != nil && !plan.DNSSettings.Equal(p.DNSSettings))) {
+		if args.GetType() != PolicyTypeDns {
+			diags.AddAttributeError(path.Root("dns_settings"), "Invalid dns_settings", fmt.Sprintf("appgatesdp_policy.dns_settings is only allowed on policy Type 'Dns', got %q", args.GetType()))
+			return diags
+		}
+		var dnsSettings []policyDNSSettingsModel
+		diags.Append(plan.DNSSettings.ElementsAs(ctx, &dnsSettings, false)...)
+		list := make([]openapi.PolicyAllOfDnsSettings, 0, len(dnsSettings))
+		for _, v := range dnsSettings {
+			result := openapi.PolicyAllOfDnsSettings{}
+			if isKnown(v.Domain) && len(v.Domain.ValueString()) > 0 {
+				result.SetDomain(v.Domain.ValueString())
+			}
+			if servers := stringsFromSet(ctx, v.Servers); len(servers) > 0 {
+				result.SetServers(servers)
+			}
+			list = append(list, result)
+		}
+		args.SetDnsSettings(list)
+	}
 	return diags
 }
 
-func flattenProxyAutoConfig(in openapi.PolicyAllOfProxyAutoConfig) ([]interface{}, error) {
-	m := make(map[string]interface{})
-	if v, o := in.GetEnabledOk(); o != false {
-		m["enabled"] = *v
+// applied returns the state after create or update, the values from the controller
+// with the known values from the plan, terraform requires the state to match the plan.
+func (r *policyResource) applied(ctx context.Context, plan tfsdk.Plan, policy *openapi.Policy, state *tfsdk.State) diag.Diagnostics {
+	m, diags := r.get(ctx, plan)
+	if diags.HasError() {
+		return diags
 	}
-	if v, o := in.GetUrlOk(); o != false {
-		m["url"] = *v
+	result, d := r.refresh(ctx, m, policy)
+	diags.Append(d...)
+	diags.Append(r.set(ctx, state, result)...)
+	if diags.HasError() {
+		return diags
 	}
-	if v, o := in.GetPersistOk(); o != false {
-		m["persist"] = *v
+	raw, err := withPlannedValues(plan.Raw, state.Raw)
+	if err != nil {
+		diags.AddError("Could not set the state", err.Error())
+		return diags
 	}
+	state.Raw = raw
+	return diags
+}
+
+// etagPrivateStateKey is the key of the etag in the private state of the policy resources,
+// the framework keeps it out of the plan so it is never shown to users.
+const etagPrivateStateKey = "etag"
 
-	return []interface{}{m}, nil
+type privateStateGetter interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
 }
 
-func flattenTrustedNetworkCheck(in openapi.PolicyAllOfTrustedNetworkCheck) ([]interface{}, error) {
-	m := make(map[string]interface{})
-	if v, o := in.GetEnabledOk(); o != false {
-		m["enabled"] = v
-	}
-	if v, o := in.GetDnsSuffixOk(); o != false {
-		m["dns_suffix"] = v
-	}
-	return []interface{}{m}, nil
+type privateStateSetter interface {
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
 }
 
-func flattenPolicyClientProfileSettings(clientSettings openapi.PolicyAllOfClientProfileSettings) ([]interface{}, error) {
-	m := make(map[string]interface{})
-	if v, ok := clientSettings.GetEnabledOk(); ok {
-		m["enabled"] = *v
-	}
-	if v, ok := clientSettings.GetProfilesOk(); ok {
-		m["profiles"] = v
+// privateETag returns the etag from the private state, empty for state written before
+// the etag was kept in the private state.
+func privateETag(ctx context.Context, private privateStateGetter) (string, diag.Diagnostics) {
+	var etag string
+	b, diags := private.GetKey(ctx, etagPrivateStateKey)
+	if diags.HasError() || len(b) == 0 {
+		return etag, diags
 	}
-	if v, ok := clientSettings.GetForceOk(); ok {
-		m["force"] = v
+	if err := json.Unmarshal(b, &etag); err != nil {
+		diags.AddError("Could not read the private state", err.Error())
 	}
-	return []interface{}{m}, nil
+	return etag, diags
 }
 
-func flattenPolicyClientSettings(clientSettings openapi.PolicyAllOfClientSettings) ([]interface{}, error) {
-	m := make(map[string]interface{})
-	if v, ok := clientSettings.GetEnabledOk(); ok {
-		m["enabled"] = *v
+// setPrivateETag saves the etag of the object in the private state, the values must be JSON.
+func setPrivateETag(ctx context.Context, private privateStateSetter, object interface{}) diag.Diagnostics {
+	b, err := json.Marshal(objectETag(object))
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddError("Could not set the private state", err.Error())
+		return diags
 	}
-	if v, ok := clientSettings.GetEntitlementsListOk(); ok {
-		m["entitlements_list"] = *v
+	return private.SetKey(ctx, etagPrivateStateKey, b)
+}
+
+func (r *policyResource) configured(diags *diag.Diagnostics) bool {
+	if r.client == nil {
+		diags.AddError("Provider not configured", fmt.Sprintf("%s requires a configured provider", r.typeName))
+		return false
 	}
-	if v, ok := clientSettings.GetAttentionLevelOk(); ok {
-		m["attention_level"] = *v
+	return true
+}
+
+func (r *policyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if !r.configured(&resp.Diagnostics) {
+		return
 	}
-	if v, ok := clientSettings.GetAutoStartOk(); ok {
-		m["auto_start"] = *v
+	plan, diags := r.get(ctx, req.Plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	if v, ok := clientSettings.GetAddRemoveProfilesOk(); ok {
-		m["add_remove_profiles"] = *v
+	log.Printf("[DEBUG] Creating Policy with name: %s", plan.Name.ValueString())
+	token, err := r.client.GetToken()
+	if err != nil {
+		resp.Diagnostics.AddError("Could not create policy", err.Error())
+		return
 	}
-	if v, ok := clientSettings.GetKeepMeSignedInOk(); ok {
-		m["keep_me_signed_in"] = *v
+	api := r.client.API.PoliciesApi
+	args := openapi.Policy{}
+	if isKnown(plan.PolicyID) && len(plan.PolicyID.ValueString()) > 0 {
+		args.SetId(plan.PolicyID.ValueString())
+	} else {
+		args.SetId(uuid.New().String())
 	}
-	if v, ok := clientSettings.GetSamlAutoSignInOk(); ok {
-		m["saml_auto_sign_in"] = *v
+	if len(r.policyType) > 0 {
+		args.SetType(r.policyType)
+	} else if isKnown(plan.Type) && len(plan.Type.ValueString()) > 0 {
+		args.SetType(plan.Type.ValueString())
 	}
-	if v, ok := clientSettings.GetQuitOk(); ok {
-		m["quit"] = *v
+	if args.GetType() == PolicyTypeDns {
+		args.SetTamperProofing(false)
 	}
-	if v, ok := clientSettings.GetSignOutOk(); ok {
-		m["sign_out"] = *v
+	resp.Diagnostics.Append(r.expand(ctx, plan, nil, &args)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	if v, ok := clientSettings.GetSuspendOk(); ok {
-		m["suspend"] = *v
+
+	ctx = authContext(ctx, token)
+	policy, _, err := api.PoliciesPost(ctx).Policy(args).Execute()
+	if err != nil {
+		resp.Diagnostics.Append(frameworkAPIErrorDiagnostics("Could not create policy", err, r.schema)...)
+		return
 	}
-	if v, ok := clientSettings.GetNewUserOnboardingOk(); ok {
-		m["new_user_onboarding"] = *v
+	// the policy is read back like the SDK resources did, the controller fills in the defaults.
+	policy, _, err = api.PoliciesIdGet(ctx, policy.GetId()).Execute()
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read policy", err.Error())
+		return
 	}
-	return []interface{}{m}, nil
+	resp.Diagnostics.Append(r.applied(ctx, req.Plan, policy, &resp.State)...)
+	resp.Diagnostics.Append(setPrivateETag(ctx, resp.Private, policy)...)
 }
 
-func flattenPolicyDnsSettings(dnsSettings []openapi.PolicyAllOfDnsSettings) (*schema.Set, error) {
-	out := make([]interface{}, 0)
-	for _, dnsSetting := range dnsSettings {
-		m := make(map[string]interface{})
-		if v, ok := dnsSetting.GetDomainOk(); ok {
-			m["domain"] = *v
-		}
-		if v, ok := dnsSetting.GetServersOk(); ok {
-			m["servers"] = schema.NewSet(schema.HashString, convertStringArrToInterface(v))
+func (r *policyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if !r.configured(&resp.Diagnostics) {
+		return
+	}
+	prior, diags := r.get(ctx, req.State)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	log.Printf("[DEBUG] Reading Policy with name: %s", prior.Name.ValueString())
+	token, err := r.client.GetToken()
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read policy", err.Error())
+		return
+	}
+	policy, response, err := r.client.API.PoliciesApi.PoliciesIdGet(authContext(ctx, token), prior.ID.ValueString()).Execute()
+	if err != nil {
+		if response != nil && response.StatusCode == http.StatusNotFound {
+			resp.State.RemoveResource(ctx)
+			return
 		}
-		out = append(out, m)
+		resp.Diagnostics.AddError("Failed to read policy", err.Error())
+		return
 	}
-	return schema.NewSet(resourcePolicyDnsSettingsHash, out), nil
+	m, diags := r.refresh(ctx, prior, policy)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(r.set(ctx, &resp.State, m)...)
+	resp.Diagnostics.Append(setPrivateETag(ctx, resp.Private, policy)...)
 }
 
-func resourceAppgatePolicyUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	log.Printf("[DEBUG] Updating policy: %s", d.Get("name").(string))
-
-	token, err := meta.(*Client).GetToken()
+func (r *policyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if !r.configured(&resp.Diagnostics) {
+		return
+	}
+	plan, diags := r.get(ctx, req.Plan)
+	resp.Diagnostics.Append(diags...)
+	state, diags := r.get(ctx, req.State)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	log.Printf("[DEBUG] Updating policy: %s", plan.Name.ValueString())
+	token, err := r.client.GetToken()
 	if err != nil {
-		return diag.FromErr(err)
+		resp.Diagnostics.AddError("Could not update policy", err.Error())
+		return
 	}
-	api := meta.(*Client).API.PoliciesApi
-	currentVersion := meta.(*Client).ApplianceVersion
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
-	request := api.PoliciesIdGet(ctx, d.Id())
-	orginalPolicy, _, err := request.Execute()
+	api := r.client.API.PoliciesApi
+	ctx = authContext(ctx, token)
+	id := state.ID.ValueString()
+	originalPolicy, _, err := api.PoliciesIdGet(ctx, id).Execute()
 	if err != nil {
-		return diag.Errorf("Failed to read policy, %s", err)
+		resp.Diagnostics.AddError("Failed to read policy", err.Error())
+		return
 	}
-
-	if d.HasChange("name") {
-		orginalPolicy.SetName(d.Get("name").(string))
+	etag, diags := privateETag(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-
-	if d.HasChange("notes") {
-		orginalPolicy.SetNotes(d.Get("notes").(string))
+	if modifiedOutsideTerraform(r.client, etag, originalPolicy) {
+		resp.Diagnostics.AddError(modifiedOutsideTerraformSummary("Policy", id), modifiedOutsideTerraformDetail("Policy"))
+		return
 	}
-
-	if d.HasChange("tags") {
-		orginalPolicy.SetTags(schemaExtractTags(d))
+	resp.Diagnostics.Append(r.expand(ctx, plan, &state, originalPolicy)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-
-	if d.HasChange("disabled") {
-		orginalPolicy.SetDisabled(d.Get("disabled").(bool))
+	if _, _, err := api.PoliciesIdPut(ctx, id).Policy(*originalPolicy).Execute(); err != nil {
+		resp.Diagnostics.Append(frameworkAPIErrorDiagnostics("Could not update policy", err, r.schema)...)
+		return
 	}
-
-	if d.HasChange("expression") {
-		orginalPolicy.SetExpression(d.Get("expression").(string))
+	policy, _, err := api.PoliciesIdGet(ctx, id).Execute()
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read policy", err.Error())
+		return
 	}
+	resp.Diagnostics.Append(r.applied(ctx, req.Plan, policy, &resp.State)...)
+	resp.Diagnostics.Append(setPrivateETag(ctx, resp.Private, policy)...)
+}
 
-	if d.HasChange("entitlements") {
-		_, n := d.GetChange("entitlements")
-		entitlements, err := readArrayOfStringsFromConfig(n.(*schema.Set).List())
-		if err != nil {
-			return diag.FromErr(err)
-		}
-		orginalPolicy.SetEntitlements(entitlements)
+func (r *policyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if !r.configured(&resp.Diagnostics) {
+		return
 	}
-
-	if d.HasChange("entitlement_links") {
-		_, n := d.GetChange("entitlement_links")
-		entitlements, err := readArrayOfStringsFromConfig(n.(*schema.Set).List())
-		if err != nil {
-			return diag.FromErr(err)
-		}
-		orginalPolicy.SetEntitlementLinks(entitlements)
+	state, diags := r.get(ctx, req.State)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	log.Printf("[DEBUG] Delete Policy with name: %s", state.Name.ValueString())
+	token, err := r.client.GetToken()
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to delete policy", err.Error())
+		return
+	}
+	response, err := r.client.API.PoliciesApi.PoliciesIdDelete(authContext(ctx, token), state.ID.ValueString()).Execute()
+	if err != nil && (response == nil || response.StatusCode != http.StatusNotFound) {
+		resp.Diagnostics.AddError("Failed to delete policy", err.Error())
 	}
+}
 
-	if d.HasChange("ringfence_rules") {
-		_, n := d.GetChange("ringfence_rules")
-		entitlements, err := readArrayOfStringsFromConfig(n.(*schema.Set).List())
-		if err != nil {
-			return diag.FromErr(err)
-		}
-		orginalPolicy.SetRingfenceRules(entitlements)
+// ImportState accepts the UUID of the policy, or name:<name> or tag:<tag> like importStateByNameOrTag.
+func (r *policyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	if !r.configured(&resp.Diagnostics) {
+		return
+	}
+	id, err := resolveImportID(ctx, r.client, r.kind, req.ID, listPolicyImportCandidates(r.policyType))
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("Could not import %s", r.kind), err.Error())
+		return
 	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id)...)
+}
 
-	if d.HasChange("ringfence_rule_links") {
-		_, n := d.GetChange("ringfence_rule_links")
-		entitlements, err := readArrayOfStringsFromConfig(n.(*schema.Set).List())
-		if err != nil {
-			return diag.FromErr(err)
+// ModifyPlan is readOnlyCustomizeDiff, tagsCustomizeDiff and attributeVersionCustomizeDiff of the SDK resources.
+func (r *policyResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// the resource is destroyed
+	if req.Plan.Raw.IsNull() {
+		if isReadOnly(r.client) && !req.State.Raw.IsNull() {
+			resp.Diagnostics.AddError(readOnlyPlanSummary(r.typeName), readOnlyPlanDetail("destroy"))
 		}
-		orginalPolicy.SetRingfenceRuleLinks(entitlements)
+		return
+	}
+	if isReadOnly(r.client) {
+		// compare the final plan with the state, a change to tags_all is an update too.
+		defer func() {
+			if req.State.Raw.IsNull() {
+				resp.Diagnostics.AddError(readOnlyPlanSummary(r.typeName), readOnlyPlanDetail("create"))
+			} else if !resp.Plan.Raw.Equal(req.State.Raw) {
+				resp.Diagnostics.AddError(readOnlyPlanSummary(r.typeName), readOnlyPlanDetail("update"))
+			}
+		}()
 	}
-
-	if d.HasChange("tamper_proofing") {
-		orginalPolicy.SetTamperProofing(d.Get("tamper_proofing").(bool))
+	var tags, tagsAll, stateTagsAll types.Set
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("tags"), &tags)...)
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("tags_all"), &stateTagsAll)...)
+	} else {
+		stateTagsAll = types.SetNull(types.StringType)
 	}
-
-	if d.HasChange("proxy_auto_config") {
-		_, v := d.GetChange("proxy_auto_config")
-		orginalPolicy.SetProxyAutoConfig(readProxyAutoConfigFromConfig(v.([]interface{})))
+	if resp.Diagnostics.HasError() {
+		return
 	}
-
-	if d.HasChange("trusted_network_check") {
-		_, v := d.GetChange("trusted_network_check")
-		orginalPolicy.SetTrustedNetworkCheck(readTrustedNetworkCheckFromConfig(v.([]interface{})))
+	if req.State.Raw.IsNull() && len(r.policyType) > 0 {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("type"), r.policyType)...)
 	}
+	tagsAll = frameworkPlanTagsAll(ctx, r.client, tags, stateTagsAll)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("tags_all"), tagsAll)...)
 
-	if d.HasChange("administrative_roles") {
-		_, n := d.GetChange("administrative_roles")
-		entitlements, err := readArrayOfStringsFromConfig(n.(*schema.Set).List())
-		if err != nil {
-			return diag.FromErr(err)
-		}
-		orginalPolicy.SetAdministrativeRoles(entitlements)
+	if r.client == nil || req.Config.Raw.IsNull() || !req.Config.Raw.IsKnown() {
+		return
 	}
-	if d.HasChange("client_settings") {
-		_, v := d.GetChange("client_settings")
-		clientSettings, err := readPolicyClientSettingsFromConfig(v.([]interface{}))
-		if err != nil {
-			return diag.FromErr(err)
-		}
-		orginalPolicy.SetClientSettings(clientSettings)
+	err := unsupportedAttributes(r.typeName, r.client, func(p []string) bool {
+		return tfConfigSets(req.Config.Raw, p)
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("Unsupported attributes", err.Error())
 	}
+}
 
-	if d.HasChange("override_site") {
-		_, n := d.GetChange("override_site")
-		if new, ok := n.(string); ok && len(new) > 0 {
-			orginalPolicy.SetOverrideSite(new)
-		} else {
-			orginalPolicy.OverrideSite = nil
+// UpgradeState upgrades the state of the SDK resource, the schema is the same but the SDK saved
+// optional attributes that are not configured as empty values instead of null.
+func (r *policyResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	upgraders := make(map[int64]resource.StateUpgrader, len(r.upgradeFrom))
+	for _, version := range r.upgradeFrom {
+		prior := r.schema
+		prior.Version = version
+		upgraders[version] = resource.StateUpgrader{
+			PriorSchema: &prior,
+			StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				m, diags := r.get(ctx, req.State)
+				resp.Diagnostics.Append(diags...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+				resp.Diagnostics.Append(upgradePolicyModel(ctx, &m)...)
+				resp.State = tfsdk.State{Schema: r.schema, Raw: tftypes.NewValue(r.schema.Type().TerraformType(ctx), nil)}
+				resp.Diagnostics.Append(r.set(ctx, &resp.State, m)...)
+			},
 		}
 	}
+	return upgraders
+}
 
-	if d.HasChange("type") {
-		orginalPolicy.SetType(d.Get("type").(string))
-	}
-	if d.HasChange("override_site_claim") {
-		_, n := d.GetChange("override_site_claim")
-		if new, ok := n.(string); ok && len(new) > 0 {
-			orginalPolicy.SetOverrideSiteClaim(new)
-		} else {
-			orginalPolicy.OverrideSiteClaim = nil
+// upgradePolicyModel replaces the empty values the SDK saved for optional attributes with null.
+func upgradePolicyModel(ctx context.Context, m *policyModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for _, s := range []*types.Set{&m.Tags, &m.Entitlements, &m.EntitlementLinks, &m.RingfenceRules, &m.RingfenceRuleLinks, &m.AdministrativeRoles} {
+		if !s.IsNull() && len(s.Elements()) == 0 {
+			*s = types.SetNull(types.StringType)
 		}
 	}
-	if d.HasChange("dns_settings") {
-		if orginalPolicy.GetType() != "Dns" {
-			return diag.Errorf("appgatesdp_policy.dns_settings is only allowed on policy Type 'Dns', got %q", orginalPolicy.GetType())
-		}
-		_, v := d.GetChange("dns_settings")
-		dnsSettings, err := readPolicyDnsSettingsFromConfig(v.(*schema.Set).List())
-		if err != nil {
-			return diag.FromErr(err)
-		}
-		orginalPolicy.SetDnsSettings(dnsSettings)
-	}
-	if currentVersion.GreaterThanOrEqual(Appliance61Version) {
-		if d.HasChange("client_profile_settings") {
-			_, v := d.GetChange("client_profile_settings")
-			clientProfileSettings, err := readPolicyClientProfileSettingsFromConfig(currentVersion, v.([]interface{}))
-			if err != nil {
-				return diag.FromErr(err)
-			}
-			orginalPolicy.SetClientProfileSettings(clientProfileSettings)
-		}
-		if d.HasChange("custom_client_help_url") {
-			orginalPolicy.SetCustomClientHelpUrl(d.Get("custom_client_help_url").(string))
-		}
+	for _, s := range []*types.String{&m.CustomClientHelpURL, &m.OverrideSite, &m.OverrideSiteClaim} {
+		*s = optionalString(s.ValueString(), types.StringNull())
 	}
-	req := api.PoliciesIdPut(ctx, d.Id())
-	_, _, err = req.Policy(*orginalPolicy).Execute()
-	if err != nil {
-		return diag.Errorf("Could not update policy %s", prettyPrintAPIError(err))
+	for _, b := range []*types.Bool{&m.OverrideNearestSite, &m.ApplyFallbackSite} {
+		*b = optionalBool(b.ValueBool(), types.BoolNull())
 	}
-
-	return resourceAppgatePolicyRead(ctx, d, meta)
-}
-
-func resourceAppgatePolicyDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	log.Printf("[DEBUG] Delete Policy with name: %s", d.Get("name").(string))
-	var diags diag.Diagnostics
-	token, err := meta.(*Client).GetToken()
-	if err != nil {
-		return diag.FromErr(err)
+	if v := firstBlock[policyProxyAutoConfigModel](ctx, m.ProxyAutoConfig, &diags); v != nil {
+		v.URL = optionalString(v.URL.ValueString(), types.StringNull())
+		m.ProxyAutoConfig = refreshBlock(ctx, policyProxyAutoConfigType, types.ListNull(policyProxyAutoConfigType), v, &diags)
 	}
-	api := meta.(*Client).API.PoliciesApi
-
-	// Get policy
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
-	request := api.PoliciesIdGet(ctx, d.Id())
-	policy, _, err := request.Execute()
-	if err != nil {
-		return diag.Errorf("Failed to delete policy while GET, %s", err)
+	if v := firstBlock[policyTrustedNetworkCheckModel](ctx, m.TrustedNetworkCheck, &diags); v != nil {
+		v.DNSSuffix = optionalString(v.DNSSuffix.ValueString(), types.StringNull())
+		m.TrustedNetworkCheck = refreshBlock(ctx, policyTrustedNetworkCheckType, types.ListNull(policyTrustedNetworkCheckType), v, &diags)
 	}
-
-	_, err = api.PoliciesIdDelete(ctx, policy.GetId()).Execute()
-	if err != nil {
-		return diag.Errorf("Failed to delete policy, %s", err)
+	if v := firstBlock[policyClientProfileSettingsModel](ctx, m.ClientProfileSettings, &diags); v != nil {
+		v.Force = optionalBool(v.Force.ValueBool(), types.BoolNull())
+		m.ClientProfileSettings = refreshBlock(ctx, policyClientProfileSettingsType, types.ListNull(policyClientProfileSettingsType), v, &diags)
 	}
-	d.SetId("")
 	return diags
 }
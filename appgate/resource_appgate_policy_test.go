@@ -1,9 +1,21 @@
 package appgate
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
@@ -12,9 +24,9 @@ func TestAccPolicyBasic(t *testing.T) {
 	resourceName := "appgatesdp_policy.test_policy"
 	rName := RandStringFromCharSet(10, CharSetAlphaNum)
 	resource.Test(t, resource.TestCase{
-		PreCheck:     func() { testAccPreCheck(t) },
-		Providers:    testAccProviders,
-		CheckDestroy: testAccCheckPolicyDestroy,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckPolicyDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config: testAccCheckPolicyBasic(rName),
@@ -305,9 +317,9 @@ func TestAccPolicyClientSettings55(t *testing.T) {
 		EOF`,
 	}
 	resource.ParallelTest(t, resource.TestCase{
-		PreCheck:     func() { testAccPreCheck(t) },
-		Providers:    testAccProviders,
-		CheckDestroy: testAccCheckPolicyDestroy,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckPolicyDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config: testAccCheckPolicyClientSettings(context),
@@ -480,9 +492,9 @@ func TestAccPolicyDnsSettings55(t *testing.T) {
 		EOF`,
 	}
 	resource.ParallelTest(t, resource.TestCase{
-		PreCheck:     func() { testAccPreCheck(t) },
-		Providers:    testAccProviders,
-		CheckDestroy: testAccCheckPolicyDestroy,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckPolicyDestroy,
 		Steps: []resource.TestStep{
 			{
 				PreConfig: func() {
@@ -730,9 +742,9 @@ func TestAccPolicyClientProfileSettings61(t *testing.T) {
 		EOF`,
 	}
 	resource.Test(t, resource.TestCase{
-		PreCheck:     func() { testAccPreCheck(t) },
-		Providers:    testAccProviders,
-		CheckDestroy: testAccCheckPolicyDestroy,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckPolicyDestroy,
 		Steps: []resource.TestStep{
 			{
 				PreConfig: func() {
@@ -836,9 +848,9 @@ func TestAccPolicyClientProfileSettings62(t *testing.T) {
 		EOF`,
 	}
 	resource.Test(t, resource.TestCase{
-		PreCheck:     func() { testAccPreCheck(t) },
-		Providers:    testAccProviders,
-		CheckDestroy: testAccCheckPolicyDestroy,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckPolicyDestroy,
 		Steps: []resource.TestStep{
 			{
 				PreConfig: func() {
@@ -930,3 +942,516 @@ resource "appgatesdp_policy" "test_policy62" {
 }
 `, context)
 }
+
+// testPolicyController is a mock controller that stores the policies in memory.
+type testPolicyController struct {
+	mu       sync.Mutex
+	policies map[string]openapi.Policy
+	requests []openapi.Policy
+}
+
+func newTestPolicyController(t *testing.T, mux *http.ServeMux) *testPolicyController {
+	c := &testPolicyController{policies: map[string]openapi.Policy{}}
+	mux.HandleFunc("/admin/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, loginResponse)
+	})
+	write := func(w http.ResponseWriter, status int, v interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(v)
+	}
+	mux.HandleFunc("/admin/policies", func(w http.ResponseWriter, r *http.Request) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if r.Method == http.MethodPost {
+			var p openapi.Policy
+			if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+				t.Error(err)
+			}
+			c.requests = append(c.requests, p)
+			c.policies[p.GetId()] = p
+			write(w, http.StatusCreated, p)
+			return
+		}
+		data := make([]openapi.Policy, 0, len(c.policies))
+		for _, p := range c.policies {
+			if strings.Contains(p.GetName(), r.URL.Query().Get("query")) {
+				data = append(data, p)
+			}
+		}
+		write(w, http.StatusOK, map[string]interface{}{"data": data})
+	})
+	mux.HandleFunc("/admin/policies/", func(w http.ResponseWriter, r *http.Request) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		id := strings.TrimPrefix(r.URL.Path, "/admin/policies/")
+		p, ok := c.policies[id]
+		if !ok {
+			write(w, http.StatusNotFound, map[string]string{"id": "not found", "message": "Policy not found."})
+			return
+		}
+		switch r.Method {
+		case http.MethodPut:
+			if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+				t.Error(err)
+			}
+			c.requests = append(c.requests, p)
+			c.policies[id] = p
+		case http.MethodDelete:
+			delete(c.policies, id)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		write(w, http.StatusOK, p)
+	})
+	return c
+}
+
+func (c *testPolicyController) lastRequest(t *testing.T) openapi.Policy {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.requests) == 0 {
+		t.Fatal("expected a request to the controller")
+	}
+	return c.requests[len(c.requests)-1]
+}
+
+// testFrameworkServer returns the terraform-plugin-framework provider server, configured with
+// the client if it is not nil.
+func testFrameworkServer(t *testing.T, client *Client) (tfprotov5.ProviderServer, *tfprotov5.GetProviderSchemaResponse) {
+	p := Provider()
+	s := providerserver.NewProtocol5(newFrameworkProvider(p)())()
+	schemas, err := s.GetProviderSchema(context.Background(), &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil || hasError(schemas.Diagnostics) {
+		t.Fatalf("GetProviderSchema %v %+v", err, schemas.Diagnostics)
+	}
+	if client == nil {
+		return s, schemas
+	}
+	p.SetMeta(client)
+	config := testObjectValue(schemas.Provider.ValueType(), nil)
+	resp, err := s.ConfigureProvider(context.Background(), &tfprotov5.ConfigureProviderRequest{Config: testDynamicValue(t, config)})
+	if err != nil || hasError(resp.Diagnostics) {
+		t.Fatalf("ConfigureProvider %v %+v", err, resp.Diagnostics)
+	}
+	return s, schemas
+}
+
+// testObjectValue returns an object of type ty with values, other attributes are null and blocks are empty.
+func testObjectValue(ty tftypes.Type, values map[string]tftypes.Value) tftypes.Value {
+	o := ty.(tftypes.Object)
+	attributes := make(map[string]tftypes.Value, len(o.AttributeTypes))
+	for k, t := range o.AttributeTypes {
+		if v, ok := values[k]; ok {
+			attributes[k] = v
+			continue
+		}
+		switch t := t.(type) {
+		case tftypes.List:
+			if _, ok := t.ElementType.(tftypes.Object); ok {
+				attributes[k] = tftypes.NewValue(t, []tftypes.Value{})
+				continue
+			}
+		case tftypes.Set:
+			if _, ok := t.ElementType.(tftypes.Object); ok {
+				attributes[k] = tftypes.NewValue(t, []tftypes.Value{})
+				continue
+			}
+		}
+		attributes[k] = tftypes.NewValue(t, nil)
+	}
+	return tftypes.NewValue(ty, attributes)
+}
+
+func testDynamicValue(t *testing.T, v tftypes.Value) *tfprotov5.DynamicValue {
+	dv, err := tfprotov5.NewDynamicValue(v.Type(), v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &dv
+}
+
+func testAttributes(t *testing.T, dv *tfprotov5.DynamicValue, ty tftypes.Type) map[string]tftypes.Value {
+	v, err := dv.Unmarshal(ty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.IsNull() {
+		return nil
+	}
+	attributes := map[string]tftypes.Value{}
+	if err := v.As(&attributes); err != nil {
+		t.Fatal(err)
+	}
+	return attributes
+}
+
+func testStringValue(t *testing.T, v tftypes.Value) string {
+	var s string
+	if err := v.As(&s); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func testStringsValue(t *testing.T, v tftypes.Value) []string {
+	var elements []tftypes.Value
+	if err := v.As(&elements); err != nil {
+		t.Fatal(err)
+	}
+	values := make([]string, 0, len(elements))
+	for _, e := range elements {
+		values = append(values, testStringValue(t, e))
+	}
+	sort.Strings(values)
+	return values
+}
+
+func testStringSet(values ...string) tftypes.Value {
+	elements := make([]tftypes.Value, 0, len(values))
+	for _, v := range values {
+		elements = append(elements, tftypes.NewValue(tftypes.String, v))
+	}
+	return tftypes.NewValue(tftypes.Set{ElementType: tftypes.String}, elements)
+}
+
+func testPolicyClient(t *testing.T, port int) *Client {
+	c := &Config{
+		URL:          fmt.Sprintf("http://localhost:%d", port),
+		Username:     "admin",
+		Password:     "admin",
+		Version:      22,
+		LoginTimeout: 1 * time.Minute,
+		DefaultTags:  []string{"terraform"},
+	}
+	client, err := c.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestAccessPolicyResourceFramework(t *testing.T) {
+	_, _, mux, _, port, teardown := setup()
+	defer teardown()
+	controller := newTestPolicyController(t, mux)
+	s, schemas := testFrameworkServer(t, testPolicyClient(t, port))
+	ctx := context.Background()
+	const typeName = "appgatesdp_access_policy"
+	ty := schemas.ResourceSchemas[typeName].ValueType()
+	null := tftypes.NewValue(ty, nil)
+
+	config := testObjectValue(ty, map[string]tftypes.Value{
+		"name":         tftypes.NewValue(tftypes.String, "developers"),
+		"tags":         testStringSet("Developers"),
+		"entitlements": testStringSet("e1"),
+	})
+	plan, err := s.PlanResourceChange(ctx, &tfprotov5.PlanResourceChangeRequest{
+		TypeName:         typeName,
+		PriorState:       testDynamicValue(t, null),
+		ProposedNewState: testDynamicValue(t, config),
+		Config:           testDynamicValue(t, config),
+	})
+	if err != nil || hasError(plan.Diagnostics) {
+		t.Fatalf("PlanResourceChange %v %+v", err, plan.Diagnostics)
+	}
+	planned := testAttributes(t, plan.PlannedState, ty)
+	if got := testStringValue(t, planned["type"]); got != PolicyTypeAccess {
+		t.Errorf("got planned type %q", got)
+	}
+	if got := testStringValue(t, planned["expression"]); got != emptyPolicyExpression {
+		t.Errorf("got planned expression %q", got)
+	}
+	if got := testStringsValue(t, planned["tags_all"]); strings.Join(got, ",") != "developers,terraform" {
+		t.Errorf("got planned tags_all %v", got)
+	}
+
+	created, err := s.ApplyResourceChange(ctx, &tfprotov5.ApplyResourceChangeRequest{
+		TypeName:     typeName,
+		PriorState:   testDynamicValue(t, null),
+		PlannedState: plan.PlannedState,
+		Config:       testDynamicValue(t, config),
+	})
+	if err != nil || hasError(created.Diagnostics) {
+		t.Fatalf("ApplyResourceChange %v %+v", err, created.Diagnostics)
+	}
+	posted := controller.lastRequest(t)
+	if posted.GetType() != PolicyTypeAccess || strings.Join(posted.GetTags(), ",") != "developers,terraform" {
+		t.Errorf("got type %q and tags %v", posted.GetType(), posted.GetTags())
+	}
+	if _, ok := posted.GetOverrideSiteOk(); ok {
+		t.Error("expected no override site")
+	}
+	state := testAttributes(t, created.NewState, ty)
+	id := testStringValue(t, state["id"])
+	if id != posted.GetId() || testStringValue(t, state["policy_id"]) != id {
+		t.Errorf("got id %q, posted %q", id, posted.GetId())
+	}
+	if !state["override_site"].IsNull() || !state["entitlement_links"].IsNull() {
+		t.Error("expected optional attributes that are not configured to be null")
+	}
+	if got := testStringsValue(t, state["tags"]); strings.Join(got, ",") != "Developers" {
+		t.Errorf("got tags %v, expected the configured case", got)
+	}
+
+	read, err := s.ReadResource(ctx, &tfprotov5.ReadResourceRequest{TypeName: typeName, CurrentState: created.NewState})
+	if err != nil || hasError(read.Diagnostics) {
+		t.Fatalf("ReadResource %v %+v", err, read.Diagnostics)
+	}
+	readState, _ := read.NewState.Unmarshal(ty)
+	createdState, _ := created.NewState.Unmarshal(ty)
+	if diff, _ := createdState.Diff(readState); len(diff) > 0 {
+		t.Errorf("expected no changes after read, got %v", diff)
+	}
+
+	updatedConfig := testObjectValue(ty, map[string]tftypes.Value{
+		"name":         tftypes.NewValue(tftypes.String, "developers"),
+		"notes":        tftypes.NewValue(tftypes.String, "updated by terraform"),
+		"tags":         testStringSet("Developers"),
+		"entitlements": testStringSet("e1"),
+	})
+	proposed := map[string]tftypes.Value{}
+	for k, v := range state {
+		proposed[k] = v
+	}
+	proposed["notes"] = tftypes.NewValue(tftypes.String, "updated by terraform")
+	updatePlan, err := s.PlanResourceChange(ctx, &tfprotov5.PlanResourceChangeRequest{
+		TypeName:         typeName,
+		PriorState:       created.NewState,
+		PriorPrivate:     created.Private,
+		ProposedNewState: testDynamicValue(t, tftypes.NewValue(ty, proposed)),
+		Config:           testDynamicValue(t, updatedConfig),
+	})
+	if err != nil || hasError(updatePlan.Diagnostics) {
+		t.Fatalf("PlanResourceChange %v %+v", err, updatePlan.Diagnostics)
+	}
+	if _, ok := testAttributes(t, updatePlan.PlannedState, ty)["etag"]; ok {
+		t.Error("expected etag to be kept in the private state")
+	}
+
+	controller.mu.Lock()
+	modified := controller.policies[id]
+	// ringfence_rules is not in the schema of appgatesdp_access_policy
+	modified.SetRingfenceRules([]string{"r1"})
+	controller.policies[id] = modified
+	controller.mu.Unlock()
+	conflict, err := s.ApplyResourceChange(ctx, &tfprotov5.ApplyResourceChangeRequest{
+		TypeName:       typeName,
+		PriorState:     created.NewState,
+		PlannedState:   updatePlan.PlannedState,
+		PlannedPrivate: updatePlan.PlannedPrivate,
+		Config:         testDynamicValue(t, updatedConfig),
+	})
+	if err != nil || !hasError(conflict.Diagnostics) || !strings.Contains(conflict.Diagnostics[0].Summary, "modified outside Terraform") {
+		t.Fatalf("expected the update to fail, got %v %+v", err, conflict.Diagnostics)
+	}
+
+	read, err = s.ReadResource(ctx, &tfprotov5.ReadResourceRequest{TypeName: typeName, CurrentState: created.NewState, Private: created.Private})
+	if err != nil || hasError(read.Diagnostics) {
+		t.Fatalf("ReadResource %v %+v", err, read.Diagnostics)
+	}
+	updated, err := s.ApplyResourceChange(ctx, &tfprotov5.ApplyResourceChangeRequest{
+		TypeName:       typeName,
+		PriorState:     read.NewState,
+		PlannedState:   updatePlan.PlannedState,
+		PlannedPrivate: read.Private,
+		Config:         testDynamicValue(t, updatedConfig),
+	})
+	if err != nil || hasError(updated.Diagnostics) {
+		t.Fatalf("ApplyResourceChange %v %+v", err, updated.Diagnostics)
+	}
+	if put := controller.lastRequest(t); put.GetNotes() != "updated by terraform" || len(put.GetRingfenceRules()) != 1 {
+		t.Errorf("expected the update to only change notes, got notes %q ringfence rules %v", put.GetNotes(), put.GetRingfenceRules())
+	}
+
+	imported, err := s.ImportResourceState(ctx, &tfprotov5.ImportResourceStateRequest{TypeName: typeName, ID: "name:developers"})
+	if err != nil || hasError(imported.Diagnostics) || len(imported.ImportedResources) != 1 {
+		t.Fatalf("ImportResourceState %v %+v", err, imported.Diagnostics)
+	}
+	if got := testStringValue(t, testAttributes(t, imported.ImportedResources[0].State, ty)["id"]); got != id {
+		t.Errorf("got imported id %q, expected %q", got, id)
+	}
+	read, err = s.ReadResource(ctx, &tfprotov5.ReadResourceRequest{TypeName: typeName, CurrentState: imported.ImportedResources[0].State})
+	if err != nil || hasError(read.Diagnostics) {
+		t.Fatalf("ReadResource %v %+v", err, read.Diagnostics)
+	}
+	if got := testStringsValue(t, testAttributes(t, read.NewState, ty)["tags"]); strings.Join(got, ",") != "developers,terraform" {
+		t.Errorf("got imported tags %v, expected all tags", got)
+	}
+
+	for i := 0; i < 2; i++ {
+		deleted, err := s.ApplyResourceChange(ctx, &tfprotov5.ApplyResourceChangeRequest{
+			TypeName:     typeName,
+			PriorState:   updated.NewState,
+			PlannedState: testDynamicValue(t, null),
+			Config:       testDynamicValue(t, null),
+		})
+		if err != nil || hasError(deleted.Diagnostics) {
+			t.Fatalf("delete %d: %v %+v", i, err, deleted.Diagnostics)
+		}
+	}
+	read, err = s.ReadResource(ctx, &tfprotov5.ReadResourceRequest{TypeName: typeName, CurrentState: updated.NewState})
+	if err != nil || hasError(read.Diagnostics) {
+		t.Fatalf("ReadResource %v %+v", err, read.Diagnostics)
+	}
+	if testAttributes(t, read.NewState, ty) != nil {
+		t.Error("expected the deleted policy to be removed from the state")
+	}
+}
+
+func TestDevicePolicyResourceFrameworkUnsupportedAttributes(t *testing.T) {
+	_, _, mux, _, port, teardown := setup()
+	defer teardown()
+	newTestPolicyController(t, mux)
+	client := testPolicyClient(t, port)
+	if _, err := client.GetToken(); err != nil {
+		t.Fatal(err)
+	}
+	client.ApplianceVersion, _ = version.NewVersion("6.0.0")
+	s, schemas := testFrameworkServer(t, client)
+	const typeName = "appgatesdp_device_policy"
+	ty := schemas.ResourceSchemas[typeName].ValueType()
+
+	config := testObjectValue(ty, map[string]tftypes.Value{
+		"name":                   tftypes.NewValue(tftypes.String, "devices"),
+		"custom_client_help_url": tftypes.NewValue(tftypes.String, "https://help.example.com"),
+	})
+	plan, err := s.PlanResourceChange(context.Background(), &tfprotov5.PlanResourceChangeRequest{
+		TypeName:         typeName,
+		PriorState:       testDynamicValue(t, tftypes.NewValue(ty, nil)),
+		ProposedNewState: testDynamicValue(t, config),
+		Config:           testDynamicValue(t, config),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hasError(plan.Diagnostics) || !strings.Contains(plan.Diagnostics[0].Detail, "custom_client_help_url is not supported by appliance version 6.0.0") {
+		t.Fatalf("expected the plan to fail, got %+v", plan.Diagnostics)
+	}
+}
+
+func TestDevicePolicyResourceFrameworkBlocks(t *testing.T) {
+	_, _, mux, _, port, teardown := setup()
+	defer teardown()
+	controller := newTestPolicyController(t, mux)
+	s, schemas := testFrameworkServer(t, testPolicyClient(t, port))
+	ctx := context.Background()
+	const typeName = "appgatesdp_device_policy"
+	ty := schemas.ResourceSchemas[typeName].ValueType()
+	null := tftypes.NewValue(ty, nil)
+	pacType := ty.(tftypes.Object).AttributeTypes["proxy_auto_config"].(tftypes.List)
+
+	config := testObjectValue(ty, map[string]tftypes.Value{
+		"name": tftypes.NewValue(tftypes.String, "devices"),
+		"proxy_auto_config": tftypes.NewValue(pacType, []tftypes.Value{
+			tftypes.NewValue(pacType.ElementType, map[string]tftypes.Value{
+				"enabled": tftypes.NewValue(tftypes.Bool, nil),
+				"url":     tftypes.NewValue(tftypes.String, "http://pac.example.com"),
+				"persist": tftypes.NewValue(tftypes.Bool, true),
+			}),
+		}),
+	})
+	plan, err := s.PlanResourceChange(ctx, &tfprotov5.PlanResourceChangeRequest{
+		TypeName:         typeName,
+		PriorState:       testDynamicValue(t, null),
+		ProposedNewState: testDynamicValue(t, config),
+		Config:           testDynamicValue(t, config),
+	})
+	if err != nil || hasError(plan.Diagnostics) {
+		t.Fatalf("PlanResourceChange %v %+v", err, plan.Diagnostics)
+	}
+	created, err := s.ApplyResourceChange(ctx, &tfprotov5.ApplyResourceChangeRequest{
+		TypeName:     typeName,
+		PriorState:   testDynamicValue(t, null),
+		PlannedState: plan.PlannedState,
+		Config:       testDynamicValue(t, config),
+	})
+	if err != nil || hasError(created.Diagnostics) {
+		t.Fatalf("ApplyResourceChange %v %+v", err, created.Diagnostics)
+	}
+	posted := controller.lastRequest(t)
+	pac := posted.GetProxyAutoConfig()
+	if pac.GetUrl() != "http://pac.example.com" || !pac.GetPersist() {
+		t.Errorf("got proxy auto config %+v", pac)
+	}
+	if _, ok := pac.GetEnabledOk(); ok {
+		t.Error("expected enabled to be left to the controller")
+	}
+	if _, ok := posted.GetClientSettingsOk(); ok {
+		t.Error("expected no client settings")
+	}
+	state := testAttributes(t, created.NewState, ty)
+	var blocks []tftypes.Value
+	if err := state["proxy_auto_config"].As(&blocks); err != nil || len(blocks) != 1 || !blocks[0].IsFullyKnown() {
+		t.Fatalf("got proxy_auto_config %s", state["proxy_auto_config"])
+	}
+	var clientSettings []tftypes.Value
+	if err := state["client_settings"].As(&clientSettings); err != nil || len(clientSettings) != 0 {
+		t.Fatalf("got client_settings %s", state["client_settings"])
+	}
+
+	read, err := s.ReadResource(ctx, &tfprotov5.ReadResourceRequest{TypeName: typeName, CurrentState: created.NewState})
+	if err != nil || hasError(read.Diagnostics) {
+		t.Fatalf("ReadResource %v %+v", err, read.Diagnostics)
+	}
+	readState, _ := read.NewState.Unmarshal(ty)
+	createdState, _ := created.NewState.Unmarshal(ty)
+	if diff, _ := createdState.Diff(readState); len(diff) > 0 {
+		t.Errorf("expected no changes after read, got %v", diff)
+	}
+}
+
+func TestPolicyResourceFrameworkUpgradeState(t *testing.T) {
+	s, schemas := testFrameworkServer(t, nil)
+	const typeName = "appgatesdp_policy"
+	ty := schemas.ResourceSchemas[typeName].ValueType()
+	// state saved by the SDK resource, with empty values for the optional attributes that are not configured.
+	sdkState := `{
+		"id": "4c07bc67-57ea-42dd-b702-c2d6c45419fc",
+		"policy_id": "4c07bc67-57ea-42dd-b702-c2d6c45419fc",
+		"name": "devices",
+		"notes": "Managed by terraform",
+		"disabled": false,
+		"expression": "return true;",
+		"type": "Device",
+		"tags": ["api"],
+		"tags_all": ["api"],
+		"entitlements": [],
+		"override_site": "",
+		"override_nearest_site": false,
+		"custom_client_help_url": "",
+		"tamper_proofing": true,
+		"proxy_auto_config": [{"enabled": true, "url": "", "persist": false}],
+		"client_profile_settings": [{"enabled": true, "profiles": ["portal"], "force": false}],
+		"dns_settings": []
+	}`
+	resp, err := s.UpgradeResourceState(context.Background(), &tfprotov5.UpgradeResourceStateRequest{
+		TypeName: typeName,
+		Version:  1,
+		RawState: &tfprotov5.RawState{JSON: []byte(sdkState)},
+	})
+	if err != nil || hasError(resp.Diagnostics) {
+		t.Fatalf("UpgradeResourceState %v %+v", err, *resp.Diagnostics[0])
+	}
+	state := testAttributes(t, resp.UpgradedState, ty)
+	if got := testStringValue(t, state["name"]); got != "devices" {
+		t.Errorf("got name %q", got)
+	}
+	for _, k := range []string{"entitlements", "override_site", "override_nearest_site", "custom_client_help_url", "ringfence_rules"} {
+		if !state[k].IsNull() {
+			t.Errorf("expected %s to be null, got %s", k, state[k])
+		}
+	}
+	var pac []tftypes.Value
+	if err := state["proxy_auto_config"].As(&pac); err != nil || len(pac) != 1 {
+		t.Fatalf("got proxy_auto_config %s", state["proxy_auto_config"])
+	}
+	nested := map[string]tftypes.Value{}
+	if err := pac[0].As(&nested); err != nil {
+		t.Fatal(err)
+	}
+	if !nested["url"].IsNull() || nested["enabled"].IsNull() {
+		t.Errorf("got proxy_auto_config %s", pac[0])
+	}
+}
@@ -19,7 +19,7 @@ func resourceAppgateReplicationSource() *schema.Resource {
 		ReadContext:   resourceAppgateReplicationSourceRead,
 		DeleteContext: resourceAppgateReplicationSourceDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Timeouts: &schema.ResourceTimeout{
@@ -74,7 +74,7 @@ func resourceAppgateReplicationSourceCreate(ctx context.Context, d *schema.Resou
 
 	log.Printf("[DEBUG] Registration token for Replication Source creation: %s", registrationToken.GetToken())
 
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	_, _, err = api.ReplicationSourcePost(ctx).ReplicationRegistrationToken(registrationToken).Execute()
 	if err != nil {
 		log.Printf("[DEBUG] Error creating Replication Source: %v", err)
@@ -92,7 +92,7 @@ func resourceAppgateReplicationSourceRead(ctx context.Context, d *schema.Resourc
 		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.ReplicationSourceApi
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	replSource, response, err := api.ReplicationSourceGet(ctx).Execute()
 	if err != nil {
 		d.SetId("")
@@ -115,7 +115,7 @@ func resourceAppgateReplicationSourceDelete(ctx context.Context, d *schema.Resou
 		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.ReplicationSourceApi
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	if _, response, err := api.ReplicationSourceDelete(ctx).Execute(); err != nil && response != nil && response.StatusCode != http.StatusPreconditionFailed {
 		return diag.FromErr(fmt.Errorf("could not delete Replication Source %w", prettyPrintAPIError(err)))
 	}
@@ -22,8 +22,9 @@ func resourceAppgateReplicationTarget() *schema.Resource {
 		ReadContext:   resourceAppgateReplicationTargetRead,
 		UpdateContext: resourceAppgateReplicationTargetUpdate,
 		DeleteContext: resourceAppgateReplicationTargetDelete,
+		CustomizeDiff: etagCustomizeDiff,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Timeouts: &schema.ResourceTimeout{
@@ -34,8 +35,9 @@ func resourceAppgateReplicationTarget() *schema.Resource {
 
 		SchemaVersion: 1,
 		Schema: func() map[string]*schema.Schema {
-			return mergeSchemaMaps(baseEntitySchema(), map[string]*schema.Schema{
+			s := mergeSchemaMaps(baseEntitySchema(), map[string]*schema.Schema{
 				"replication_target_id": resourceUUID(),
+				"etag":                  etagSchema(),
 				"replication_tags": {
 					Type:        schema.TypeSet,
 					Description: "Array of tags.",
@@ -57,6 +59,9 @@ func resourceAppgateReplicationTarget() *schema.Resource {
 					Computed:  true,
 				},
 			})
+			// replication targets have no tags on the controller, so default_tags are not added.
+			delete(s, "tags_all")
+			return s
 		}(),
 	}
 }
@@ -69,15 +74,13 @@ func resourceAppgateReplicationTargetCreate(ctx context.Context, d *schema.Resou
 	}
 	api := meta.(*Client).API.ReplicationTargetsApi
 	args := openapi.ReplicationTarget{}
-	if v, ok := d.GetOk("replication_target_id"); ok {
-		args.SetId(v.(string))
-	}
+	args.SetId(resourceObjectID(d, "replication_target_id"))
 	args.SetName(d.Get("name").(string))
 	args.SetNotes(d.Get("notes").(string))
 
 	args.SetReplicationTags(schemaExtractReplicationTags(d))
 
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	replTarget, _, err := api.ReplicationTargetsPost(ctx).ReplicationTarget(args).Execute()
 	if err != nil {
 		return diag.FromErr(prettyPrintAPIError(err))
@@ -105,7 +108,7 @@ func resourceAppgateReplicationTargetRead(ctx context.Context, d *schema.Resourc
 		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.ReplicationTargetsApi
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	replTarget, response, err := api.ReplicationTargetsIdGet(ctx, d.Id()).Execute()
 	if err != nil {
 		d.SetId("")
@@ -114,6 +117,7 @@ func resourceAppgateReplicationTargetRead(ctx context.Context, d *schema.Resourc
 		}
 		return diag.FromErr(prettyPrintAPIError(err))
 	}
+	d.Set("etag", objectETag(replTarget))
 	replToken, response, err := api.ReplicationTargetsIdExportGet(ctx, replTarget.GetId()).Execute()
 	if err != nil && response.StatusCode != http.StatusPreconditionFailed {
 		if response != nil && response.StatusCode == http.StatusNotFound {
@@ -144,11 +148,14 @@ func resourceAppgateReplicationTargetUpdate(ctx context.Context, d *schema.Resou
 		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.ReplicationTargetsApi
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	replTarget, _, err := api.ReplicationTargetsIdGet(ctx, d.Id()).Execute()
 	if err != nil {
 		return diag.FromErr(prettyPrintAPIError(err))
 	}
+	if diags := checkNotModified(d, meta, "Replication target", replTarget); diags.HasError() {
+		return diags
+	}
 
 	if d.HasChange("name") {
 		replTarget.SetName(d.Get("name").(string))
@@ -160,10 +167,10 @@ func resourceAppgateReplicationTargetUpdate(ctx context.Context, d *schema.Resou
 		replTarget.SetReplicationTags(schemaExtractReplicationTags(d))
 	}
 
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	_, _, err = api.ReplicationTargetsIdPut(ctx, d.Id()).ReplicationTarget(*replTarget).Execute()
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("could not update Replication Target %w", prettyPrintAPIError(err)))
+		return apiErrorDiagnostics("could not update Replication Target", err, resourceAppgateReplicationTarget().Schema)
 	}
 	return resourceAppgateReplicationTargetRead(ctx, d, meta)
 }
@@ -175,7 +182,7 @@ func resourceAppgateReplicationTargetDelete(ctx context.Context, d *schema.Resou
 		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.ReplicationTargetsApi
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	if _, err := api.ReplicationTargetsIdDelete(ctx, d.Id()).Execute(); err != nil {
 		return diag.FromErr(fmt.Errorf("could not delete Replication Target %w", prettyPrintAPIError(err)))
 	}
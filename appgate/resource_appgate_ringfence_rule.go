@@ -8,17 +8,20 @@ import (
 
 	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func resourceAppgateRingfenceRule() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceAppgateRingfenceRuleCreate,
-		Read:   resourceAppgateRingfenceRuleRead,
-		Update: resourceAppgateRingfenceRuleUpdate,
-		Delete: resourceAppgateRingfenceRuleDelete,
+		CreateContext: resourceAppgateRingfenceRuleCreate,
+		ReadContext:   resourceAppgateRingfenceRuleRead,
+		UpdateContext: resourceAppgateRingfenceRuleUpdate,
+		DeleteContext: resourceAppgateRingfenceRuleDelete,
+		CustomizeDiff: customdiff.All(tagsCustomizeDiff, etagCustomizeDiff),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Timeouts: &schema.ResourceTimeout{
@@ -31,6 +34,7 @@ func resourceAppgateRingfenceRule() *schema.Resource {
 		Schema: map[string]*schema.Schema{
 
 			"ringfence_rule_id": resourceUUID(),
+			"etag":              etagSchema(),
 
 			"name": {
 				Type:        schema.TypeString,
@@ -47,6 +51,8 @@ func resourceAppgateRingfenceRule() *schema.Resource {
 
 			"tags": tagsSchema(),
 
+			"tags_all": tagsAllSchema(),
+
 			"actions": {
 				Type:     schema.TypeList,
 				Required: true,
@@ -125,67 +131,64 @@ func resourceAppgateRingfenceRule() *schema.Resource {
 	}
 }
 
-func resourceAppgateRingfenceRuleCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateRingfenceRuleCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Creating Ringfence rule with name: %s", d.Get("name").(string))
-	ctx := context.Background()
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.RingfenceRulesApi
 
 	args := openapi.NewRingfenceRuleWithDefaults()
-	if v, ok := d.GetOk("ringfence_rule_id"); ok {
-		args.SetId(v.(string))
-	}
+	args.SetId(resourceObjectID(d, "ringfence_rule_id"))
 	args.SetName(d.Get("name").(string))
 
 	if c, ok := d.GetOk("notes"); ok {
 		args.SetNotes(c.(string))
 	}
-	args.SetTags(schemaExtractTags(d))
+	args.SetTags(schemaExtractTags(d, meta))
 
 	if c, ok := d.GetOk("actions"); ok {
 		action, err := readRingfencActionFromConfig(c.([]interface{}))
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		args.SetActions(action)
 	}
 
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	request := api.RingfenceRulesPost(ctx)
 	request = request.RingfenceRule(*args)
 	ringfenceRule, _, err := request.Execute()
 	if err != nil {
-		return fmt.Errorf("Could not create Ringfence rule  %w", prettyPrintAPIError(err))
+		return apiErrorDiagnostics("Could not create Ringfence rule", err, resourceAppgateRingfenceRule().Schema)
 	}
 
 	d.SetId(ringfenceRule.GetId())
-	return resourceAppgateRingfenceRuleRead(d, meta)
+	return resourceAppgateRingfenceRuleRead(ctx, d, meta)
 }
 
-func resourceAppgateRingfenceRuleRead(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateRingfenceRuleRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Read Ringfence rule with name: %s", d.Get("name").(string))
-	ctx := context.Background()
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.RingfenceRulesApi
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	request := api.RingfenceRulesIdGet(ctx, d.Id())
 	ringfenceRule, _, err := request.Execute()
 	if err != nil {
-		return fmt.Errorf("Failed to read Ringfence rule, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read Ringfence rule, %w", err))
 	}
 	d.Set("ringfence_rule_id", ringfenceRule.GetId())
+	d.Set("etag", objectETag(ringfenceRule))
 	d.Set("name", ringfenceRule.Name)
 	d.Set("notes", ringfenceRule.Notes)
-	d.Set("tags", ringfenceRule.Tags)
+	setTags(d, meta, ringfenceRule.Tags)
 	if ringfenceRule.Actions != nil {
 		if err = d.Set("actions", flattenRingfenceActions(ringfenceRule.Actions)); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 	return nil
@@ -218,18 +221,21 @@ func flattenRingfenceActions(in []openapi.RingfenceRuleAllOfActions) []map[strin
 	return out
 }
 
-func resourceAppgateRingfenceRuleUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateRingfenceRuleUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Updating Ringfence rule with name: %s", d.Get("name").(string))
 	token, err := meta.(*Client).GetToken()
-	ctx := context.WithValue(context.Background(), openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.RingfenceRulesApi
 	request := api.RingfenceRulesIdGet(ctx, d.Id())
 	originalRingfenceRule, _, err := request.Execute()
 	if err != nil {
-		return fmt.Errorf("Failed to read Ringfence rule, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read Ringfence rule, %w", err))
+	}
+	if diags := checkNotModified(d, meta, "Ringfence rule", originalRingfenceRule); diags.HasError() {
+		return diags
 	}
 
 	if d.HasChange("name") {
@@ -240,43 +246,43 @@ func resourceAppgateRingfenceRuleUpdate(d *schema.ResourceData, meta interface{}
 		originalRingfenceRule.SetNotes(d.Get("notes").(string))
 	}
 
-	if d.HasChange("tags") {
-		originalRingfenceRule.SetTags(schemaExtractTags(d))
+	if d.HasChanges("tags", "tags_all") {
+		originalRingfenceRule.SetTags(schemaExtractTags(d, meta))
 	}
 	if d.HasChange("actions") {
 		_, n := d.GetChange("actions")
 		actions, err := readRingfencActionFromConfig(n.([]interface{}))
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		originalRingfenceRule.SetActions(actions)
 	}
 	req := api.RingfenceRulesIdPut(ctx, d.Id())
 	_, _, err = req.RingfenceRule(*originalRingfenceRule).Execute()
 	if err != nil {
-		return fmt.Errorf("Could not update Ringfence rule %w", prettyPrintAPIError(err))
+		return apiErrorDiagnostics("Could not update Ringfence rule", err, resourceAppgateRingfenceRule().Schema)
 	}
 
-	return resourceAppgateRingfenceRuleRead(d, meta)
+	return resourceAppgateRingfenceRuleRead(ctx, d, meta)
 }
 
-func resourceAppgateRingfenceRuleDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateRingfenceRuleDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Delete Ringfence rule: %s", d.Get("name").(string))
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.RingfenceRulesApi
-	ctx := context.WithValue(context.Background(), openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	request := api.RingfenceRulesIdGet(ctx, d.Id())
 	ringfenceRule, _, err := request.Execute()
 	if err != nil {
-		return fmt.Errorf("Failed to delete Ringfence rule while GET, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to delete Ringfence rule while GET, %w", err))
 	}
 	deleteRequest := api.RingfenceRulesIdDelete(ctx, ringfenceRule.GetId())
 	_, err = deleteRequest.Execute()
 	if err != nil {
-		return fmt.Errorf("Failed to delete Ringfence rule, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to delete Ringfence rule, %w", err))
 	}
 	d.SetId("")
 	return nil
@@ -1,6 +1,7 @@
 package appgate
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -9,17 +10,20 @@ import (
 	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
 
 	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func resourceAppgateSite() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceAppgateSiteCreate,
-		Read:   resourceAppgateSiteRead,
-		Update: resourceAppgateSiteUpdate,
-		Delete: resourceAppgateSiteDelete,
+		CreateContext: resourceAppgateSiteCreate,
+		ReadContext:   resourceAppgateSiteRead,
+		UpdateContext: resourceAppgateSiteUpdate,
+		DeleteContext: resourceAppgateSiteDelete,
+		CustomizeDiff: customdiff.All(tagsCustomizeDiff, etagCustomizeDiff, attributeVersionCustomizeDiff("appgatesdp_site")),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: importStateByNameOrTag("site", listSiteImportCandidates),
 		},
 
 		Timeouts: &schema.ResourceTimeout{
@@ -33,6 +37,7 @@ func resourceAppgateSite() *schema.Resource {
 		Schema: map[string]*schema.Schema{
 
 			"site_id": resourceUUID(),
+			"etag":    etagSchema(),
 
 			"name": {
 				Type:        schema.TypeString,
@@ -54,6 +59,8 @@ func resourceAppgateSite() *schema.Resource {
 
 			"tags": tagsSchema(),
 
+			"tags_all": tagsAllSchema(),
+
 			"network_subnets": {
 				Type:        schema.TypeSet,
 				Description: "Network subnets in CIDR format to define the Site's boundaries. They are added as routes by the Client.",
@@ -541,27 +548,25 @@ func resourceAppgateSite() *schema.Resource {
 	}
 }
 
-func resourceAppgateSiteCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateSiteCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Creating Site: %s", d.Get("name").(string))
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.SitesApi
 	currentVersion := meta.(*Client).ApplianceVersion
 	args := openapi.Site{}
-	if v, ok := d.GetOk("site_id"); ok {
-		args.SetId(v.(string))
-	}
+	args.SetId(resourceObjectID(d, "site_id"))
 	args.SetName(d.Get("name").(string))
 	args.SetDescription(d.Get("description").(string))
 	args.SetNotes(d.Get("notes").(string))
-	args.SetTags(schemaExtractTags(d))
+	args.SetTags(schemaExtractTags(d, meta))
 
 	if v, ok := d.GetOk("network_subnets"); ok {
 		networkSubnets, err := readArrayOfStringsFromConfig(v.(*schema.Set).List())
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		args.SetNetworkSubnets(networkSubnets)
 	}
@@ -569,7 +574,7 @@ func resourceAppgateSiteCreate(d *schema.ResourceData, meta interface{}) error {
 	if v, ok := d.GetOk("ip_pool_mappings"); ok {
 		ipPoolMappings, err := readIPPoolMappingsFromConfig(v.(*schema.Set).List())
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		args.SetIpPoolMappings(ipPoolMappings)
 	}
@@ -577,7 +582,7 @@ func resourceAppgateSiteCreate(d *schema.ResourceData, meta interface{}) error {
 	if v, ok := d.GetOk("default_gateway"); ok {
 		DefaultGateway, err := readSiteDefaultGatewayFromConfig(v.(*schema.Set).List())
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		args.SetDefaultGateway(DefaultGateway)
 	}
@@ -589,7 +594,7 @@ func resourceAppgateSiteCreate(d *schema.ResourceData, meta interface{}) error {
 	if v, ok := d.GetOk("vpn"); ok {
 		vpn, err := readSiteVPNFromConfig(v.([]interface{}))
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		args.SetVpn(vpn)
 	}
@@ -597,62 +602,62 @@ func resourceAppgateSiteCreate(d *schema.ResourceData, meta interface{}) error {
 	if v, ok := d.GetOk("name_resolution"); ok {
 		nameResolution, err := readSiteNameResolutionFromConfig(currentVersion, v.([]interface{}))
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		args.SetNameResolution(nameResolution)
 	}
 
-	site, _, err := api.SitesPost(BaseAuthContext(token)).Site(args).Execute()
+	site, _, err := api.SitesPost(authContext(ctx, token)).Site(args).Execute()
 	if err != nil {
-		return fmt.Errorf("Could not create site %w", prettyPrintAPIError(err))
+		return apiErrorDiagnostics("Could not create site", err, resourceAppgateSite().Schema)
 	}
 
 	d.SetId(site.GetId())
 
-	return resourceAppgateSiteRead(d, meta)
+	return resourceAppgateSiteRead(ctx, d, meta)
 }
 
-func resourceAppgateSiteRead(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateSiteRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Reading Site Name: %s", d.Get("name").(string))
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.SitesApi
-	currentVersion := meta.(*Client).ApplianceVersion
 
-	request := api.SitesIdGet(BaseAuthContext(token), d.Id())
+	request := api.SitesIdGet(authContext(ctx, token), d.Id())
 	site, res, err := request.Execute()
 	if err != nil {
 		d.SetId("")
 		if res != nil && res.StatusCode == http.StatusNotFound {
 			return nil
 		}
-		return fmt.Errorf("Failed to read Site, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read Site, %w", err))
 	}
 
 	d.SetId(site.GetId())
 	d.Set("site_id", site.GetId())
+	d.Set("etag", objectETag(site))
 	d.Set("name", site.GetName())
 	d.Set("description", site.GetDescription())
 	d.Set("notes", site.GetNotes())
-	d.Set("tags", site.GetTags())
+	setTags(d, meta, site.GetTags())
 	d.Set("network_subnets", site.NetworkSubnets)
 	if site.IpPoolMappings != nil {
 		if err = d.Set("ip_pool_mappings", flattenSiteIPpoolmappning(site.GetIpPoolMappings())); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 	if site.DefaultGateway != nil {
 		if err = d.Set("default_gateway", flattenSiteDefaultGateway(*site.DefaultGateway)); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 	d.Set("entitlement_based_routing", site.EntitlementBasedRouting)
 
 	if site.Vpn != nil {
 		if err = d.Set("vpn", flattenSiteVPN(*site.Vpn)); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
@@ -662,12 +667,12 @@ func resourceAppgateSiteRead(d *schema.ResourceData, meta interface{}) error {
 		for _, l := range localNameResolutionList {
 			localNameResolution = l.(map[string]interface{})
 		}
-		ns, err := flattenNameResolution(currentVersion, localNameResolution, *site.NameResolution)
+		ns, err := flattenNameResolution(localNameResolution, *site.NameResolution)
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		if err = d.Set("name_resolution", ns); err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
@@ -736,7 +741,7 @@ func flattenSiteVPN(in openapi.SiteAllOfVpn) []interface{} {
 	return []interface{}{m}
 }
 
-func flattenNameResolution(currentVersion *version.Version, local map[string]interface{}, in openapi.SiteAllOfNameResolution) ([]interface{}, error) {
+func flattenNameResolution(local map[string]interface{}, in openapi.SiteAllOfNameResolution) ([]interface{}, error) {
 	m := make(map[string]interface{})
 	if v, ok := in.GetUseHostsFileOk(); ok {
 		m["use_hosts_file"] = *v
@@ -747,7 +752,7 @@ func flattenNameResolution(currentVersion *version.Version, local map[string]int
 	}
 	if v, ok := in.GetAwsResolversOk(); ok {
 		l := getNSLocalChanges(local, "aws_resolvers")
-		m["aws_resolvers"] = flattenSiteAWSResolver(currentVersion, v, l)
+		m["aws_resolvers"] = flattenSiteAWSResolver(v, l)
 	}
 	if v, ok := in.GetAzureResolversOk(); ok {
 		l := getNSLocalChanges(local, "azure_resolvers")
@@ -767,10 +772,8 @@ func flattenNameResolution(currentVersion *version.Version, local map[string]int
 		}
 		m["dns_forwarding"] = dnsfwd
 	}
-	if currentVersion.GreaterThanOrEqual(Appliance61Version) {
-		if v, ok := in.GetIllumioResolversOk(); ok {
-			m["illumio_resolvers"] = flattenSiteIllumioResolvers(currentVersion, v, getNSLocalChanges(local, "illumio_resolvers"))
-		}
+	if v, ok := in.GetIllumioResolversOk(); ok {
+		m["illumio_resolvers"] = flattenSiteIllumioResolvers(v, getNSLocalChanges(local, "illumio_resolvers"))
 	}
 	return []interface{}{m}, nil
 }
@@ -797,7 +800,7 @@ func flattenSiteGCPResolvers(in []openapi.SiteAllOfNameResolutionGcpResolvers) [
 	return out
 }
 
-func flattenSiteIllumioResolvers(version *version.Version, in []openapi.SiteAllOfNameResolutionIllumioResolvers, local map[string]interface{}) []map[string]interface{} {
+func flattenSiteIllumioResolvers(in []openapi.SiteAllOfNameResolutionIllumioResolvers, local map[string]interface{}) []map[string]interface{} {
 	var out = make([]map[string]interface{}, len(in), len(in))
 	for i, v := range in {
 		m := make(map[string]interface{})
@@ -806,8 +809,8 @@ func flattenSiteIllumioResolvers(version *version.Version, in []openapi.SiteAllO
 		m["hostname"] = v.GetHostname()
 		m["port"] = v.GetPort()
 		m["username"] = v.GetUsername()
-		if version.GreaterThanOrEqual(Appliance62Version) {
-			m["org_id"] = v.GetOrgId()
+		if orgID, ok := v.GetOrgIdOk(); ok {
+			m["org_id"] = *orgID
 		}
 		if val, ok := local["password"]; ok {
 			m["password"] = val
@@ -858,7 +861,7 @@ func flattenSiteAzureResolver(in []openapi.SiteAllOfNameResolutionAzureResolvers
 	return out
 }
 
-func flattenSiteAWSResolver(version *version.Version, in []openapi.SiteAllOfNameResolutionAwsResolvers, local map[string]interface{}) []map[string]interface{} {
+func flattenSiteAWSResolver(in []openapi.SiteAllOfNameResolutionAwsResolvers, local map[string]interface{}) []map[string]interface{} {
 	var out = make([]map[string]interface{}, len(in), len(in))
 	for i, v := range in {
 		m := make(map[string]interface{})
@@ -879,10 +882,14 @@ func flattenSiteAWSResolver(version *version.Version, in []openapi.SiteAllOfName
 		if vv, o := v.GetAssumedRolesOk(); o != false {
 			m["assumed_roles"] = flattenSiteAwsAssumedRoles(vv)
 		}
-		if version.GreaterThanOrEqual(Appliance65Version) {
-			m["ec2"] = v.GetEc2()
-			m["eks"] = v.GetEks()
-			m["rds"] = v.GetRds()
+		if ec2, ok := v.GetEc2Ok(); ok {
+			m["ec2"] = *ec2
+		}
+		if eks, ok := v.GetEksOk(); ok {
+			m["eks"] = *eks
+		}
+		if rds, ok := v.GetRdsOk(); ok {
+			m["rds"] = *rds
 		}
 		out[i] = m
 	}
@@ -949,22 +956,25 @@ func flattenAllowDestinations(input []openapi.AllowResourcesInner) ([]map[string
 	return r, nil
 }
 
-func resourceAppgateSiteUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateSiteUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Updating Site: %s", d.Get("name").(string))
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.SitesApi
 	currentVersion := meta.(*Client).ApplianceVersion
-	request := api.SitesIdGet(BaseAuthContext(token), d.Id())
+	request := api.SitesIdGet(authContext(ctx, token), d.Id())
 	orginalSite, res, err := request.Execute()
 	if err != nil {
 		d.SetId("")
 		if res != nil && res.StatusCode == http.StatusNotFound {
 			return nil
 		}
-		return fmt.Errorf("Failed to read Site, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read Site, %w", err))
+	}
+	if diags := checkNotModified(d, meta, "Site", orginalSite); diags.HasError() {
+		return diags
 	}
 
 	if d.HasChange("name") {
@@ -973,8 +983,8 @@ func resourceAppgateSiteUpdate(d *schema.ResourceData, meta interface{}) error {
 	if d.HasChange("notes") {
 		orginalSite.SetNotes(d.Get("notes").(string))
 	}
-	if d.HasChange("tags") {
-		orginalSite.SetTags(schemaExtractTags(d))
+	if d.HasChanges("tags", "tags_all") {
+		orginalSite.SetTags(schemaExtractTags(d, meta))
 	}
 	if d.HasChange("entitlement_based_routing") {
 		_, v := d.GetChange("entitlement_based_routing")
@@ -984,7 +994,7 @@ func resourceAppgateSiteUpdate(d *schema.ResourceData, meta interface{}) error {
 		_, n := d.GetChange("network_subnets")
 		networkSubnets, err := readArrayOfStringsFromConfig(n.(*schema.Set).List())
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		orginalSite.SetNetworkSubnets(networkSubnets)
 	}
@@ -993,7 +1003,7 @@ func resourceAppgateSiteUpdate(d *schema.ResourceData, meta interface{}) error {
 		_, n := d.GetChange("network_subnets")
 		networkSubnets, err := readArrayOfStringsFromConfig(n.(*schema.Set).List())
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		orginalSite.SetNetworkSubnets(networkSubnets)
 	}
@@ -1002,7 +1012,7 @@ func resourceAppgateSiteUpdate(d *schema.ResourceData, meta interface{}) error {
 		_, n := d.GetChange("ip_pool_mappings")
 		ipPoolMappings, err := readIPPoolMappingsFromConfig(n.(*schema.Set).List())
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		orginalSite.SetIpPoolMappings(ipPoolMappings)
 	}
@@ -1011,7 +1021,7 @@ func resourceAppgateSiteUpdate(d *schema.ResourceData, meta interface{}) error {
 		_, n := d.GetChange("default_gateway")
 		DefaultGateway, err := readSiteDefaultGatewayFromConfig(n.(*schema.Set).List())
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		orginalSite.SetDefaultGateway(DefaultGateway)
 	}
@@ -1020,7 +1030,7 @@ func resourceAppgateSiteUpdate(d *schema.ResourceData, meta interface{}) error {
 		_, v := d.GetChange("vpn")
 		vpn, err := readSiteVPNFromConfig(v.([]interface{}))
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		orginalSite.SetVpn(vpn)
 	}
@@ -1029,29 +1039,29 @@ func resourceAppgateSiteUpdate(d *schema.ResourceData, meta interface{}) error {
 		_, v := d.GetChange("name_resolution")
 		nameResolution, err := readSiteNameResolutionFromConfig(currentVersion, v.([]interface{}))
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 		orginalSite.SetNameResolution(nameResolution)
 	}
 
-	putRequest := api.SitesIdPut(BaseAuthContext(token), d.Id())
+	putRequest := api.SitesIdPut(authContext(ctx, token), d.Id())
 	_, _, err = putRequest.Site(*orginalSite).Execute()
 	if err != nil {
-		return fmt.Errorf("Could not update site %w", prettyPrintAPIError(err))
+		return apiErrorDiagnostics("Could not update site", err, resourceAppgateSite().Schema)
 	}
-	return resourceAppgateSiteRead(d, meta)
+	return resourceAppgateSiteRead(ctx, d, meta)
 }
 
-func resourceAppgateSiteDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateSiteDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Delete Site: %s", d.Get("name").(string))
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.SitesApi
 
-	if _, err := api.SitesIdDelete(BaseAuthContext(token), d.Id()).Execute(); err != nil {
-		return fmt.Errorf("Failed to delete Site, %w", err)
+	if _, err := api.SitesIdDelete(authContext(ctx, token), d.Id()).Execute(); err != nil {
+		return diag.FromErr(fmt.Errorf("Failed to delete Site, %w", err))
 	}
 	d.SetId("")
 	return nil
@@ -1223,7 +1233,7 @@ func readSiteNameResolutionFromConfig(currentVersion *version.Version, nameresol
 			result.SetGcpResolvers(gcpResolvers)
 		}
 		if v, ok := raw["dns_forwarding"]; ok {
-			dnsForwardingResolvers, err := readDNSForwardingResolversFromConfig(currentVersion, v.(*schema.Set).List())
+			dnsForwardingResolvers, err := readDNSForwardingResolversFromConfig(v.(*schema.Set).List())
 			if err != nil {
 				return result, err
 			}
@@ -1231,14 +1241,12 @@ func readSiteNameResolutionFromConfig(currentVersion *version.Version, nameresol
 				result.SetDnsForwarding(dnsForwardingResolvers)
 			}
 		}
-		if currentVersion.GreaterThanOrEqual(Appliance61Version) {
-			if v, ok := raw["illumio_resolvers"]; ok {
-				resolvers, err := readIllumioResolversFromConfig(currentVersion, v.(*schema.Set).List())
-				if err != nil {
-					return result, err
-				}
-				result.SetIllumioResolvers(resolvers)
+		if v, ok := raw["illumio_resolvers"]; ok {
+			resolvers, err := readIllumioResolversFromConfig(currentVersion, v.(*schema.Set).List())
+			if err != nil {
+				return result, err
 			}
+			result.SetIllumioResolvers(resolvers)
 		}
 	}
 	return result, nil
@@ -1328,7 +1336,8 @@ func readAWSResolversFromConfig(currentVersion *version.Version, awsConfigs []in
 		if v, ok := raw["resolve_with_master_credentials"]; ok {
 			row.SetResolveWithMasterCredentials(v.(bool))
 		}
-		if currentVersion.GreaterThanOrEqual(Appliance65Version) {
+		// ec2, eks and rds have a default value, so they are sent even if they are not in the configuration.
+		if attributeSupported("appgatesdp_site", "name_resolution.aws_resolvers.ec2", currentVersion) {
 			if v, ok := raw["ec2"]; ok {
 				row.SetEc2(v.(bool))
 			}
@@ -1465,7 +1474,7 @@ func readGCPResolversFromConfig(gcpConfigs []interface{}) ([]openapi.SiteAllOfNa
 	return result, nil
 }
 
-func readDNSForwardingResolversFromConfig(currentVersion *version.Version, dnsForwardingConfig []interface{}) (openapi.SiteAllOfNameResolutionDnsForwarding, error) {
+func readDNSForwardingResolversFromConfig(dnsForwardingConfig []interface{}) (openapi.SiteAllOfNameResolutionDnsForwarding, error) {
 	result := openapi.SiteAllOfNameResolutionDnsForwarding{}
 	for _, dnsForwarding := range dnsForwardingConfig {
 		raw := dnsForwarding.(map[string]interface{})
@@ -1489,10 +1498,8 @@ func readDNSForwardingResolversFromConfig(currentVersion *version.Version, dnsFo
 			}
 			result.SetAllowDestinations(destinations)
 		}
-		if currentVersion.LessThan(Appliance65Version) {
-			if v, ok := raw["default_ttl_seconds"].(int); ok && v > 0 {
-				result.SetDefaultTtlSeconds(int32(v))
-			}
+		if v, ok := raw["default_ttl_seconds"].(int); ok && v > 0 {
+			result.SetDefaultTtlSeconds(int32(v))
 		}
 	}
 	return result, nil
@@ -1521,10 +1528,8 @@ func readIllumioResolversFromConfig(currentVersion *version.Version, resolvers [
 		if v, ok := raw["password"]; ok {
 			row.SetPassword(v.(string))
 		}
-		if currentVersion.GreaterThanOrEqual(Appliance62Version) {
-			if v, ok := raw["org_id"]; ok {
-				row.SetOrgId(v.(string))
-			}
+		if v, ok := raw["org_id"]; ok && attributeSupported("appgatesdp_site", "name_resolution.illumio_resolvers.org_id", currentVersion) {
+			row.SetOrgId(v.(string))
 		}
 		result = append(result, row)
 	}
@@ -1,23 +1,26 @@
 package appgate
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"time"
 
 	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func resourceAppgateTrustedCertificate() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceAppgateTrustedCertificateCreate,
-		Read:   resourceAppgateTrustedCertificateRead,
-		Update: resourceAppgateTrustedCertificateUpdate,
-		Delete: resourceAppgateTrustedCertificateDelete,
+		CreateContext: resourceAppgateTrustedCertificateCreate,
+		ReadContext:   resourceAppgateTrustedCertificateRead,
+		UpdateContext: resourceAppgateTrustedCertificateUpdate,
+		DeleteContext: resourceAppgateTrustedCertificateDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Timeouts: &schema.ResourceTimeout{
@@ -27,9 +30,11 @@ func resourceAppgateTrustedCertificate() *schema.Resource {
 		},
 
 		SchemaVersion: 1,
+		CustomizeDiff: customdiff.All(tagsCustomizeDiff, etagCustomizeDiff),
 		Schema: func() map[string]*schema.Schema {
 			return mergeSchemaMaps(baseEntitySchema(), map[string]*schema.Schema{
 				"trusted_certificate_id": resourceUUID(),
+				"etag":                   etagSchema(),
 				"pem": {
 					Type:        schema.TypeString,
 					Description: "A certificate in PEM format.",
@@ -40,77 +45,79 @@ func resourceAppgateTrustedCertificate() *schema.Resource {
 	}
 }
 
-func resourceAppgateTrustedCertificateCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateTrustedCertificateCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Creating trusted certificate: %s", d.Get("name").(string))
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.TrustedCertificatesApi
 	args := openapi.NewTrustedCertificateWithDefaults()
-	if v, ok := d.GetOk("trusted_certificate_id"); ok {
-		args.SetId(v.(string))
-	}
+	args.SetId(resourceObjectID(d, "trusted_certificate_id"))
 	args.SetName(d.Get("name").(string))
 	args.SetNotes(d.Get("notes").(string))
-	args.SetTags(schemaExtractTags(d))
+	args.SetTags(schemaExtractTags(d, meta))
 
 	if v, ok := d.GetOk("pem"); ok {
 		args.SetPem(v.(string))
 	}
 
-	request := api.TrustedCertificatesPost(BaseAuthContext(token))
+	request := api.TrustedCertificatesPost(authContext(ctx, token))
 	request = request.TrustedCertificate(*args)
 
 	trustedCertificate, _, err := request.Execute()
 	if err != nil {
-		return fmt.Errorf("Could not create trusted certificate %w", prettyPrintAPIError(err))
+		return apiErrorDiagnostics("Could not create trusted certificate", err, resourceAppgateTrustedCertificate().Schema)
 	}
 
 	d.SetId(trustedCertificate.GetId())
 	d.Set("trusted_certificate_id", trustedCertificate.GetId())
 
-	return resourceAppgateTrustedCertificateRead(d, meta)
+	return resourceAppgateTrustedCertificateRead(ctx, d, meta)
 }
 
-func resourceAppgateTrustedCertificateRead(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateTrustedCertificateRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Reading trusted certificate id: %+v", d.Id())
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.TrustedCertificatesApi
-	request := api.TrustedCertificatesIdGet(BaseAuthContext(token), d.Id())
+	request := api.TrustedCertificatesIdGet(authContext(ctx, token), d.Id())
 	trustedCertificate, res, err := request.Execute()
 	if err != nil {
 		d.SetId("")
 		if res != nil && res.StatusCode == http.StatusNotFound {
 			return nil
 		}
-		return fmt.Errorf("Failed to read trusted certificate, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read trusted certificate, %w", err))
 	}
 	d.SetId(trustedCertificate.GetId())
+	d.Set("etag", objectETag(trustedCertificate))
 	d.Set("trusted_certificate_id", trustedCertificate.GetId())
 	d.Set("name", trustedCertificate.GetName())
 	d.Set("notes", trustedCertificate.GetNotes())
-	d.Set("tags", trustedCertificate.GetTags())
+	setTags(d, meta, trustedCertificate.GetTags())
 	d.Set("pem", trustedCertificate.GetPem())
 
 	return nil
 }
 
-func resourceAppgateTrustedCertificateUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateTrustedCertificateUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Updating trusted certificate: %s", d.Get("name").(string))
 	api := meta.(*Client).API.TrustedCertificatesApi
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
-	ctx := BaseAuthContext(token)
+	ctx = authContext(ctx, token)
 	request := api.TrustedCertificatesIdGet(ctx, d.Id())
 	originalTrustedCertificate, _, err := request.Execute()
 	if err != nil {
-		return fmt.Errorf("Failed to read trusted certificate while updating, %w", err)
+		return diag.FromErr(fmt.Errorf("Failed to read trusted certificate while updating, %w", err))
+	}
+	if diags := checkNotModified(d, meta, "Trusted certificate", originalTrustedCertificate); diags.HasError() {
+		return diags
 	}
 
 	if d.HasChange("name") {
@@ -121,8 +128,8 @@ func resourceAppgateTrustedCertificateUpdate(d *schema.ResourceData, meta interf
 		originalTrustedCertificate.SetNotes(d.Get("notes").(string))
 	}
 
-	if d.HasChange("tags") {
-		originalTrustedCertificate.SetTags(schemaExtractTags(d))
+	if d.HasChanges("tags", "tags_all") {
+		originalTrustedCertificate.SetTags(schemaExtractTags(d, meta))
 	}
 
 	if d.HasChange("pem") {
@@ -133,20 +140,20 @@ func resourceAppgateTrustedCertificateUpdate(d *schema.ResourceData, meta interf
 	req = req.TrustedCertificate(*originalTrustedCertificate)
 	_, _, err = req.Execute()
 	if err != nil {
-		return fmt.Errorf("Could not update trusted certificate %w", prettyPrintAPIError(err))
+		return apiErrorDiagnostics("Could not update trusted certificate", err, resourceAppgateTrustedCertificate().Schema)
 	}
-	return resourceAppgateTrustedCertificateRead(d, meta)
+	return resourceAppgateTrustedCertificateRead(ctx, d, meta)
 }
 
-func resourceAppgateTrustedCertificateDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceAppgateTrustedCertificateDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Printf("[DEBUG] Delete trusted certificate: %s", d.Get("name").(string))
 	api := meta.(*Client).API.TrustedCertificatesApi
 	token, err := meta.(*Client).GetToken()
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
-	if _, err := api.TrustedCertificatesIdDelete(BaseAuthContext(token), d.Id()).Execute(); err != nil {
-		return fmt.Errorf("Could not delete trusted certificate %w", prettyPrintAPIError(err))
+	if _, err := api.TrustedCertificatesIdDelete(authContext(ctx, token), d.Id()).Execute(); err != nil {
+		return diag.FromErr(fmt.Errorf("Could not delete trusted certificate %w", prettyPrintAPIError(err)))
 	}
 	d.SetId("")
 	return nil
@@ -8,6 +8,7 @@ import (
 
 	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -18,13 +19,15 @@ func resourceAppgateUserClaimScript() *schema.Resource {
 		UpdateContext: resourceAppgateUserClaimScriptUpdate,
 		DeleteContext: resourceAppgateUserClaimScriptDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: importStateByNameOrTag("user claim script", listUserScriptImportCandidates),
 		},
 
 		SchemaVersion: 1,
+		CustomizeDiff: customdiff.All(tagsCustomizeDiff, etagCustomizeDiff),
 		Schema: map[string]*schema.Schema{
 
 			"user_claim_script_id": resourceUUID(),
+			"etag":                 etagSchema(),
 
 			"name": {
 				Type:        schema.TypeString,
@@ -41,6 +44,8 @@ func resourceAppgateUserClaimScript() *schema.Resource {
 
 			"tags": tagsSchema(),
 
+			"tags_all": tagsAllSchema(),
+
 			"expression": {
 				Type:        schema.TypeString,
 				Description: "The User Claim Script content.",
@@ -58,20 +63,18 @@ func resourceAppgateUserClaimScriptCreate(ctx context.Context, d *schema.Resourc
 	}
 	api := meta.(*Client).API.UserClaimScriptsApi
 	args := openapi.NewUserScriptWithDefaults()
-	if v, ok := d.GetOk("user_claim_script_id"); ok {
-		args.SetId(v.(string))
-	}
+	args.SetId(resourceObjectID(d, "user_claim_script_id"))
 	args.SetName(d.Get("name").(string))
 	args.SetNotes(d.Get("notes").(string))
-	args.SetTags(schemaExtractTags(d))
+	args.SetTags(schemaExtractTags(d, meta))
 	if v, ok := d.GetOk("expression"); ok {
 		args.SetExpression(v.(string))
 	}
 
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	UserClaimScript, _, err := api.UserScriptsPost(ctx).UserScript(*args).Execute()
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("Could not create User Claim Script %w", prettyPrintAPIError(err)))
+		return apiErrorDiagnostics("Could not create User Claim Script", err, resourceAppgateUserClaimScript().Schema)
 	}
 
 	d.SetId(UserClaimScript.GetId())
@@ -87,7 +90,7 @@ func resourceAppgateUserClaimScriptRead(ctx context.Context, d *schema.ResourceD
 		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.UserClaimScriptsApi
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	request := api.UserScriptsIdGet(ctx, d.Id())
 	UserClaimScript, res, err := request.Execute()
 	if err != nil {
@@ -98,10 +101,11 @@ func resourceAppgateUserClaimScriptRead(ctx context.Context, d *schema.ResourceD
 		return diag.FromErr(fmt.Errorf("Failed to read User claim script, %w", err))
 	}
 	d.SetId(UserClaimScript.GetId())
+	d.Set("etag", objectETag(UserClaimScript))
 	d.Set("user_claim_script_id", UserClaimScript.GetId())
 	d.Set("name", UserClaimScript.GetName())
 	d.Set("notes", UserClaimScript.GetNotes())
-	d.Set("tags", UserClaimScript.GetTags())
+	setTags(d, meta, UserClaimScript.GetTags())
 	d.Set("expression", UserClaimScript.GetExpression())
 
 	return nil
@@ -115,12 +119,15 @@ func resourceAppgateUserClaimScriptUpdate(ctx context.Context, d *schema.Resourc
 		return diag.FromErr(fmt.Errorf("Failed to read user claim script %w", err))
 	}
 	api := meta.(*Client).API.UserClaimScriptsApi
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	request := api.UserScriptsIdGet(ctx, d.Id())
 	originalUserClaimScript, _, err := request.Execute()
 	if err != nil {
 		return diag.FromErr(fmt.Errorf("Failed to read User Claim Script while updating, %w", err))
 	}
+	if diags := checkNotModified(d, meta, "User claim script", originalUserClaimScript); diags.HasError() {
+		return diags
+	}
 
 	if d.HasChange("name") {
 		originalUserClaimScript.SetName(d.Get("name").(string))
@@ -130,8 +137,8 @@ func resourceAppgateUserClaimScriptUpdate(ctx context.Context, d *schema.Resourc
 		originalUserClaimScript.SetNotes(d.Get("notes").(string))
 	}
 
-	if d.HasChange("tags") {
-		originalUserClaimScript.SetTags(schemaExtractTags(d))
+	if d.HasChanges("tags", "tags_all") {
+		originalUserClaimScript.SetTags(schemaExtractTags(d, meta))
 	}
 
 	if d.HasChange("expression") {
@@ -142,7 +149,7 @@ func resourceAppgateUserClaimScriptUpdate(ctx context.Context, d *schema.Resourc
 	req = req.UserScript(*originalUserClaimScript)
 	_, _, err = req.Execute()
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("Could not update User Claim Script %w", prettyPrintAPIError(err)))
+		return apiErrorDiagnostics("Could not update User Claim Script", err, resourceAppgateUserClaimScript().Schema)
 	}
 	return resourceAppgateUserClaimScriptRead(ctx, d, meta)
 }
@@ -155,7 +162,7 @@ func resourceAppgateUserClaimScriptDelete(ctx context.Context, d *schema.Resourc
 		return diag.FromErr(err)
 	}
 	api := meta.(*Client).API.UserClaimScriptsApi
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	if _, err := api.UserScriptsIdDelete(ctx, d.Id()).Execute(); err != nil {
 		return diag.FromErr(fmt.Errorf("Could not delete User Claim Script %w", prettyPrintAPIError(err)))
 	}
@@ -0,0 +1,192 @@
+package appgate
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultMaxRetries is the number of times a request is retried if the controller is busy.
+	DefaultMaxRetries = 3
+	// DefaultRetryMaxWait is the longest we wait between two attempts.
+	DefaultRetryMaxWait = 30 * time.Second
+
+	retryBaseWait = 500 * time.Millisecond
+)
+
+// retryTransport retries requests when the controller is rate limiting us or is briefly unavailable,
+// for example while it syncs the configuration to the other controllers. Only requests that are safe
+// to send again are retried, idempotent methods and POST with a client generated id.
+type retryTransport struct {
+	maxRetries int
+	maxWait    time.Duration
+	next       http.RoundTripper
+}
+
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable:
+		return true
+	}
+	return false
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.maxRetries <= 0 || !retryableRequest(req) {
+		return t.next.RoundTrip(req)
+	}
+	for attempt := 0; ; attempt++ {
+		retry := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			retry = req.Clone(req.Context())
+			retry.Body = body
+		}
+		res, err := t.next.RoundTrip(retry)
+		if err == nil && attempt > 0 {
+			res = createdByEarlierAttempt(t.next, retry, res)
+		}
+		if err != nil || !retryableStatus(res.StatusCode) || attempt >= t.maxRetries {
+			return res, err
+		}
+		wait := t.wait(attempt, res.Header.Get("Retry-After"))
+		log.Printf("[DEBUG] %s %s got HTTP %d, retry %d/%d in %s", req.Method, req.URL.Path, res.StatusCode, attempt+1, t.maxRetries, wait)
+		io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// wait returns how long to wait before the next attempt, Retry-After from the
+// controller is used if present, otherwise exponential backoff with full jitter.
+func (t *retryTransport) wait(attempt int, retryAfter string) time.Duration {
+	maxWait := t.maxWait
+	if maxWait <= 0 {
+		maxWait = DefaultRetryMaxWait
+	}
+	if d, ok := parseRetryAfter(retryAfter); ok {
+		if d > maxWait {
+			return maxWait
+		}
+		return d
+	}
+	backoff := retryBaseWait << attempt
+	if backoff <= 0 || backoff > maxWait {
+		backoff = maxWait
+	}
+	return time.Duration(rand.Int63n(int64(backoff)) + 1)
+}
+
+// parseRetryAfter parses the Retry-After header, in seconds or as a HTTP date.
+func parseRetryAfter(v string) (time.Duration, bool) {
+	if len(v) == 0 {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(v); err == nil {
+		d := time.Until(date)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// retryableRequest returns true if the request can be sent again without side effects.
+func retryableRequest(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	case http.MethodPost:
+		// a POST with the id of the new object will fail with a conflict instead
+		// of creating a duplicate if the first attempt was applied by the controller.
+		_, ok := clientID(req)
+		return ok
+	}
+	return false
+}
+
+// clientID returns the id of the new object in the body of a POST.
+func clientID(req *http.Request) (string, bool) {
+	if req.GetBody == nil {
+		return "", false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return "", false
+	}
+	defer body.Close()
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return "", false
+	}
+	var object struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(bytes.NewReader(b)).Decode(&object); err != nil {
+		return "", false
+	}
+	if _, err := uuid.Parse(object.ID); err != nil {
+		return "", false
+	}
+	return object.ID, true
+}
+
+// createdByEarlierAttempt is used when a POST with a client generated id was sent more than once.
+// If an earlier attempt was applied by the controller, the POST fails with a conflict on the id
+// of the object it created. The object is read with the id that was sent and returned as the
+// response of the POST, so the create succeeds. Any other response is returned as is.
+func createdByEarlierAttempt(next http.RoundTripper, req *http.Request, res *http.Response) *http.Response {
+	if req.Method != http.MethodPost || res.StatusCode != http.StatusConflict {
+		return res
+	}
+	id, ok := clientID(req)
+	if !ok {
+		return res
+	}
+	get := req.Clone(req.Context())
+	get.Method = http.MethodGet
+	get.URL.Path = strings.TrimSuffix(req.URL.Path, "/") + "/" + id
+	get.URL.RawPath = ""
+	get.Body = http.NoBody
+	get.GetBody = nil
+	get.ContentLength = 0
+	get.Header.Del("Content-Type")
+	existing, err := next.RoundTrip(get)
+	if err != nil {
+		return res
+	}
+	if existing.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, existing.Body)
+		existing.Body.Close()
+		return res
+	}
+	log.Printf("[DEBUG] POST %s conflicts with %s from an earlier attempt, using the created object", req.URL.Path, id)
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+	return existing
+}
@@ -0,0 +1,191 @@
+package appgate
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		body         string
+		statuses     []int
+		maxRetries   int
+		wantStatus   int
+		wantAttempts int
+	}{
+		{
+			name:         "GET retried until OK",
+			method:       http.MethodGet,
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK},
+			maxRetries:   3,
+			wantStatus:   http.StatusOK,
+			wantAttempts: 3,
+		},
+		{
+			name:         "PUT gives up after max retries",
+			method:       http.MethodPut,
+			body:         `{"name": "updated"}`,
+			statuses:     []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway},
+			maxRetries:   2,
+			wantStatus:   http.StatusBadGateway,
+			wantAttempts: 3,
+		},
+		{
+			name:         "POST with client id",
+			method:       http.MethodPost,
+			body:         `{"id": "4c07bc67-57ea-42dd-b702-c2d6c45419fc", "name": "new"}`,
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusOK},
+			maxRetries:   3,
+			wantStatus:   http.StatusOK,
+			wantAttempts: 2,
+		},
+		{
+			name:         "POST without client id is not retried",
+			method:       http.MethodPost,
+			body:         `{"name": "new"}`,
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusOK},
+			maxRetries:   3,
+			wantStatus:   http.StatusServiceUnavailable,
+			wantAttempts: 1,
+		},
+		{
+			name:         "internal server error is not retried",
+			method:       http.MethodGet,
+			statuses:     []int{http.StatusInternalServerError, http.StatusOK},
+			maxRetries:   3,
+			wantStatus:   http.StatusInternalServerError,
+			wantAttempts: 1,
+		},
+		{
+			name:         "retries disabled",
+			method:       http.MethodGet,
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusOK},
+			wantStatus:   http.StatusServiceUnavailable,
+			wantAttempts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if string(body) != tt.body {
+					t.Errorf("attempt %d got body %q, want %q", attempts+1, body, tt.body)
+				}
+				status := tt.statuses[attempts]
+				attempts++
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+			client := &http.Client{Transport: &retryTransport{maxRetries: tt.maxRetries, maxWait: time.Second, next: http.DefaultTransport}}
+			req, err := http.NewRequest(tt.method, server.URL, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+			if res.StatusCode != tt.wantStatus || attempts != tt.wantAttempts {
+				t.Errorf("got HTTP %d after %d attempts, want HTTP %d after %d", res.StatusCode, attempts, tt.wantStatus, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestRetryTransportWait(t *testing.T) {
+	transport := &retryTransport{maxWait: 10 * time.Second}
+	if got := transport.wait(0, "3"); got != 3*time.Second {
+		t.Errorf("wait with Retry-After 3 got %s", got)
+	}
+	if got := transport.wait(0, "120"); got != 10*time.Second {
+		t.Errorf("wait with Retry-After 120 got %s, want retry_max_wait", got)
+	}
+	date := time.Now().Add(5 * time.Second).UTC().Format(http.TimeFormat)
+	if got := transport.wait(0, date); got <= 3*time.Second || got > 5*time.Second {
+		t.Errorf("wait with Retry-After %s got %s", date, got)
+	}
+	for attempt := 0; attempt < 10; attempt++ {
+		got := transport.wait(attempt, "")
+		if max := retryBaseWait << attempt; got <= 0 || got > max || got > transport.maxWait {
+			t.Errorf("wait for attempt %d got %s", attempt, got)
+		}
+	}
+}
+
+func TestRetryTransportConflictAfterRetry(t *testing.T) {
+	const id = "4c07bc67-57ea-42dd-b702-c2d6c45419fc"
+	tests := []struct {
+		name       string
+		existing   bool
+		wantStatus int
+		wantBody   string
+		wantPaths  []string
+	}{
+		{
+			name:       "first attempt was applied",
+			existing:   true,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"id": "` + id + `", "name": "new"}`,
+			wantPaths:  []string{"POST /admin/conditions", "POST /admin/conditions", "GET /admin/conditions/" + id},
+		},
+		{
+			name:       "conflict with another object",
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"message": "name already in use"}`,
+			wantPaths:  []string{"POST /admin/conditions", "POST /admin/conditions", "GET /admin/conditions/" + id},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paths []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				paths = append(paths, r.Method+" "+r.URL.Path)
+				if got := r.Header.Get("Authorization"); got != "Bearer token" {
+					t.Errorf("%s %s got Authorization %q", r.Method, r.URL.Path, got)
+				}
+				switch {
+				case r.Method == http.MethodGet && tt.existing:
+					io.WriteString(w, `{"id": "`+id+`", "name": "new"}`)
+				case r.Method == http.MethodGet:
+					w.WriteHeader(http.StatusNotFound)
+				case len(paths) == 1:
+					// the controller applied the create, but the load balancer timed out.
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(http.StatusServiceUnavailable)
+				default:
+					w.WriteHeader(http.StatusConflict)
+					io.WriteString(w, `{"message": "name already in use"}`)
+				}
+			}))
+			defer server.Close()
+			client := &http.Client{Transport: &retryTransport{maxRetries: 3, maxWait: time.Second, next: http.DefaultTransport}}
+			req, err := http.NewRequest(http.MethodPost, server.URL+"/admin/conditions", strings.NewReader(`{"id": "`+id+`", "name": "new"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer token")
+			res, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(res.Body)
+			res.Body.Close()
+			if res.StatusCode != tt.wantStatus || string(body) != tt.wantBody {
+				t.Errorf("got HTTP %d %s, want HTTP %d %s", res.StatusCode, body, tt.wantStatus, tt.wantBody)
+			}
+			if !reflect.DeepEqual(paths, tt.wantPaths) {
+				t.Errorf("got requests %q, want %q", paths, tt.wantPaths)
+			}
+		})
+	}
+}
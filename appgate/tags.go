@@ -0,0 +1,129 @@
+package appgate
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// tagsAllSchema is the computed set of tags on the object, the resource tags merged with the provider default_tags.
+func tagsAllSchema() *schema.Schema {
+	s := tagsSchema()
+	s.Description = "Array of tags on the object, including the provider default_tags."
+	s.Optional = false
+	s.Computed = true
+	s.StateFunc = nil
+	return s
+}
+
+// defaultTags returns the provider default_tags in lower case, the controller stores all tags in lower case.
+func defaultTags(meta interface{}) []string {
+	c, ok := meta.(*Client)
+	if !ok || c == nil || c.Config == nil {
+		return nil
+	}
+	tags := make([]string, 0, len(c.Config.DefaultTags))
+	for _, t := range c.Config.DefaultTags {
+		tags = append(tags, strings.ToLower(t))
+	}
+	return tags
+}
+
+// mergeTags returns the sorted union of the tags, without duplicates.
+func mergeTags(lists ...[]string) []string {
+	seen := make(map[string]bool)
+	tags := make([]string, 0)
+	for _, list := range lists {
+		for _, t := range list {
+			t = strings.ToLower(t)
+			if seen[t] {
+				continue
+			}
+			seen[t] = true
+			tags = append(tags, t)
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+func tagsFromSet(v interface{}) []string {
+	set, ok := v.(*schema.Set)
+	if !ok {
+		return nil
+	}
+	tags := make([]string, 0, set.Len())
+	for _, raw := range set.List() {
+		tags = append(tags, strings.ToLower(raw.(string)))
+	}
+	return tags
+}
+
+// setTags saves the tags from the controller in tags_all, and the same tags without the provider
+// default_tags in tags, so the default tags are not shown as a change to the configuration.
+// Default tags that are also in the resource tags are kept.
+// The prior state is empty after terraform import, so we can't tell which default tags are also in the
+// configuration, all tags are kept in tags then and the next plan reconciles them with the configuration.
+func setTags(d *schema.ResourceData, meta interface{}, remote []string) error {
+	tags := resourceTags(tagsFromSet(d.Get("tags")), tagsFromSet(d.Get("tags_all")), defaultTags(meta), remote)
+	if err := d.Set("tags", tags); err != nil {
+		return err
+	}
+	return d.Set("tags_all", mergeTags(remote))
+}
+
+// resourceTags returns the remote tags without the default tags that are not in configured.
+func resourceTags(configured, all, defaults, remote []string) []string {
+	imported := len(configured) == 0 && len(all) == 0
+	tags := make([]string, 0, len(remote))
+	for _, t := range remote {
+		t = strings.ToLower(t)
+		if !imported && inArray(t, append([]string{}, defaults...)) && !inArray(t, append([]string{}, configured...)) {
+			continue
+		}
+		tags = append(tags, t)
+	}
+	return tags
+}
+
+// frameworkTags is setTags for the terraform-plugin-framework resources, it returns tags and tags_all.
+// The state can't normalize the case of the tags like the SDK StateFunc, so the prior tags are kept
+// if they only differ in case from the controller.
+func frameworkTags(ctx context.Context, meta interface{}, prior, priorAll types.Set, remote []string) (types.Set, types.Set) {
+	configured := lowercaseTags(stringsFromSet(ctx, prior))
+	tags := resourceTags(configured, stringsFromSet(ctx, priorAll), defaultTags(meta), remote)
+	all := stringSet(mergeTags(remote))
+	if isKnown(prior) && strings.Join(mergeTags(configured), ",") == strings.Join(mergeTags(tags), ",") {
+		return prior, all
+	}
+	return setFromStrings(tags, prior), all
+}
+
+// frameworkPlanTagsAll is tagsCustomizeDiff for the terraform-plugin-framework resources,
+// it returns tags_all for the planned tags.
+func frameworkPlanTagsAll(ctx context.Context, meta interface{}, tags, stateTagsAll types.Set) types.Set {
+	if !fullyKnown(ctx, tags) {
+		return types.SetUnknown(types.StringType)
+	}
+	all := mergeTags(stringsFromSet(ctx, tags), defaultTags(meta))
+	if isKnown(stateTagsAll) && strings.Join(mergeTags(stringsFromSet(ctx, stateTagsAll)), ",") == strings.Join(all, ",") {
+		return stateTagsAll
+	}
+	return stringSet(all)
+}
+
+// tagsCustomizeDiff sets tags_all in the plan to the resource tags merged with the provider default_tags,
+// so a change to default_tags shows up as an update of tags_all on all resources.
+func tagsCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("tags") {
+		return d.SetNewComputed("tags_all")
+	}
+	all := mergeTags(tagsFromSet(d.Get("tags")), defaultTags(meta))
+	if current := mergeTags(tagsFromSet(d.Get("tags_all"))); d.Id() != "" && strings.Join(current, ",") == strings.Join(all, ",") {
+		return nil
+	}
+	return d.SetNew("tags_all", all)
+}
@@ -0,0 +1,128 @@
+package appgate
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestDefaultTagsSchema(t *testing.T) {
+	for name, r := range Provider().ResourcesMap {
+		if _, ok := r.Schema["tags"]; !ok || name == "appgatesdp_replication_target" {
+			continue
+		}
+		if _, ok := r.Schema["tags_all"]; !ok {
+			t.Errorf("%s has tags but no tags_all", name)
+		}
+		if r.CustomizeDiff == nil {
+			t.Errorf("%s has no CustomizeDiff", name)
+		}
+	}
+}
+
+func TestMergeTags(t *testing.T) {
+	got := mergeTags([]string{"Team-A", "api"}, []string{"terraform", "team-a"}, nil)
+	want := []string{"api", "team-a", "terraform"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestSetTags(t *testing.T) {
+	meta := &Client{Config: &Config{DefaultTags: []string{"Terraform", "owner-network"}}}
+	d := resourceAppgateCondition().Data(&terraform.InstanceState{
+		ID: "ee7f8e9c-6e4a-4c3b-8b5d-3b1c2a1e0f55",
+		Attributes: map[string]string{
+			"tags.#": "2",
+			"tags.0": "api",
+			"tags.1": "owner-network",
+		},
+	})
+	if err := setTags(d, meta, []string{"api", "owner-network", "terraform", "builtin"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := mergeTags(tagsFromSet(d.Get("tags"))), []string{"api", "builtin", "owner-network"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got tags %v, want %v", got, want)
+	}
+	if got, want := mergeTags(tagsFromSet(d.Get("tags_all"))), []string{"api", "builtin", "owner-network", "terraform"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got tags_all %v, want %v", got, want)
+	}
+}
+
+func TestSetTagsImport(t *testing.T) {
+	meta := &Client{Config: &Config{DefaultTags: []string{"terraform"}}}
+	d := resourceAppgateCondition().Data(&terraform.InstanceState{ID: "ee7f8e9c-6e4a-4c3b-8b5d-3b1c2a1e0f55"})
+	if err := setTags(d, meta, []string{"api", "terraform"}); err != nil {
+		t.Fatal(err)
+	}
+	// the configuration might have terraform in tags as well, so it is kept until the next plan.
+	if got, want := mergeTags(tagsFromSet(d.Get("tags"))), []string{"api", "terraform"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got tags %v, want %v", got, want)
+	}
+	if got, want := mergeTags(tagsFromSet(d.Get("tags_all"))), []string{"api", "terraform"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got tags_all %v, want %v", got, want)
+	}
+	// once the configured tags are applied, the default tags are removed from tags again.
+	d.Set("tags", []string{"api"})
+	if err := setTags(d, meta, []string{"api", "terraform"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := mergeTags(tagsFromSet(d.Get("tags"))), []string{"api"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got tags %v, want %v", got, want)
+	}
+}
+
+func TestTagsCustomizeDiff(t *testing.T) {
+	r := resourceAppgateCriteriaScript()
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name":       "script",
+		"expression": "return true;",
+		"tags":       []interface{}{"api"},
+	})
+	state := &terraform.InstanceState{
+		ID: "ee7f8e9c-6e4a-4c3b-8b5d-3b1c2a1e0f55",
+		Attributes: map[string]string{
+			"id":         "ee7f8e9c-6e4a-4c3b-8b5d-3b1c2a1e0f55",
+			"name":       "script",
+			"notes":      DefaultDescription,
+			"expression": "return true;",
+			"tags.#":     "1",
+			"tags.0":     "api",
+			"tags_all.#": "2",
+			"tags_all.0": "api",
+			"tags_all.1": "terraform",
+		},
+	}
+	tests := []struct {
+		name        string
+		defaultTags []string
+		wantDiff    bool
+	}{
+		{name: "unchanged", defaultTags: []string{"terraform"}},
+		{name: "default tag added", defaultTags: []string{"terraform", "owner-network"}, wantDiff: true},
+		{name: "default tags removed", wantDiff: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta := &Client{Config: &Config{DefaultTags: tt.defaultTags}}
+			diff, err := r.Diff(context.Background(), state.DeepCopy(), config, meta)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := diff != nil && len(diff.Attributes) > 0; got != tt.wantDiff {
+				t.Fatalf("got diff %t, want %t: %v", got, tt.wantDiff, diff)
+			}
+			if !tt.wantDiff {
+				return
+			}
+			if _, ok := diff.Attributes["tags_all.#"]; !ok {
+				t.Fatalf("expected a change to tags_all, got %v", diff.Attributes)
+			}
+			if _, ok := diff.Attributes["tags.#"]; ok {
+				t.Fatalf("expected no change to tags, got %v", diff.Attributes)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package appgate
+
+import (
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// throttleTransport limits the number of concurrent requests, and paces the writes, to the
+// controller. Each write triggers a config push to every appliance in the collective, so a
+// terraform apply with high parallelism can otherwise overload the controller.
+// It is shared by all resources using the same Client.
+type throttleTransport struct {
+	slots  chan struct{}
+	writes *writePacer
+	next   http.RoundTripper
+}
+
+func newThrottleTransport(maxConcurrent int, writesPerSecond float64, next http.RoundTripper) *throttleTransport {
+	t := &throttleTransport{next: next}
+	if maxConcurrent > 0 {
+		t.slots = make(chan struct{}, maxConcurrent)
+	}
+	if writesPerSecond > 0 {
+		t.writes = &writePacer{interval: time.Duration(float64(time.Second) / writesPerSecond)}
+	}
+	return t
+}
+
+// isWrite returns true for requests that change the configuration. POST /login
+// is the only request without Authorization header, and it is never paced.
+func isWrite(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return len(req.Header.Get("Authorization")) > 0
+}
+
+func (t *throttleTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	start := time.Now()
+	if t.writes != nil && isWrite(req) {
+		timer := time.NewTimer(t.writes.reserve(start))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if t.slots != nil {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case t.slots <- struct{}{}:
+		}
+		defer func() { <-t.slots }()
+	}
+	if wait := time.Since(start); wait > time.Millisecond {
+		log.Printf("[DEBUG] %s %s waited %s in the request queue", req.Method, req.URL.Path, wait.Round(time.Millisecond))
+	}
+	return t.next.RoundTrip(req)
+}
+
+// writePacer spaces out the writes evenly, so we never send more than one write per interval.
+type writePacer struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// reserve returns how long the caller must wait before it can send the write.
+func (p *writePacer) reserve(now time.Time) time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	at := p.next
+	if at.Before(now) {
+		at = now
+	}
+	p.next = at.Add(p.interval)
+	return at.Sub(now)
+}
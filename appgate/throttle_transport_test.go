@@ -0,0 +1,107 @@
+package appgate
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestThrottleTransportConcurrency(t *testing.T) {
+	var active, highest int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&active, 1)
+		defer atomic.AddInt32(&active, -1)
+		for {
+			h := atomic.LoadInt32(&highest)
+			if n <= h || atomic.CompareAndSwapInt32(&highest, h, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newThrottleTransport(2, 0, http.DefaultTransport)}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := client.Get(server.URL)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			res.Body.Close()
+		}()
+	}
+	wg.Wait()
+	if highest > 2 {
+		t.Errorf("got %d concurrent requests, want at most 2", highest)
+	}
+}
+
+func TestWritePacer(t *testing.T) {
+	p := &writePacer{interval: 500 * time.Millisecond}
+	now := time.Now()
+	for i, want := range []time.Duration{0, 500 * time.Millisecond, time.Second} {
+		if got := p.reserve(now); got != want {
+			t.Errorf("write %d got wait %s, want %s", i, got, want)
+		}
+	}
+	// the pacer does not save up unused capacity for a burst.
+	later := now.Add(10 * time.Second)
+	if got := p.reserve(later); got != 0 {
+		t.Errorf("got wait %s after idle period, want 0", got)
+	}
+	if got := p.reserve(later); got != 500*time.Millisecond {
+		t.Errorf("got wait %s, want 500ms", got)
+	}
+}
+
+func TestThrottleTransportWrites(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	transport := newThrottleTransport(0, 20, http.DefaultTransport)
+	client := &http.Client{Transport: transport}
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		req, _ := http.NewRequest(http.MethodPut, server.URL, nil)
+		req.Header.Set("Authorization", "Bearer token")
+		res, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("3 writes at 20 per second took %s, want at least 100ms", elapsed)
+	}
+	// reads and login are not paced.
+	start = time.Now()
+	for i := 0; i < 3; i++ {
+		res, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+	if elapsed := time.Since(start); elapsed >= 50*time.Millisecond {
+		t.Errorf("3 reads took %s, want no pacing", elapsed)
+	}
+
+	// a canceled request does not wait for its turn.
+	transport.writes.reserve(time.Now().Add(time.Hour))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodDelete, server.URL, nil)
+	req.Header.Set("Authorization", "Bearer token")
+	if _, err := client.Do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got err %v, want %s", err, context.DeadlineExceeded)
+	}
+}
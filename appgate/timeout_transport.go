@@ -0,0 +1,51 @@
+package appgate
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultTimeout is the dial timeout in seconds.
+	DefaultTimeout = 20
+	// DefaultRequestTimeout is the timeout for each attempt of a request, including reading the response body.
+	DefaultRequestTimeout = 40 * time.Second
+	// DefaultTLSHandshakeTimeout is the timeout for the TLS handshake with the controller.
+	DefaultTLSHandshakeTimeout = 20 * time.Second
+	// DefaultIdleConnTimeout is how long idle connections to the controller are kept open.
+	DefaultIdleConnTimeout = 90 * time.Second
+	// DefaultMaxIdleConnsPerHost is the number of idle connections kept open to each controller.
+	DefaultMaxIdleConnsPerHost = 10
+)
+
+// timeoutTransport applies request_timeout to each attempt of a request, unlike http.Client.Timeout
+// that would also include the time spent waiting for retries and in the request queue.
+type timeoutTransport struct {
+	timeout time.Duration
+	next    http.RoundTripper
+}
+
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	res, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return res, err
+	}
+	// the timeout covers reading the response body, so we can't cancel until it is closed.
+	res.Body = &cancelOnClose{ReadCloser: res.Body, cancel: cancel}
+	return res, nil
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,89 @@
+package appgate
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTimeoutTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		if r.URL.Path == "/slow-body" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: &timeoutTransport{timeout: 50 * time.Millisecond, next: http.DefaultTransport}}
+
+	res, err := client.Get(server.URL + "/fast")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil || string(body) != "ok" {
+		t.Errorf("got body %q err %v, want ok", body, err)
+	}
+
+	if _, err := client.Get(server.URL + "/slow"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got err %v, want %s", err, context.DeadlineExceeded)
+	}
+
+	res, err = client.Get(server.URL + "/slow-body")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = io.ReadAll(res.Body)
+	res.Body.Close()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got err %v reading the body, want %s", err, context.DeadlineExceeded)
+	}
+}
+
+func TestClientTransportSettings(t *testing.T) {
+	c := &Config{
+		URL:                 "https://controller.appgate.com",
+		TLSHandshakeTimeout: 5 * time.Second,
+		MaxIdleConnsPerHost: 4,
+	}
+	client, err := c.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := httpTransport(t, client.API.GetConfig().HTTPClient.Transport)
+	if !tr.ForceAttemptHTTP2 {
+		t.Error("expected HTTP/2 to be enabled")
+	}
+	if tr.TLSHandshakeTimeout != 5*time.Second || tr.MaxIdleConnsPerHost != 4 || tr.IdleConnTimeout != DefaultIdleConnTimeout {
+		t.Errorf("got TLSHandshakeTimeout %s MaxIdleConnsPerHost %d IdleConnTimeout %s", tr.TLSHandshakeTimeout, tr.MaxIdleConnsPerHost, tr.IdleConnTimeout)
+	}
+	if timeout := client.API.GetConfig().HTTPClient.Timeout; timeout != 0 {
+		t.Errorf("got http.Client timeout %s, the request_timeout must only apply to each attempt", timeout)
+	}
+	var timeout *timeoutTransport
+	for rt := client.API.GetConfig().HTTPClient.Transport; timeout == nil; {
+		switch v := rt.(type) {
+		case *timeoutTransport:
+			timeout = v
+		case *reauthTransport:
+			rt = v.next
+		case *retryTransport:
+			rt = v.next
+		default:
+			t.Fatalf("no timeoutTransport before %T", rt)
+		}
+	}
+	if timeout.timeout != DefaultRequestTimeout {
+		t.Errorf("got request timeout %s, want %s", timeout.timeout, DefaultRequestTimeout)
+	}
+}
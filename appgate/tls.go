@@ -0,0 +1,150 @@
+package appgate
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// tlsConfig creates the tls.Config used for the admin API connection, with the optional
+// CA from pem_filepath, client certificate for mutual TLS and pinned certificates.
+func (c *Config) tlsConfig() (*tls.Config, error) {
+	rootCAs, _ := x509.SystemCertPool()
+	if rootCAs == nil {
+		rootCAs = x509.NewCertPool()
+	}
+	if ok, err := FileExists(c.PemFilePath); err == nil && ok {
+		certs, err := os.ReadFile(c.PemFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("could not read pem file %w", err)
+		}
+		if ok := rootCAs.AppendCertsFromPEM(certs); !ok {
+			return nil, fmt.Errorf("unable to append cert %s", c.PemFilePath)
+		}
+	}
+	cfg := &tls.Config{
+		InsecureSkipVerify: c.Insecure,
+		RootCAs:            rootCAs,
+	}
+	if len(c.ClientCert) > 0 || len(c.ClientKey) > 0 {
+		if len(c.ClientCert) == 0 || len(c.ClientKey) == 0 {
+			return nil, errors.New("both client_cert and client_key are required for mutual TLS")
+		}
+		certPEM, err := readPEM(c.ClientCert)
+		if err != nil {
+			return nil, fmt.Errorf("could not read client_cert %w", err)
+		}
+		keyPEM, err := readPEM(c.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("could not read client_key %w", err)
+		}
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client_cert or client_key %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	if len(c.PinnedFingerprints) > 0 {
+		pins := make([][]byte, 0, len(c.PinnedFingerprints))
+		for _, fingerprint := range c.PinnedFingerprints {
+			pin, err := parseFingerprint(fingerprint)
+			if err != nil {
+				return nil, err
+			}
+			pins = append(pins, pin)
+		}
+		// the pins replace the certificate chain verification against the CA,
+		// so the controller certificate can be verified without the collective CA.
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyConnection = func(state tls.ConnectionState) error {
+			return verifyPinnedCertificate(state, pins)
+		}
+	}
+	return cfg, nil
+}
+
+// readPEM returns the PEM content as is, or reads it from the file path.
+func readPEM(v string) ([]byte, error) {
+	if strings.Contains(v, "-----BEGIN") {
+		return []byte(v), nil
+	}
+	return os.ReadFile(v)
+}
+
+// parseFingerprint parses a SHA-256 fingerprint in hex, with or without colons, or base64.
+func parseFingerprint(fingerprint string) ([]byte, error) {
+	v := strings.TrimPrefix(strings.TrimSpace(fingerprint), "sha256/")
+	if pin, err := hex.DecodeString(strings.ReplaceAll(v, ":", "")); err == nil && len(pin) == sha256.Size {
+		return pin, nil
+	}
+	if pin, err := base64.StdEncoding.DecodeString(v); err == nil && len(pin) == sha256.Size {
+		return pin, nil
+	}
+	return nil, fmt.Errorf("invalid pinned fingerprint %q, expected SHA-256 in hex or base64", fingerprint)
+}
+
+// spkiFingerprint is the SHA-256 of the certificate SubjectPublicKeyInfo.
+func spkiFingerprint(cert *x509.Certificate) []byte {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return sum[:]
+}
+
+func matchesPin(cert *x509.Certificate, pins [][]byte) bool {
+	fingerprint := spkiFingerprint(cert)
+	for _, pin := range pins {
+		if string(pin) == string(fingerprint) {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyPinnedCertificate accepts the connection if the controller certificate is pinned, or if the
+// controller certificate is signed by a pinned CA certificate in the chain presented by the controller.
+func verifyPinnedCertificate(state tls.ConnectionState, pins [][]byte) error {
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("controller did not present a certificate")
+	}
+	leaf := state.PeerCertificates[0]
+	if matchesPin(leaf, pins) {
+		return nil
+	}
+	// a pinned CA is public, so the chain must be verified against it, otherwise
+	// anyone could present it next to their own certificate.
+	for _, ca := range state.PeerCertificates[1:] {
+		if !matchesPin(ca, pins) {
+			continue
+		}
+		roots := x509.NewCertPool()
+		roots.AddCert(ca)
+		intermediates := x509.NewCertPool()
+		for _, cert := range state.PeerCertificates[1:] {
+			intermediates.AddCert(cert)
+		}
+		_, err := leaf.Verify(x509.VerifyOptions{
+			DNSName:       state.ServerName,
+			Roots:         roots,
+			Intermediates: intermediates,
+		})
+		if err == nil {
+			return nil
+		}
+	}
+	return &pinnedCertificateError{Subject: leaf.Subject.String(), Fingerprint: hex.EncodeToString(spkiFingerprint(leaf))}
+}
+
+// pinnedCertificateError is returned if the controller certificate does not match pinned_fingerprints.
+type pinnedCertificateError struct {
+	Subject     string
+	Fingerprint string
+}
+
+func (e *pinnedCertificateError) Error() string {
+	return fmt.Sprintf("controller certificate %q does not match any pinned fingerprint, got SHA-256 %s", e.Subject, e.Fingerprint)
+}
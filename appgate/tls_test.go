@@ -0,0 +1,205 @@
+package appgate
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCertificate struct {
+	cert   *x509.Certificate
+	key    *ecdsa.PrivateKey
+	der    []byte
+	keyPEM []byte
+}
+
+func (c testCertificate) certPEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der})
+}
+
+// newTestCertificate creates a certificate for 127.0.0.1 signed by parent, or a self-signed CA if parent is nil.
+func newTestCertificate(t *testing.T, name string, parent *testCertificate) testCertificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testCertificate{
+		cert:   cert,
+		key:    key,
+		der:    der,
+		keyPEM: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func TestParseFingerprint(t *testing.T) {
+	sum := make([]byte, 32)
+	for i := range sum {
+		sum[i] = byte(i)
+	}
+	colons := ""
+	for i, b := range sum {
+		if i > 0 {
+			colons += ":"
+		}
+		colons += hex.EncodeToString([]byte{b})
+	}
+	for _, fingerprint := range []string{
+		hex.EncodeToString(sum),
+		colons,
+		base64.StdEncoding.EncodeToString(sum),
+		"sha256/" + base64.StdEncoding.EncodeToString(sum),
+	} {
+		got, err := parseFingerprint(fingerprint)
+		if err != nil {
+			t.Errorf("parseFingerprint(%q) got error %s", fingerprint, err)
+			continue
+		}
+		if string(got) != string(sum) {
+			t.Errorf("parseFingerprint(%q) got %x", fingerprint, got)
+		}
+	}
+	for _, fingerprint := range []string{"", "abcd", hex.EncodeToString(sum[:20])} {
+		if _, err := parseFingerprint(fingerprint); err == nil {
+			t.Errorf("parseFingerprint(%q) expected error", fingerprint)
+		}
+	}
+}
+
+func TestTLSConfig(t *testing.T) {
+	ca := newTestCertificate(t, "collective CA", nil)
+	controller := newTestCertificate(t, "controller", &ca)
+	client := newTestCertificate(t, "terraform", &ca)
+	otherCA := newTestCertificate(t, "other CA", nil)
+	impostor := newTestCertificate(t, "impostor", &otherCA)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+	newServer := func(serverCert testCertificate, chain ...[]byte) *httptest.Server {
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		server.TLS = &tls.Config{
+			Certificates: []tls.Certificate{{
+				Certificate: append([][]byte{serverCert.der}, chain...),
+				PrivateKey:  serverCert.key,
+			}},
+			ClientAuth: tls.RequireAndVerifyClientCert,
+			ClientCAs:  clientCAs,
+		}
+		server.StartTLS()
+		return server
+	}
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.pem")
+	if err := os.WriteFile(certFile, client.certPEM(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	pin := func(c testCertificate) string { return hex.EncodeToString(spkiFingerprint(c.cert)) }
+
+	tests := []struct {
+		name    string
+		server  *httptest.Server
+		config  Config
+		wantErr bool
+	}{
+		{
+			name:   "pinned controller certificate",
+			server: newServer(controller),
+			config: Config{ClientCert: certFile, ClientKey: string(client.keyPEM), PinnedFingerprints: []string{pin(controller)}},
+		},
+		{
+			name:   "pinned CA",
+			server: newServer(controller, ca.der),
+			config: Config{ClientCert: string(client.certPEM()), ClientKey: string(client.keyPEM), PinnedFingerprints: []string{pin(ca)}},
+		},
+		{
+			name:    "pinned CA presented next to a certificate it did not sign",
+			server:  newServer(impostor, ca.der),
+			config:  Config{ClientCert: certFile, ClientKey: string(client.keyPEM), PinnedFingerprints: []string{pin(ca)}},
+			wantErr: true,
+		},
+		{
+			name:    "fingerprint mismatch",
+			server:  newServer(controller),
+			config:  Config{ClientCert: certFile, ClientKey: string(client.keyPEM), PinnedFingerprints: []string{pin(impostor)}},
+			wantErr: true,
+		},
+		{
+			name:    "fingerprint mismatch with insecure",
+			server:  newServer(controller),
+			config:  Config{Insecure: true, ClientCert: certFile, ClientKey: string(client.keyPEM), PinnedFingerprints: []string{pin(impostor)}},
+			wantErr: true,
+		},
+		{
+			name:    "missing client certificate",
+			server:  newServer(controller),
+			config:  Config{PinnedFingerprints: []string{pin(controller)}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer tt.server.Close()
+			tlsConfig, err := tt.config.tlsConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			hc := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+			res, err := hc.Get(tt.server.URL)
+			if res != nil {
+				res.Body.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := (&Config{ClientCert: certFile}).tlsConfig(); err == nil {
+		t.Error("expected error for client_cert without client_key")
+	}
+	if _, err := (&Config{PinnedFingerprints: []string{"invalid"}}).tlsConfig(); err == nil {
+		t.Error("expected error for invalid pinned fingerprint")
+	}
+}
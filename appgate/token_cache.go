@@ -0,0 +1,151 @@
+package appgate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// cachedToken is a token saved in the token_cache_path file, so that it can be
+// reused between terraform invocations without a new login.
+type cachedToken struct {
+	Token         string    `json:"token"`
+	Expires       time.Time `json:"expires"`
+	ClientVersion int       `json:"client_version,omitempty"`
+}
+
+// tokenCacheKey identifies the login in the token cache file. The key is hashed
+// so that the username is not stored in plain text.
+func tokenCacheKey(c *Config) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{strings.Join(c.controllerURLs(), ","), c.Provider, c.Username, c.DeviceID}, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+func readTokenCache(path string) (map[string]cachedToken, error) {
+	cache := make(map[string]cachedToken)
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return cache, nil
+	}
+	if err := json.Unmarshal(b, &cache); err != nil {
+		return nil, fmt.Errorf("invalid token cache file %s: %w", path, err)
+	}
+	return cache, nil
+}
+
+// loadCachedToken returns the cached token for the config, if it is still valid.
+func loadCachedToken(c *Config) (*cachedToken, error) {
+	cache, err := readTokenCache(c.TokenCachePath)
+	if err != nil {
+		return nil, err
+	}
+	t, ok := cache[tokenCacheKey(c)]
+	if !ok || len(t.Token) == 0 || time.Now().Add(tokenExpiryLeeway).After(t.Expires) {
+		return nil, nil
+	}
+	return &t, nil
+}
+
+// lockTokenCache takes an exclusive lock on the token cache, so that concurrent terraform
+// invocations don't overwrite each other's tokens, and returns the function that releases it.
+// The lock is held on a separate file since the cache file itself is replaced on each write.
+func lockTokenCache(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("could not lock token cache %s: %w", path, err)
+	}
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+// saveCachedToken saves the token in the token cache file, and removes expired tokens.
+func saveCachedToken(c *Config, t cachedToken) error {
+	unlock, err := lockTokenCache(c.TokenCachePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	cache, err := readTokenCache(c.TokenCachePath)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for k, v := range cache {
+		if now.After(v.Expires) {
+			delete(cache, k)
+		}
+	}
+	cache[tokenCacheKey(c)] = t
+	return writeTokenCache(c.TokenCachePath, cache)
+}
+
+// deleteCachedToken removes the token from the token cache file, for example
+// if it has been rejected by the controller.
+func deleteCachedToken(c *Config) error {
+	unlock, err := lockTokenCache(c.TokenCachePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	cache, err := readTokenCache(c.TokenCachePath)
+	if err != nil {
+		return err
+	}
+	key := tokenCacheKey(c)
+	if _, ok := cache[key]; !ok {
+		return nil
+	}
+	delete(cache, key)
+	return writeTokenCache(c.TokenCachePath, cache)
+}
+
+// writeTokenCache writes the token cache file with 0600 permissions since it contains credentials.
+func writeTokenCache(path string, cache map[string]cachedToken) error {
+	b, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	// write to a temporary file first, so that concurrent terraform invocations never read a partial file.
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,159 @@
+package appgate
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTokenCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "appgate", "tokens.json")
+	cfg := &Config{
+		URL:            "https://controller.appgate.com/admin",
+		Provider:       "local",
+		Username:       "admin",
+		DeviceID:       "a1e3ef54-5bb6-4a34-a2a5-b18d6d3a8b6f",
+		TokenCachePath: path,
+	}
+	other := *cfg
+	other.Username = "operator"
+
+	cached, err := loadCachedToken(cfg)
+	if err != nil {
+		t.Fatalf("expected no error from missing cache file, got %s", err)
+	}
+	if cached != nil {
+		t.Fatalf("expected no cached token, got %+v", cached)
+	}
+
+	if err := saveCachedToken(cfg, cachedToken{Token: "valid", Expires: time.Now().Add(time.Hour), ClientVersion: 22}); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveCachedToken(&other, cachedToken{Token: "expired", Expires: time.Now().Add(-time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("expected token cache file mode 0600, got %s", info.Mode().Perm())
+		}
+	}
+
+	cached, err = loadCachedToken(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached == nil || cached.Token != "valid" || cached.ClientVersion != 22 {
+		t.Fatalf("expected cached token, got %+v", cached)
+	}
+	cached, err = loadCachedToken(&other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached != nil {
+		t.Fatalf("expected expired token to be ignored, got %+v", cached)
+	}
+
+	if err := deleteCachedToken(cfg); err != nil {
+		t.Fatal(err)
+	}
+	cached, err = loadCachedToken(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached != nil {
+		t.Fatalf("expected deleted token, got %+v", cached)
+	}
+}
+
+func TestClientTokenCache(t *testing.T) {
+	_, _, mux, _, port, teardown := setup()
+	defer teardown()
+	logins := 0
+	mux.HandleFunc("/admin/login", func(w http.ResponseWriter, r *http.Request) {
+		logins++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"token": "token-%d", "expires": %q}`, logins, time.Now().Add(time.Hour).Format(time.RFC3339))
+	})
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	newClient := func() *Client {
+		c := &Config{
+			URL:            fmt.Sprintf("http://localhost:%d", port),
+			Username:       "admin",
+			Password:       "admin",
+			Version:        22,
+			LoginTimeout:   1,
+			TokenCachePath: path,
+		}
+		client, err := c.Client()
+		if err != nil {
+			t.Fatalf("got err %s expected nil", err)
+		}
+		return client
+	}
+
+	// each terraform invocation starts with a new client.
+	for i := 0; i < 3; i++ {
+		token, err := newClient().GetToken()
+		if err != nil {
+			t.Fatalf("got err %s expected nil", err)
+		}
+		if token != "token-1" {
+			t.Errorf("expected cached token-1, got %s", token)
+		}
+	}
+	if logins != 1 {
+		t.Fatalf("expected 1 login, got %d", logins)
+	}
+
+	// a rejected token is removed from the cache
+	client := newClient()
+	token, err := client.refreshToken("token-1")
+	if err != nil {
+		t.Fatalf("got err %s expected nil", err)
+	}
+	if token != "token-2" {
+		t.Errorf("expected token-2, got %s", token)
+	}
+	token, err = newClient().GetToken()
+	if err != nil {
+		t.Fatalf("got err %s expected nil", err)
+	}
+	if token != "token-2" {
+		t.Errorf("expected cached token-2, got %s", token)
+	}
+	if logins != 2 {
+		t.Fatalf("expected 2 logins, got %d", logins)
+	}
+}
+
+func TestTokenCacheConcurrentWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cfg := &Config{URL: "https://controller.appgate.com/admin", Username: fmt.Sprintf("admin%d", i), TokenCachePath: path}
+			if err := saveCachedToken(cfg, cachedToken{Token: fmt.Sprintf("token%d", i), Expires: time.Now().Add(time.Hour)}); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	cache, err := readTokenCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cache) != 20 {
+		t.Fatalf("got %d tokens in the cache, want 20", len(cache))
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"sort"
 	"strings"
 
+	v22 "github.com/appgate/sdp-api-client-go/api/v22/openapi"
 	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
 	"github.com/appgate/terraform-provider-appgatesdp/appgate/hashcode"
 	"github.com/cenkalti/backoff/v4"
@@ -24,10 +25,20 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
+// BaseAuthContext is used outside of the terraform CRUD functions, such as in the acceptance tests.
+// Use authContext in the resources, so the requests are canceled together with terraform.
 func BaseAuthContext(token string) context.Context {
 	return context.WithValue(context.Background(), openapi.ContextAccessToken, token)
 }
 
+// authContext carries the terraform context into the openapi requests, so that cancellation,
+// the resource timeouts and the terraform logger applies to the requests.
+func authContext(ctx context.Context, token string) context.Context {
+	ctx = context.WithValue(ctx, tflogCtx, true)
+	ctx = context.WithValue(ctx, v22.ContextAccessToken, token)
+	return context.WithValue(ctx, openapi.ContextAccessToken, token)
+}
+
 func AppendErrorf(diags diag.Diagnostics, format string, a ...any) diag.Diagnostics {
 	return append(diags, diag.Errorf(format, a...)...)
 }
@@ -68,7 +79,8 @@ func baseEntitySchema() map[string]*schema.Schema {
 
 func baseTagsSchema() map[string]*schema.Schema {
 	return map[string]*schema.Schema{
-		"tags": tagsSchema(),
+		"tags":     tagsSchema(),
+		"tags_all": tagsAllSchema(),
 	}
 }
 
@@ -111,7 +123,16 @@ func ipPoolRange() *schema.Schema {
 	}
 }
 
-func readBaseEntityFromConfig(d *schema.ResourceData) (*openapi.BaseEntity, error) {
+// resourceObjectID returns the id from the key attribute, or a new UUID. The id of a new object is
+// generated on the client, so the retry transport can send the create again without creating a duplicate.
+func resourceObjectID(d *schema.ResourceData, key string) string {
+	if v, ok := d.GetOk(key); ok {
+		return v.(string)
+	}
+	return uuid.New().String()
+}
+
+func readBaseEntityFromConfig(d *schema.ResourceData, meta interface{}) (*openapi.BaseEntity, error) {
 	base := &openapi.BaseEntity{}
 	base.SetId(uuid.New().String())
 	if v, ok := d.GetOk("name"); ok {
@@ -120,8 +141,8 @@ func readBaseEntityFromConfig(d *schema.ResourceData) (*openapi.BaseEntity, erro
 	if v, ok := d.GetOk("notes"); ok {
 		base.SetNotes(v.(string))
 	}
-	if _, ok := d.GetOk("tags"); ok {
-		base.SetTags(schemaExtractTags(d))
+	if tags := schemaExtractTags(d, meta); len(tags) > 0 {
+		base.SetTags(tags)
 	}
 	return base, nil
 }
@@ -150,13 +171,9 @@ func prettyPrintAPIError(err error) error {
 	return fmt.Errorf("%w", err)
 }
 
-func schemaExtractTags(d *schema.ResourceData) []string {
-	rawtags := d.Get("tags").(*schema.Set).List()
-	tags := make([]string, 0)
-	for _, raw := range rawtags {
-		tags = append(tags, strings.ToLower(raw.(string)))
-	}
-	return tags
+// schemaExtractTags returns the resource tags merged with the provider default_tags.
+func schemaExtractTags(d *schema.ResourceData, meta interface{}) []string {
+	return mergeTags(tagsFromSet(d.Get("tags")), defaultTags(meta))
 }
 
 func listToMapList(in []interface{}) ([]map[string]interface{}, error) {
@@ -410,7 +427,7 @@ func waitForApplianceState(ctx context.Context, meta interface{}, applianceID, s
 		if err != nil {
 			return ApplianceStatsRetryableError{err: err}
 		}
-		ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+		ctx = authContext(ctx, token)
 		stats, _, err := appliancesAPI.AppliancesStatusGet(ctx).Execute()
 		if err != nil {
 			log.Printf("[ERROR] Failed to get appliance status for %s: %s", applianceID, err)
@@ -0,0 +1,107 @@
+package appgate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	v22 "github.com/appgate/sdp-api-client-go/api/v22/openapi"
+	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
+	"github.com/google/uuid"
+)
+
+func TestAuthContext(t *testing.T) {
+	_, _, mux, _, port, teardown := setup()
+	defer teardown()
+	mux.HandleFunc("/admin/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, loginResponse)
+	})
+	mux.HandleFunc("/admin/global-settings", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("got Authorization %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{}`)
+	})
+	c := &Config{
+		URL:          fmt.Sprintf("http://localhost:%d", port),
+		Username:     "admin",
+		Password:     "admin",
+		Version:      22,
+		LoginTimeout: 1,
+	}
+	client, err := c.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := authContext(context.Background(), "token")
+	if ctx.Value(openapi.ContextAccessToken) != "token" || ctx.Value(v22.ContextAccessToken) != "token" {
+		t.Fatal("expected the token for both api versions")
+	}
+	if _, _, err := client.API.GlobalSettingsApi.GlobalSettingsGet(ctx).Execute(); err != nil {
+		t.Fatalf("got err %s expected nil", err)
+	}
+	if _, _, err := client.OldAPI.GlobalSettingsApi.GlobalSettingsGet(ctx).Execute(); err != nil {
+		t.Fatalf("got err %s expected nil", err)
+	}
+
+	// the request is canceled together with the terraform context.
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = client.API.GlobalSettingsApi.GlobalSettingsGet(authContext(canceled, "token")).Execute()
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got err %v expected %s", err, context.Canceled)
+	}
+}
+
+func TestCreateSendsClientID(t *testing.T) {
+	_, _, mux, _, port, teardown := setup()
+	defer teardown()
+	mux.HandleFunc("/admin/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, loginResponse)
+	})
+	var id string
+	mux.HandleFunc("/admin/conditions", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			ID string `json:"id"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		// the retry transport only sends a create again if it has the id of the new object.
+		if _, err := uuid.Parse(body.ID); err != nil {
+			t.Errorf("expected the id of the new condition in the request, got %q", body.ID)
+		}
+		id = body.ID
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id": %q, "name": "always", "expression": "return true;"}`, id)
+	})
+	mux.HandleFunc("/admin/conditions/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id": %q, "name": "always", "expression": "return true;"}`, id)
+	})
+	c := &Config{
+		URL:          fmt.Sprintf("http://localhost:%d", port),
+		Username:     "admin",
+		Password:     "admin",
+		Version:      22,
+		LoginTimeout: 1,
+	}
+	client, err := c.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := resourceAppgateCondition().Data(nil)
+	d.Set("name", "always")
+	d.Set("expression", "return true;")
+	if diags := resourceAppgateConditionCreate(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("got %v", diags)
+	}
+	if d.Id() != id || len(id) == 0 {
+		t.Fatalf("got id %q, want %q", d.Id(), id)
+	}
+}
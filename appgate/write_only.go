@@ -0,0 +1,52 @@
+package appgate
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// writeOnlySchemas returns the write-only counterpart <name>_wo of the sensitive attribute name, which is sent to the
+// controller but never saved in the state or plan. Write-only values can't be compared with the state, so
+// <name>_wo_version must be changed to send a new value on update.
+//
+// Write-only attributes are not allowed in set blocks or computed blocks, so they can only be added to top level attributes
+// and nested list blocks.
+func writeOnlySchemas(name, description string) map[string]*schema.Schema {
+	wo := name + "_wo"
+	version := wo + "_version"
+	return map[string]*schema.Schema{
+		wo: {
+			Type:          schema.TypeString,
+			Description:   fmt.Sprintf("%s Write-only alternative to %s, it is never saved in the state. Requires Terraform 1.11 or later.", description, name),
+			Optional:      true,
+			Sensitive:     true,
+			WriteOnly:     true,
+			ConflictsWith: []string{name},
+			RequiredWith:  []string{version},
+		},
+		version: {
+			Type:         schema.TypeInt,
+			Description:  fmt.Sprintf("Version of %s, change it to send a new value of %s to the controller.", wo, wo),
+			Optional:     true,
+			RequiredWith: []string{wo},
+			ValidateFunc: validation.IntAtLeast(1),
+		},
+	}
+}
+
+// writeOnlyString returns the value of the top level write-only attribute name from the configuration,
+// ok is false if it is not set.
+func writeOnlyString(d *schema.ResourceData, name string) (string, bool, diag.Diagnostics) {
+	v, diags := d.GetRawConfigAt(cty.GetAttrPath(name))
+	if diags.HasError() {
+		return "", false, diags
+	}
+	if v.IsNull() || !v.IsKnown() || !v.Type().Equals(cty.String) {
+		return "", false, nil
+	}
+	return v.AsString(), true, nil
+}
@@ -0,0 +1,181 @@
+package appgate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/appgate/sdp-api-client-go/api/v24/openapi"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestLocalUserUpdatePasswordWriteOnly(t *testing.T) {
+	const id = "5f0c7bd8-8a0e-4e1d-9b35-6f7f0a1c2d3e"
+	tests := []struct {
+		name         string
+		version      int
+		wantPassword string
+	}{
+		{name: "unchanged version", version: 1},
+		{name: "new version", version: 2, wantPassword: "s3cret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, mux, _, port, teardown := setup()
+			defer teardown()
+			mux.HandleFunc("/admin/login", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, loginResponse)
+			})
+			var password string
+			mux.HandleFunc("/admin/local-users/"+id, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPut {
+					var body openapi.LocalUsersGetRequest
+					if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+						t.Fatal(err)
+					}
+					password = body.GetPassword()
+				}
+				w.Header().Set("Content-Type", "application/json")
+				user := openapi.NewLocalUsersGetRequest("jane", "Jane", "Doe", "")
+				user.SetId(id)
+				body, _ := user.MarshalJSON()
+				w.Write(body)
+			})
+			c := &Config{
+				URL:          fmt.Sprintf("http://localhost:%d", port),
+				Username:     "admin",
+				Password:     "admin",
+				Version:      22,
+				LoginTimeout: 1,
+			}
+			client, err := c.Client()
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := resourceAppgateLocalUser()
+			state := &terraform.InstanceState{
+				ID: id,
+				Attributes: map[string]string{
+					"id":                  id,
+					"name":                "jane",
+					"notes":               DefaultDescription,
+					"first_name":          "Jane",
+					"last_name":           "Doe",
+					"password_wo_version": "1",
+				},
+			}
+			config := terraform.NewResourceConfigRaw(map[string]interface{}{
+				"name":                "jane",
+				"first_name":          "Jane",
+				"last_name":           "Doe",
+				"email":               "jane@example.com",
+				"password_wo":         "s3cret",
+				"password_wo_version": tt.version,
+			})
+			diff, err := r.Diff(context.Background(), state, config, client)
+			if err != nil {
+				t.Fatal(err)
+			}
+			diff.RawConfig = cty.ObjectVal(map[string]cty.Value{
+				"password_wo": cty.StringVal("s3cret"),
+			})
+			newState, diags := r.Apply(context.Background(), state, diff, client)
+			if diags.HasError() {
+				t.Fatalf("got %v, expected no error", diags)
+			}
+			if password != tt.wantPassword {
+				t.Fatalf("got password %q, want %q", password, tt.wantPassword)
+			}
+			if v := newState.Attributes["password"]; v != "" {
+				t.Fatalf("got password %q in the state", v)
+			}
+		})
+	}
+}
+
+func TestRadiusProviderUpdateSharedSecretWriteOnly(t *testing.T) {
+	const id = "0f3e5a7c-2b4d-4e6f-8a1c-3d5e7f9a1b2c"
+	tests := []struct {
+		name       string
+		version    int
+		wantSecret string
+	}{
+		{name: "unchanged version", version: 1},
+		{name: "new version", version: 2, wantSecret: "s3cret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, mux, _, port, teardown := setup()
+			defer teardown()
+			mux.HandleFunc("/admin/login", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, loginResponse)
+			})
+			var secret string
+			mux.HandleFunc("/admin/identity-providers/"+id, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPut {
+					var body openapi.RadiusProvider
+					if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+						t.Fatal(err)
+					}
+					secret = body.GetSharedSecret()
+				}
+				w.Header().Set("Content-Type", "application/json")
+				// the controller never returns the shared secret.
+				provider := openapi.NewRadiusProvider("radius", identityProviderRadius, []string{"radius.example.com"}, "")
+				provider.SetId(id)
+				body, _ := provider.MarshalJSON()
+				w.Write(body)
+			})
+			c := &Config{
+				URL:          fmt.Sprintf("http://localhost:%d", port),
+				Username:     "admin",
+				Password:     "admin",
+				Version:      22,
+				LoginTimeout: 1,
+			}
+			client, err := c.Client()
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := resourceAppgateRadiusProvider()
+			state := &terraform.InstanceState{
+				ID: id,
+				Attributes: map[string]string{
+					"id":                       id,
+					"name":                     "radius",
+					"notes":                    DefaultDescription,
+					"hostnames.#":              "1",
+					"hostnames.0":              "radius.example.com",
+					"port":                     "1812",
+					"shared_secret_wo_version": "1",
+				},
+			}
+			config := terraform.NewResourceConfigRaw(map[string]interface{}{
+				"name":                     "radius",
+				"notes":                    "updated by terraform",
+				"hostnames":                []interface{}{"radius.example.com"},
+				"port":                     1812,
+				"shared_secret_wo":         "s3cret",
+				"shared_secret_wo_version": tt.version,
+			})
+			diff, err := r.Diff(context.Background(), state, config, client)
+			if err != nil {
+				t.Fatal(err)
+			}
+			diff.RawConfig = cty.ObjectVal(map[string]cty.Value{
+				"shared_secret_wo": cty.StringVal("s3cret"),
+			})
+			if _, diags := r.Apply(context.Background(), state, diff, client); diags.HasError() {
+				t.Fatalf("got %v, expected no error", diags)
+			}
+			if secret != tt.wantSecret {
+				t.Fatalf("got shared secret %q, want %q", secret, tt.wantSecret)
+			}
+		})
+	}
+}
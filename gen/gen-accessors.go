@@ -20,6 +20,10 @@ import (
 
 type Resource struct {
 	Name, Service, Model, ServiceGetMethod, ServiceIDGetMethod, Plural, AccessorName string
+	// ClientField is the field of the service in openapi.APIClient.
+	ClientField string
+	// Import generates a lister for importStateByNameOrTag.
+	Import bool
 }
 
 type templateStub struct {
@@ -37,7 +41,8 @@ var (
 	stub       = templateStub{}
 	generators = []Resource{
 		{
-			Name: "Entitlement",
+			Name:   "Entitlement",
+			Import: true,
 		},
 		{
 			Name:    "AdministrativeRole",
@@ -47,23 +52,29 @@ var (
 			Name: "ApplianceCustomization",
 		},
 		{
-			Name: "Appliance",
+			Name:   "Appliance",
+			Import: true,
 		},
 		{
-			Name: "Condition",
+			Name:   "Condition",
+			Import: true,
 		},
 		{
-			Name: "CriteriaScript",
+			Name:   "CriteriaScript",
+			Import: true,
 		},
 		{
 			Name:    "DeviceScript",
 			Service: "DeviceClaimScriptsApi",
+			Import:  true,
 		},
 		{
-			Name: "EntitlementScript",
+			Name:   "EntitlementScript",
+			Import: true,
 		},
 		{
-			Name: "IpPool",
+			Name:   "IpPool",
+			Import: true,
 		},
 		{
 			Name: "LocalUser",
@@ -76,7 +87,8 @@ var (
 			Name: "RingfenceRule",
 		},
 		{
-			Name: "Site",
+			Name:   "Site",
+			Import: true,
 		},
 		{
 			Name: "TrustedCertificate",
@@ -85,6 +97,7 @@ var (
 			Name:         "UserScript",
 			Service:      "UserClaimScriptsApi",
 			AccessorName: "user_claim_script",
+			Import:       true,
 		},
 		{
 			Name: "MfaProvider",
@@ -131,6 +144,7 @@ func main() {
 			if strings.ToLower(guess) == strings.ToLower(reflectType.Field(i).Name) {
 				child := reflectType.Field(i)
 				generator.Service = fmt.Sprintf("%s", child.Type.Elem())
+				generator.ClientField = child.Name
 
 				// TODO get reflect | go analysis to get the exact method name and return value
 				generator.ServiceGetMethod = fmt.Sprintf("%sGet", plural)
@@ -217,7 +231,7 @@ import (
 
 func find{{ .Name | Title }}ByUUID(ctx context.Context, api *{{ .Service }}, id, token string) (*{{ .Model }}, diag.Diagnostics) {
 	log.Printf("[DEBUG] Data source {{ .Name }} get by UUID %s", id)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.{{ .ServiceIDGetMethod }}(ctx, id).Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -228,7 +242,7 @@ func find{{ .Name | Title }}ByUUID(ctx context.Context, api *{{ .Service }}, id,
 func find{{ .Name | Title }}ByName(ctx context.Context, api *{{ .Service }}, name, token string) (*{{ .Model }}, diag.Diagnostics) {
 	var diags diag.Diagnostics
 	log.Printf("[DEBUG] Data source {{ .Name }} get by name %s", name)
-	ctx = context.WithValue(ctx, openapi.ContextAccessToken, token)
+	ctx = authContext(ctx, token)
 	resource, _, err := api.{{ .ServiceGetMethod }}(ctx).Query(name).OrderBy("name").Range_("0-10").Execute()
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -259,6 +273,16 @@ func Resolve{{ .Name | Title}}FromResourceData(ctx context.Context, d *schema.Re
 	}
 	return find{{ .Name | Title}}ByName(ctx, api, resourceName.(string), token)
 }
+{{- if .Import }}
+
+func list{{ .Name | Title }}ImportCandidates(ctx context.Context, c *Client, token, query string) ([]importCandidate, error) {
+	list, _, err := c.API.{{ .ClientField }}.{{ .ServiceGetMethod }}(authContext(ctx, token)).Query(query).OrderBy("name").Execute()
+	if err != nil {
+		return nil, err
+	}
+	return importCandidates(list.GetData()), nil
+}
+{{- end }}
 
 {{- end }}
 `
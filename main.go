@@ -20,13 +20,13 @@ func main() {
 	if debugMode {
 		err := plugin.Debug(context.Background(), "registry.terraform.io/appgate/appgatesdp",
 			&plugin.ServeOpts{
-				ProviderFunc: appgate.Provider,
+				GRPCProviderFunc: appgate.ProtocolServer,
 			})
 		if err != nil {
 			log.Println(err.Error())
 		}
 	} else {
 		plugin.Serve(&plugin.ServeOpts{
-			ProviderFunc: appgate.Provider})
+			GRPCProviderFunc: appgate.ProtocolServer})
 	}
 }